@@ -1,54 +1,133 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 
-	"gilab.com/estate-agency-api/internal/adapters/db/mysql"
+	"gilab.com/estate-agency-api/internal/app"
 	"gilab.com/estate-agency-api/internal/config"
-	"gilab.com/estate-agency-api/internal/domain/service"
-	"gilab.com/estate-agency-api/internal/domain/usecase"
-	"gilab.com/estate-agency-api/internal/middlewares"
-	v1 "gilab.com/estate-agency-api/internal/transport/http/v1"
-	client "gilab.com/estate-agency-api/pkg/client/mysql"
 	"gilab.com/estate-agency-api/pkg/logging"
 	"github.com/gin-gonic/gin"
 )
 
+const usage = `Usage:
+  app [--config path]                            run the server
+  app [--config path] config print [--redacted]  print the effective config
+
+The config path defaults to $CONFIG_PATH, then to ` + config.DefaultPath + `.
+`
+
 func main() {
-	cfg := config.GetConfig()
+	configFlag := flag.String("config", "", "path to the config file")
+	flag.Usage = func() { fmt.Fprint(flag.CommandLine.Output(), usage) }
+	flag.Parse()
+
+	args := flag.Args()
+	switch {
+	case len(args) == 0:
+		serve(config.Path(*configFlag))
+	case len(args) >= 2 && args[0] == "config" && args[1] == "print":
+		printConfig(*configFlag, args[2:])
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
 
-	logger := logging.GetLogger("info")
+func printConfig(configFlag string, args []string) {
+	fs := flag.NewFlagSet("config print", flag.ExitOnError)
+	redacted := fs.Bool("redacted", false, "replace passwords and other secrets")
+	fs.StringVar(&configFlag, "config", configFlag, "path to the config file")
+	fs.Parse(args)
 
-	logger.Info("Connect to db")
-	db, err := client.NewClient(&cfg.StorageConfig)
+	cfg, err := config.Load(config.Path(configFlag))
 	if err != nil {
-		panic(err)
+		log.Fatal(err)
+	}
+	if *redacted {
+		cfg = cfg.Redacted()
 	}
 
-	logger.Info("Create router")
-	router := gin.New()
-
-	router.Use(gin.Recovery(), gin.Logger())
-	/* routes.UserRoutes(router) */
-	router.Use(middlewares.BasicAuth(cfg.HTTPServerConfig.User, cfg.HTTPServerConfig.Password))
+	out, err := config.Marshal(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	os.Stdout.Write(out)
+}
 
-	logger.Info("Set routes")
-	realtorHandler := v1.NewRealtorHandler(usecase.NewRealtorUsecase(service.NewRealtorService(mysql.NewRealtorStorage(db))))
-	realtorHandler.Register(router)
-	apartmentHandler := v1.NewApartmentHandler(usecase.NewApartmentUsecase(service.NewApartmentService(mysql.NewApartmentStorage(db)), service.NewRealtorService(mysql.NewRealtorStorage(db))))
-	apartmentHandler.Register(router)
+func serve(path string) {
+	cfg, err := config.Load(path)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	serv := &http.Server{
-		Addr:         cfg.Address,
-		Handler:      router,
-		ReadTimeout:  cfg.HTTPServerConfig.Timeout,
-		WriteTimeout: cfg.HTTPServerConfig.Timeout,
-		IdleTimeout:  cfg.HTTPServerConfig.IdleTimeout,
+	level, err := logging.ParseLevel(cfg.LoggerConfig.Level)
+	if err != nil {
+		log.Fatal(err)
 	}
+	var levelVar slog.LevelVar
+	levelVar.Set(level)
 
-	logger.Info("Start server on " + cfg.HTTPServerConfig.Address)
-	err = serv.ListenAndServe()
+	logger, err := logging.New(os.Stdout, &levelVar, cfg.LoggerConfig.Format)
 	if err != nil {
-		panic(err)
+		log.Fatal(err)
+	}
+	slog.SetDefault(logger)
+	logger.Info("Loaded config", slog.String("path", path), slog.String("env", cfg.Env))
+
+	// Requests are logged by the logging middleware; gin's debug output would be a second format.
+	gin.SetMode(gin.ReleaseMode)
+
+	a := app.New(cfg, logger)
+
+	go func() {
+		logger.Info("Start server", slog.String("address", cfg.HTTPServerConfig.Address))
+		if err := a.Run(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("server stopped", "err", err.Error())
+			os.Exit(1)
+		}
+	}()
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+
+	// running tracks the settings in effect, so a change that needs a restart is reported on every reload.
+	running := *cfg
+	for {
+		select {
+		case <-reload:
+			next, err := config.Load(path)
+			if err != nil {
+				logger.Error("config not reloaded", "err", err.Error())
+				continue
+			}
+			for _, key := range config.Diff(&running, next) {
+				if !config.Reloadable(key) {
+					logger.Warn("config change needs a restart", slog.String("key", key))
+				}
+			}
+			level, _ := logging.ParseLevel(next.LoggerConfig.Level)
+			levelVar.Set(level)
+			a.Reload(next)
+			running.ApplyReloadable(next)
+			logger.Info("Reloaded config", slog.String("log_level", level.String()))
+
+		case <-stop:
+			logger.Info("Shutdown server")
+			if err := a.Shutdown(); err != nil {
+				logger.Error("shutdown failed", "err", err.Error())
+				os.Exit(1)
+			}
+			return
+		}
 	}
 }
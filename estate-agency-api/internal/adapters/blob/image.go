@@ -0,0 +1,73 @@
+package adapterBlob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+type imageAdapter struct {
+	root string
+}
+
+func NewImageAdapter(root string) *imageAdapter {
+	return &imageAdapter{root: root}
+}
+
+// Delete removes every stored image of the entity regardless of its extension; missing files are not an error.
+func (ia *imageAdapter) Delete(kind string, id int) error {
+	const op = "adapterBlob.Delete"
+
+	matches, err := filepath.Glob(filepath.Join(ia.root, kind, strconv.Itoa(id)+".*"))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	for _, path := range matches {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return nil
+}
+
+// Exists reports whether an image of the entity is stored, whatever its extension.
+func (ia *imageAdapter) Exists(kind string, id int) (bool, error) {
+	const op = "adapterBlob.Exists"
+
+	matches, err := filepath.Glob(filepath.Join(ia.root, kind, strconv.Itoa(id)+".*"))
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return len(matches) != 0, nil
+}
+
+// Ping checks that the image directory exists and is writable.
+func (ia *imageAdapter) Ping(ctx context.Context) error {
+	const op = "adapterBlob.Ping"
+
+	info, err := os.Stat(ia.root)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s: %s is not a directory", op, ia.root)
+	}
+
+	file, err := os.CreateTemp(ia.root, ".health-*")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	file.Close()
+
+	if err = os.Remove(file.Name()); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return ctx.Err()
+}
@@ -6,9 +6,16 @@ import (
 	"time"
 
 	"gilab.com/estate-agency-api/internal/entity"
+	"gilab.com/estate-agency-api/pkg/reqctx"
 	"github.com/go-redis/cache/v9"
 )
 
+// apartmentKey includes the agency of the request, so that an apartment cached for one agency is not served to another.
+func apartmentKey(ctx context.Context, id int) string {
+	agencyID, _ := reqctx.Tenant(ctx)
+	return strconv.Itoa(agencyID) + ":" + strconv.Itoa(id)
+}
+
 type apartmentAdapter struct {
 	cacheClient *cache.Cache
 }
@@ -17,10 +24,10 @@ func NewApartmentAdapter(cacheClient *cache.Cache) *apartmentAdapter {
 	return &apartmentAdapter{cacheClient: cacheClient}
 }
 
-func (a *apartmentAdapter) SetApartment(apartment *entity.Apartment, id int) error {
+func (a *apartmentAdapter) SetApartment(ctx context.Context, apartment *entity.Apartment, id int) error {
 	err := a.cacheClient.Set(&cache.Item{
-		Ctx:   context.Background(),
-		Key:   strconv.Itoa(id),
+		Ctx:   ctx,
+		Key:   apartmentKey(ctx, id),
 		Value: apartment,
 		TTL:   time.Hour,
 	})
@@ -28,10 +35,10 @@ func (a *apartmentAdapter) SetApartment(apartment *entity.Apartment, id int) err
 	return err
 }
 
-func (a *apartmentAdapter) GetApartment(id int) (*entity.Apartment, error) {
+func (a *apartmentAdapter) GetApartment(ctx context.Context, id int) (*entity.Apartment, error) {
 	var apartment entity.Apartment
 
-	err := a.cacheClient.Get(context.Background(), strconv.Itoa(id), &apartment)
+	err := a.cacheClient.Get(ctx, apartmentKey(ctx, id), &apartment)
 
 	return &apartment, err
 }
@@ -0,0 +1,60 @@
+package adapterRedis
+
+import (
+	"context"
+	"strconv"
+
+	"gilab.com/estate-agency-api/internal/entity"
+	"github.com/redis/go-redis/v9"
+)
+
+const rateLimitKeyPrefix = "ratelimit:"
+
+// takeToken refills the bucket by the time elapsed on the Redis clock, so instances with skewed
+// clocks share one bucket, and takes a token when there is one. The key expires once the bucket is full.
+var takeToken = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'updated')
+local tokens = tonumber(state[1]) or burst
+local updated = tonumber(state[2]) or now
+tokens = math.min(burst, tokens + (now - updated) * rate / 1000)
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'updated', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil((burst - tokens) * 1000 / rate) + 1000)
+
+return {allowed, tostring(tokens)}
+`)
+
+// rateLimitAdapter keeps token buckets in Redis, so all instances share the limits.
+type rateLimitAdapter struct {
+	client redis.Scripter
+}
+
+func NewRateLimitAdapter(client redis.Scripter) *rateLimitAdapter {
+	return &rateLimitAdapter{client: client}
+}
+
+func (ra *rateLimitAdapter) Allow(ctx context.Context, key string, limit entity.RateLimit) (entity.RateLimitResult, error) {
+	reply, err := takeToken.Run(ctx, ra.client, []string{rateLimitKeyPrefix + key}, limit.Rate, limit.Burst).Slice()
+	if err != nil {
+		return entity.RateLimitResult{}, err
+	}
+
+	allowed, _ := reply[0].(int64)
+	tokens, err := strconv.ParseFloat(reply[1].(string), 64)
+	if err != nil {
+		return entity.RateLimitResult{}, err
+	}
+
+	return limit.Result(tokens, allowed == 1), nil
+}
@@ -0,0 +1,141 @@
+package adapterSql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"gilab.com/estate-agency-api/internal/entity"
+	"github.com/go-sql-driver/mysql"
+)
+
+const (
+	contextTimeGetAllAgency = 2
+	contextTimeGetOneAgency = 1
+	contextTimeCreateAgency = 1
+)
+
+const agencyColumns = `id, name, slug, created_at`
+
+func scanAgency(row scanner, agency *entity.Agency) error {
+	return row.Scan(&agency.ID, &agency.Name, &agency.Slug, &agency.CreatedAt)
+}
+
+// agencyAdapter stores the tenants themselves, so unlike the other adapters it is not scoped to one.
+type agencyAdapter struct {
+	db *sql.DB
+}
+
+func NewAgencyAdapter(db *sql.DB) *agencyAdapter {
+	return &agencyAdapter{db: db}
+}
+
+func (aa *agencyAdapter) GetAll(ctx context.Context, page int, pageSize int) (agencies []*entity.Agency, err error) {
+
+	q := `SELECT ` + agencyColumns + ` FROM agencies ORDER BY id LIMIT ?,?`
+
+	context, close := context.WithTimeout(ctx, contextTimeGetAllAgency*time.Second)
+	defer close()
+
+	return aa.query(context, q, page*pageSize, pageSize)
+}
+
+// All returns every agency, it is used by workers that process each agency in turn.
+func (aa *agencyAdapter) All(ctx context.Context) (agencies []*entity.Agency, err error) {
+
+	q := `SELECT ` + agencyColumns + ` FROM agencies ORDER BY id`
+
+	context, close := context.WithTimeout(ctx, contextTimeGetAllAgency*time.Second)
+	defer close()
+
+	return aa.query(context, q)
+}
+
+func (aa *agencyAdapter) GetByID(ctx context.Context, id int) (agency *entity.Agency, err error) {
+
+	q := `SELECT ` + agencyColumns + ` FROM agencies WHERE id=?`
+
+	context, close := context.WithTimeout(ctx, contextTimeGetOneAgency*time.Second)
+	defer close()
+
+	agency = &entity.Agency{}
+	if err = scanAgency(aa.db.QueryRowContext(context, q, id), agency); err != nil {
+		return nil, err
+	}
+
+	return agency, nil
+}
+
+func (aa *agencyAdapter) GetBySlug(ctx context.Context, slug string) (agency *entity.Agency, err error) {
+
+	q := `SELECT ` + agencyColumns + ` FROM agencies WHERE slug=?`
+
+	context, close := context.WithTimeout(ctx, contextTimeGetOneAgency*time.Second)
+	defer close()
+
+	agency = &entity.Agency{}
+	if err = scanAgency(aa.db.QueryRowContext(context, q, slug), agency); err != nil {
+		return nil, err
+	}
+
+	return agency, nil
+}
+
+// Create stores the agency. The audit entry is written to the audit log of the agency the request is scoped to.
+func (aa *agencyAdapter) Create(ctx context.Context, agency *entity.Agency, audit *entity.AuditEntry) (id int64, err error) {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return
+	}
+	q := `INSERT INTO agencies (name, slug, created_at) VALUES (?, ?, ?)`
+
+	context, close := context.WithTimeout(ctx, contextTimeCreateAgency*time.Second)
+	defer close()
+
+	tx, err := aa.db.BeginTx(context, nil)
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(context, q, agency.Name, agency.Slug, agency.CreatedAt)
+	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrDuplicateEntry {
+			return 0, entity.ErrAgencySlugExists
+		}
+		return 0, err
+	}
+
+	id, err = result.LastInsertId()
+	if err != nil {
+		return
+	}
+	agency.ID = int(id)
+
+	if err = insertAudit(context, tx, agencyID, agency.ID, audit); err != nil {
+		return
+	}
+
+	return id, tx.Commit()
+}
+
+func (aa *agencyAdapter) query(ctx context.Context, q string, args ...any) (agencies []*entity.Agency, err error) {
+	rows, err := aa.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		agency := &entity.Agency{}
+		if err = scanAgency(rows, agency); err != nil {
+			return
+		}
+		agencies = append(agencies, agency)
+	}
+
+	return agencies, rows.Err()
+}
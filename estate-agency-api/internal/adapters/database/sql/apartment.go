@@ -3,19 +3,39 @@ package adapterSql
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"time"
 
 	"gilab.com/estate-agency-api/internal/entity"
 )
 
 const (
-	contextTimeGetAllApartment = 2
-	contextTimeGetOneApartment = 1
-	contextTimeCreateApartment = 1
-	contextTimeUpdateApartment = 1
-	contextTimeDeleteApartment = 1
+	contextTimeGetAllApartment  = 2
+	contextTimeGetOneApartment  = 1
+	contextTimeCreateApartment  = 1
+	contextTimeUpdateApartment  = 1
+	contextTimeDeleteApartment  = 1
+	contextTimeEachApartment    = 1
+	contextTimeRestoreApartment = 1
+	contextTimePurgeApartment   = 10
+	contextTimeCountApartment   = 5
 )
 
+const apartmentColumns = `id, title, price, city, rooms, address, square, id_realtor, status, version, update_time, create_time, deleted_at, listing_type, monthly_rent, deposit, min_term_months, utilities_included, pets_allowed, lat, lng, address_street, address_house, address_building, address_city`
+
+// apartmentDistance is the distance in kilometers from the point given by the lat and lng arguments.
+const apartmentDistance = `ST_Distance_Sphere(location, ST_SRID(POINT(?, ?), 4326))/1000`
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// scanApartment scans apartmentColumns followed by the extra columns of the query.
+func scanApartment(row scanner, apartment *entity.Apartment, extra ...any) error {
+	return row.Scan(append([]any{&apartment.ID, &apartment.Title, &apartment.Price, &apartment.City, &apartment.Rooms, &apartment.Address, &apartment.Square, &apartment.IDRealtor, &apartment.Status, &apartment.Version, &apartment.UpdateTime, &apartment.CreateTime, &apartment.DeletedAt, &apartment.ListingType, &apartment.MonthlyRent, &apartment.Deposit, &apartment.MinTermMonths, &apartment.UtilitiesIncluded, &apartment.PetsAllowed, &apartment.Lat, &apartment.Lng, &apartment.NormalizedAddress.Street, &apartment.NormalizedAddress.House, &apartment.NormalizedAddress.Building, &apartment.NormalizedAddress.City}, extra...)...)
+}
+
 type apartmentAdapter struct {
 	db *sql.DB
 }
@@ -24,30 +44,43 @@ func NewApartmentAdapter(db *sql.DB) *apartmentAdapter {
 	return &apartmentAdapter{db: db}
 }
 
-func (as *apartmentAdapter) GetAll(page int, pageSize int) (apartments []*entity.Apartment, err error) {
+func (as *apartmentAdapter) GetAll(ctx context.Context, filter entity.ApartmentFilter, page int, pageSize int) (apartments []*entity.Apartment, err error) {
 
-	q := `SELECT * FROM apartments LIMIT ?,?`
-
-	context, close := context.WithTimeout(context.Background(), contextTimeGetAllApartment*time.Second)
-	defer close()
-
-	if err = as.db.PingContext(context); err != nil {
+	agencyID, err := tenant(ctx)
+	if err != nil {
 		return
 	}
+	where, args := apartmentFilterClause(agencyID, filter)
+	columns, order := apartmentColumns, ""
+	if filter.Center != nil {
+		columns += `, ` + apartmentDistance + ` AS distance_km`
+		args = append([]any{filter.Center.Lat, filter.Center.Lng}, args...)
+		if filter.Sort == entity.SortDistance {
+			order = ` ORDER BY distance_km, id`
+		}
+	}
+	q := `SELECT ` + columns + ` FROM apartments` + where + order + ` LIMIT ?,?`
+
+	context, close := context.WithTimeout(ctx, contextTimeGetAllApartment*time.Second)
+	defer close()
 
 	stmt, err := as.db.PrepareContext(context, q)
 	if err != nil {
 		return
 	}
 
-	rows, err := stmt.QueryContext(context, page*pageSize, pageSize)
+	rows, err := stmt.QueryContext(context, append(args, page*pageSize, pageSize)...)
 	if err != nil {
 		return
 	}
 
 	for rows.Next() {
 		apartment := &entity.Apartment{}
-		err = rows.Scan(&apartment.ID, &apartment.Title, &apartment.Price, &apartment.City, &apartment.Rooms, &apartment.Address, &apartment.Square, &apartment.IDRealtor, &apartment.UpdateTime, &apartment.CreateTime)
+		if filter.Center != nil {
+			err = scanApartment(rows, apartment, &apartment.DistanceKM)
+		} else {
+			err = scanApartment(rows, apartment)
+		}
 		if err != nil {
 			return
 		}
@@ -59,16 +92,55 @@ func (as *apartmentAdapter) GetAll(page int, pageSize int) (apartments []*entity
 	return apartments, nil
 }
 
-func (as *apartmentAdapter) GetByID(id int) (apartment *entity.Apartment, err error) {
+func (as *apartmentAdapter) Each(ctx context.Context, filter entity.ApartmentFilter, fn func(apartment *entity.Apartment) error) error {
 
-	q := `SELECT * FROM apartments WHERE id=?`
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return err
+	}
+	where, args := apartmentFilterClause(agencyID, filter)
+	q := `SELECT ` + apartmentColumns + ` FROM apartments` + where + ` ORDER BY id`
 
-	context, close := context.WithTimeout(context.Background(), contextTimeGetOneApartment*time.Second)
-	defer close()
+	// Only the preparation is bounded: an export streams for as long as the client reads it and ends with the
+	// request when the client goes away.
+	context, close := context.WithTimeout(ctx, contextTimeEachApartment*time.Second)
+	stmt, err := as.db.PrepareContext(context, q)
+	close()
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
 
-	if err = as.db.PingContext(context); err != nil {
+	for rows.Next() {
+		apartment := &entity.Apartment{}
+		err = scanApartment(rows, apartment)
+		if err != nil {
+			return err
+		}
+		if err = fn(apartment); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func (as *apartmentAdapter) GetByID(ctx context.Context, id int) (apartment *entity.Apartment, err error) {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
 		return
 	}
+	q := `SELECT ` + apartmentColumns + ` FROM apartments WHERE id=? AND agency_id=? AND deleted_at IS NULL`
+
+	context, close := context.WithTimeout(ctx, contextTimeGetOneApartment*time.Second)
+	defer close()
 
 	stmt, err := as.db.PrepareContext(context, q)
 	if err != nil {
@@ -76,82 +148,274 @@ func (as *apartmentAdapter) GetByID(id int) (apartment *entity.Apartment, err er
 	}
 
 	apartment = &entity.Apartment{}
-	if err = stmt.QueryRowContext(context, id).Scan(&apartment.ID, &apartment.Title, &apartment.Price, &apartment.City, &apartment.Rooms, &apartment.Address, &apartment.Square, &apartment.IDRealtor, &apartment.UpdateTime, &apartment.CreateTime); err != nil {
+	if err = scanApartment(stmt.QueryRowContext(context, id, agencyID), apartment); err != nil {
 		return
 	}
 
 	return apartment, nil
 }
 
-func (as *apartmentAdapter) Create(apartment *entity.Apartment) (id int64, err error) {
+func (as *apartmentAdapter) Create(ctx context.Context, apartment *entity.Apartment, events []*entity.Event, audit *entity.AuditEntry) (id int64, err error) {
 
-	q := `INSERT INTO apartments (title, price, city, rooms, address, square, id_realtor, update_time, create_time) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return
+	}
+	q := `INSERT INTO apartments (agency_id, title, price, city, rooms, address, square, id_realtor, status, update_time, create_time, listing_type, monthly_rent, deposit, min_term_months, utilities_included, pets_allowed, lat, lng, address_street, address_house, address_building, address_city) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
-	context, close := context.WithTimeout(context.Background(), contextTimeCreateApartment*time.Second)
+	context, close := context.WithTimeout(ctx, contextTimeCreateApartment*time.Second)
 	defer close()
 
-	if err = as.db.PingContext(context); err != nil {
+	tx, err := as.db.BeginTx(context, nil)
+	if err != nil {
 		return
 	}
+	defer tx.Rollback()
 
-	stmt, err := as.db.PrepareContext(context, q)
-	if err != nil {
+	if err = checkRealtor(context, tx, agencyID, apartment.IDRealtor); err != nil {
 		return
 	}
 
-	row, err := stmt.ExecContext(context, apartment.Title, apartment.Price, apartment.City, apartment.Rooms, apartment.Address, apartment.Square, apartment.IDRealtor, apartment.UpdateTime, apartment.CreateTime)
+	row, err := tx.ExecContext(context, q, agencyID, apartment.Title, apartment.Price, apartment.City, apartment.Rooms, apartment.Address, apartment.Square, apartment.IDRealtor, apartment.Status, apartment.UpdateTime, apartment.CreateTime, apartment.ListingType, apartment.MonthlyRent, apartment.Deposit, apartment.MinTermMonths, apartment.UtilitiesIncluded, apartment.PetsAllowed, apartment.Lat, apartment.Lng, apartment.NormalizedAddress.Street, apartment.NormalizedAddress.House, apartment.NormalizedAddress.Building, apartment.NormalizedAddress.City)
 	if err != nil {
 		return
 	}
 
 	id, err = row.LastInsertId()
+	if err != nil {
+		return
+	}
+	apartment.ID = int(id)
+
+	if err = insertEvents(context, tx, agencyID, apartment.ID, events); err != nil {
+		return
+	}
+	if err = insertAudit(context, tx, agencyID, apartment.ID, audit); err != nil {
+		return
+	}
 
-	return id, err
+	return id, tx.Commit()
 }
 
-func (as *apartmentAdapter) Update(apartment *entity.Apartment) (aff int64, err error) {
+func (as *apartmentAdapter) UpdateFields(ctx context.Context, id int, version int, fields map[string]any, events []*entity.Event, audit *entity.AuditEntry) (aff int64, err error) {
 
-	q := `UPDATE apartments SET title=?, price=?, city=?, rooms=?, address=?, square=?, id_realtor=?, update_time=?, create_time=? WHERE id=?`
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return
+	}
+	set, args, err := setClause(fields, apartmentUpdatableColumns)
+	if err != nil {
+		return
+	}
+	q := `UPDATE apartments SET ` + set + `, version=version+1 WHERE id=? AND agency_id=? AND version=? AND deleted_at IS NULL`
 
-	context, close := context.WithTimeout(context.Background(), contextTimeUpdateApartment*time.Second)
+	context, close := context.WithTimeout(ctx, contextTimeUpdateApartment*time.Second)
 	defer close()
 
-	if err = as.db.PingContext(context); err != nil {
-		return aff, err
+	tx, err := as.db.BeginTx(context, nil)
+	if err != nil {
+		return
 	}
+	defer tx.Rollback()
 
-	stmt, err := as.db.PrepareContext(context, q)
+	if realtorID, ok := fields["id_realtor"].(int); ok {
+		if err = checkRealtor(context, tx, agencyID, realtorID); err != nil {
+			return
+		}
+	}
+
+	result, err := tx.ExecContext(context, q, append(args, id, agencyID, version)...)
 	if err != nil {
 		return
 	}
 
-	result, err := stmt.ExecContext(context, apartment.Title, apartment.Price, apartment.City, apartment.Rooms, apartment.Address, apartment.Square, apartment.IDRealtor, apartment.UpdateTime, apartment.CreateTime, apartment.ID)
+	aff, err = result.RowsAffected()
 	if err != nil {
 		return
 	}
+	if aff == 0 {
+		return aff, entity.ErrVersionConflict
+	}
 
-	aff, err = result.RowsAffected()
+	if err = insertEvents(context, tx, agencyID, id, events); err != nil {
+		return
+	}
+	if err = insertAudit(context, tx, agencyID, id, audit); err != nil {
+		return
+	}
 
-	return aff, err
+	return aff, tx.Commit()
 }
 
-func (as *apartmentAdapter) Delete(id int) error {
+func (as *apartmentAdapter) Delete(ctx context.Context, id int, version int, events []*entity.Event, audit *entity.AuditEntry) error {
 
-	q := `DELETE FROM apartments WHERE id=?`
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return err
+	}
+	q := `UPDATE apartments SET deleted_at=?, version=version+1 WHERE id=? AND agency_id=? AND version=? AND deleted_at IS NULL`
 
-	context, close := context.WithTimeout(context.Background(), contextTimeDeleteApartment*time.Second)
+	context, close := context.WithTimeout(ctx, contextTimeDeleteApartment*time.Second)
 	defer close()
 
-	if err := as.db.PingContext(context); err != nil {
+	tx, err := as.db.BeginTx(context, nil)
+	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	stmt, err := as.db.PrepareContext(context, q)
+	result, err := tx.ExecContext(context, q, time.Now(), id, agencyID, version)
+	if err != nil {
+		return err
+	}
+
+	aff, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if aff == 0 {
+		return entity.ErrVersionConflict
+	}
+
+	if err = insertEvents(context, tx, agencyID, id, events); err != nil {
+		return err
+	}
+	if err = insertAudit(context, tx, agencyID, id, audit); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (as *apartmentAdapter) Restore(ctx context.Context, id int, events []*entity.Event, audit *entity.AuditEntry) error {
+
+	agencyID, err := tenant(ctx)
 	if err != nil {
 		return err
 	}
+	q := `UPDATE apartments SET deleted_at=NULL, version=version+1 WHERE id=? AND agency_id=? AND deleted_at IS NOT NULL`
 
-	_, err = stmt.ExecContext(context, "", id)
+	context, close := context.WithTimeout(ctx, contextTimeRestoreApartment*time.Second)
+	defer close()
+
+	tx, err := as.db.BeginTx(context, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(context, q, id, agencyID)
+	if err != nil {
+		return err
+	}
+
+	aff, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if aff == 0 {
+		return sql.ErrNoRows
+	}
+
+	if err = insertEvents(context, tx, agencyID, id, events); err != nil {
+		return err
+	}
+	if audit != nil {
+		restored := &entity.Apartment{}
+		if err = scanApartment(tx.QueryRowContext(context, `SELECT `+apartmentColumns+` FROM apartments WHERE id=? AND agency_id=?`, id, agencyID), restored); err != nil {
+			return err
+		}
+		audit.After = restored
+	}
+	if err = insertAudit(context, tx, agencyID, id, audit); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (as *apartmentAdapter) GetDeleted(ctx context.Context, page int, pageSize int) (apartments []*entity.Apartment, err error) {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return
+	}
+	q := `SELECT ` + apartmentColumns + ` FROM apartments WHERE agency_id=? AND deleted_at IS NOT NULL ORDER BY deleted_at DESC LIMIT ?,?`
+
+	context, close := context.WithTimeout(ctx, contextTimeGetAllApartment*time.Second)
+	defer close()
+
+	rows, err := as.db.QueryContext(context, q, agencyID, page*pageSize, pageSize)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		apartment := &entity.Apartment{}
+		if err = scanApartment(rows, apartment); err != nil {
+			return
+		}
+		apartments = append(apartments, apartment)
+	}
+
+	return apartments, rows.Err()
+}
+
+func (as *apartmentAdapter) Purge(ctx context.Context, before time.Time) (ids []int, err error) {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return
+	}
+
+	context, close := context.WithTimeout(ctx, contextTimePurgeApartment*time.Second)
+	defer close()
+
+	return purge(context, as.db, "apartments", agencyID, before)
+}
+
+// CountPublished counts published apartments by city and listing type.
+func (as *apartmentAdapter) CountPublished(ctx context.Context) (counts []*entity.ListingCount, err error) {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return
+	}
+	q := `SELECT city, listing_type, COUNT(*) FROM apartments WHERE agency_id=? AND status=? AND deleted_at IS NULL GROUP BY city, listing_type`
+
+	context, close := context.WithTimeout(ctx, contextTimeCountApartment*time.Second)
+	defer close()
+
+	rows, err := as.db.QueryContext(context, q, agencyID, entity.ApartmentStatusPublished)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		c := &entity.ListingCount{}
+		if err = rows.Scan(&c.City, &c.ListingType, &c.Count); err != nil {
+			return
+		}
+		counts = append(counts, c)
+	}
+
+	return counts, rows.Err()
+}
+
+// checkRealtor fails with entity.ErrValidation unless the realtor exists in the agency,
+// so an apartment can't be assigned to a realtor of another agency. Zero means no realtor.
+func checkRealtor(ctx context.Context, tx *sql.Tx, agencyID int, realtorID int) error {
+	if realtorID == 0 {
+		return nil
+	}
+
+	var id int
+	err := tx.QueryRowContext(ctx, `SELECT id FROM realtors WHERE id=? AND agency_id=? AND deleted_at IS NULL`, realtorID, agencyID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: realtor %d does not exist", entity.ErrValidation, realtorID)
+	}
 
 	return err
 }
@@ -0,0 +1,192 @@
+package adapterSql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"gilab.com/estate-agency-api/internal/entity"
+)
+
+const (
+	contextTimeGetAllAPIKey = 2
+	contextTimeGetOneAPIKey = 1
+	contextTimeCreateAPIKey = 1
+	contextTimeUpdateAPIKey = 1
+)
+
+const apiKeyColumns = `id, agency_id, id_realtor, name, prefix, key_hash, scopes, expires_at, last_used_at, revoked_at, created_by, created_at`
+
+func scanAPIKey(row scanner, key *entity.APIKey) error {
+	var scopes []byte
+	if err := row.Scan(&key.ID, &key.AgencyID, &key.IDRealtor, &key.Name, &key.Prefix, &key.Hash, &scopes, &key.ExpiresAt, &key.LastUsedAt, &key.RevokedAt, &key.CreatedBy, &key.CreatedAt); err != nil {
+		return err
+	}
+	return json.Unmarshal(scopes, &key.Scopes)
+}
+
+type apiKeyAdapter struct {
+	db *sql.DB
+}
+
+func NewAPIKeyAdapter(db *sql.DB) *apiKeyAdapter {
+	return &apiKeyAdapter{db: db}
+}
+
+func (ka *apiKeyAdapter) GetAll(ctx context.Context, page int, pageSize int) (keys []*entity.APIKey, err error) {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return
+	}
+	q := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE agency_id=? ORDER BY id LIMIT ?,?`
+
+	context, close := context.WithTimeout(ctx, contextTimeGetAllAPIKey*time.Second)
+	defer close()
+
+	rows, err := ka.db.QueryContext(context, q, agencyID, page*pageSize, pageSize)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		key := &entity.APIKey{}
+		if err = scanAPIKey(rows, key); err != nil {
+			return
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+func (ka *apiKeyAdapter) GetByID(ctx context.Context, id int) (key *entity.APIKey, err error) {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return
+	}
+	q := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE id=? AND agency_id=?`
+
+	context, close := context.WithTimeout(ctx, contextTimeGetOneAPIKey*time.Second)
+	defer close()
+
+	key = &entity.APIKey{}
+	if err = scanAPIKey(ka.db.QueryRowContext(context, q, id, agencyID), key); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// GetByHash looks the key up in every agency, since authentication is what tells the agency of the request.
+func (ka *apiKeyAdapter) GetByHash(ctx context.Context, hash string) (key *entity.APIKey, err error) {
+
+	q := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE key_hash=?`
+
+	context, close := context.WithTimeout(ctx, contextTimeGetOneAPIKey*time.Second)
+	defer close()
+
+	key = &entity.APIKey{}
+	if err = scanAPIKey(ka.db.QueryRowContext(context, q, hash), key); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func (ka *apiKeyAdapter) Create(ctx context.Context, key *entity.APIKey, audit *entity.AuditEntry) (id int64, err error) {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return
+	}
+	q := `INSERT INTO api_keys (agency_id, id_realtor, name, prefix, key_hash, scopes, expires_at, created_by, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	scopes, err := json.Marshal(key.Scopes)
+	if err != nil {
+		return
+	}
+
+	context, close := context.WithTimeout(ctx, contextTimeCreateAPIKey*time.Second)
+	defer close()
+
+	tx, err := ka.db.BeginTx(context, nil)
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(context, q, agencyID, key.IDRealtor, key.Name, key.Prefix, key.Hash, scopes, key.ExpiresAt, key.CreatedBy, key.CreatedAt)
+	if err != nil {
+		return
+	}
+
+	id, err = result.LastInsertId()
+	if err != nil {
+		return
+	}
+	key.ID = int(id)
+
+	if err = insertAudit(context, tx, agencyID, key.ID, audit); err != nil {
+		return
+	}
+
+	return id, tx.Commit()
+}
+
+// Revoke marks an active key as revoked; revoking it again is reported as sql.ErrNoRows.
+func (ka *apiKeyAdapter) Revoke(ctx context.Context, id int, at time.Time, audit *entity.AuditEntry) error {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return err
+	}
+	q := `UPDATE api_keys SET revoked_at=? WHERE id=? AND agency_id=? AND revoked_at IS NULL`
+
+	context, close := context.WithTimeout(ctx, contextTimeUpdateAPIKey*time.Second)
+	defer close()
+
+	tx, err := ka.db.BeginTx(context, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(context, q, at, id, agencyID)
+	if err != nil {
+		return err
+	}
+
+	aff, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if aff == 0 {
+		return sql.ErrNoRows
+	}
+
+	if err = insertAudit(context, tx, agencyID, id, audit); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (ka *apiKeyAdapter) Touch(ctx context.Context, id int, at time.Time) error {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return err
+	}
+	q := `UPDATE api_keys SET last_used_at=? WHERE id=? AND agency_id=?`
+
+	context, close := context.WithTimeout(ctx, contextTimeUpdateAPIKey*time.Second)
+	defer close()
+
+	_, err = ka.db.ExecContext(context, q, at, id, agencyID)
+
+	return err
+}
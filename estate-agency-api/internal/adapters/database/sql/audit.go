@@ -0,0 +1,76 @@
+package adapterSql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"gilab.com/estate-agency-api/internal/entity"
+)
+
+const contextTimeFindAudit = 2
+
+// insertAudit writes the audit entry of a change of the agency inside the transaction of the change, so that
+// neither is stored without the other. An entry without an entity ID gets entityID, which lets creations
+// reference the freshly inserted row. A nil entry is skipped.
+func insertAudit(ctx context.Context, tx *sql.Tx, agencyID int, entityID int, entry *entity.AuditEntry) error {
+	if entry == nil {
+		return nil
+	}
+
+	if entry.EntityID == 0 {
+		entry.EntityID = entityID
+	}
+	if err := entry.TakeDiff(); err != nil {
+		return err
+	}
+
+	q := `INSERT INTO audit_log (agency_id, actor, entity_type, entity_id, action, diff, request_id, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	result, err := tx.ExecContext(ctx, q, agencyID, entry.Actor, entry.EntityType, entry.EntityID, entry.Action, []byte(entry.Diff), entry.RequestID, entry.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	entry.ID, err = result.LastInsertId()
+	return err
+}
+
+type auditAdapter struct {
+	db *sql.DB
+}
+
+func NewAuditAdapter(db *sql.DB) *auditAdapter {
+	return &auditAdapter{db: db}
+}
+
+func (aa *auditAdapter) Find(ctx context.Context, filter entity.AuditFilter, page int, pageSize int) (entries []*entity.AuditEntry, err error) {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return
+	}
+	where, args := auditFilterClause(agencyID, filter)
+	q := `SELECT id, actor, entity_type, entity_id, action, diff, request_id, created_at FROM audit_log` + where + ` ORDER BY id DESC LIMIT ?,?`
+
+	context, close := context.WithTimeout(ctx, contextTimeFindAudit*time.Second)
+	defer close()
+
+	rows, err := aa.db.QueryContext(context, q, append(args, page*pageSize, pageSize)...)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		entry := &entity.AuditEntry{}
+		var diff []byte
+		if err = rows.Scan(&entry.ID, &entry.Actor, &entry.EntityType, &entry.EntityID, &entry.Action, &diff, &entry.RequestID, &entry.CreatedAt); err != nil {
+			return
+		}
+		entry.Diff = diff
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
@@ -0,0 +1,198 @@
+package adapterSql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"gilab.com/estate-agency-api/internal/entity"
+	"github.com/go-sql-driver/mysql"
+)
+
+const (
+	contextTimeGetAllClient = 2
+	contextTimeGetOneClient = 1
+	contextTimeCreateClient = 1
+	contextTimeUpdateClient = 1
+	contextTimeDeleteClient = 1
+)
+
+const clientColumns = `id, first_name, last_name, phone, email, created_at`
+
+func scanClient(row scanner, client *entity.Client) error {
+	return row.Scan(&client.ID, &client.FirstName, &client.LastName, &client.Phone, &client.Email, &client.CreatedAt)
+}
+
+func duplicateEmail(err error) error {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrDuplicateEntry {
+		return entity.ErrClientEmailExists
+	}
+	return err
+}
+
+type clientAdapter struct {
+	db *sql.DB
+}
+
+func NewClientAdapter(db *sql.DB) *clientAdapter {
+	return &clientAdapter{db: db}
+}
+
+func (cs *clientAdapter) GetAll(ctx context.Context, page int, pageSize int) (clients []*entity.Client, err error) {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return
+	}
+	q := `SELECT ` + clientColumns + ` FROM clients WHERE agency_id=? ORDER BY id LIMIT ?,?`
+
+	context, close := context.WithTimeout(ctx, contextTimeGetAllClient*time.Second)
+	defer close()
+
+	rows, err := cs.db.QueryContext(context, q, agencyID, page*pageSize, pageSize)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		client := &entity.Client{}
+		if err = scanClient(rows, client); err != nil {
+			return
+		}
+		clients = append(clients, client)
+	}
+
+	return clients, rows.Err()
+}
+
+func (cs *clientAdapter) GetByID(ctx context.Context, id int) (client *entity.Client, err error) {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return
+	}
+	q := `SELECT ` + clientColumns + ` FROM clients WHERE id=? AND agency_id=?`
+
+	context, close := context.WithTimeout(ctx, contextTimeGetOneClient*time.Second)
+	defer close()
+
+	client = &entity.Client{}
+	if err = scanClient(cs.db.QueryRowContext(context, q, id, agencyID), client); err != nil {
+		return nil, err
+	}
+
+	return client, nil
+}
+
+func (cs *clientAdapter) Create(ctx context.Context, client *entity.Client, audit *entity.AuditEntry) (id int64, err error) {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return
+	}
+	q := `INSERT INTO clients (agency_id, first_name, last_name, phone, email, created_at) VALUES (?, ?, ?, ?, ?, ?)`
+
+	context, close := context.WithTimeout(ctx, contextTimeCreateClient*time.Second)
+	defer close()
+
+	tx, err := cs.db.BeginTx(context, nil)
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(context, q, agencyID, client.FirstName, client.LastName, client.Phone, client.Email, client.CreatedAt)
+	if err != nil {
+		return 0, duplicateEmail(err)
+	}
+
+	id, err = result.LastInsertId()
+	if err != nil {
+		return
+	}
+	client.ID = int(id)
+
+	if err = insertAudit(context, tx, agencyID, client.ID, audit); err != nil {
+		return
+	}
+
+	return id, tx.Commit()
+}
+
+func (cs *clientAdapter) UpdateFields(ctx context.Context, id int, fields map[string]any, audit *entity.AuditEntry) (aff int64, err error) {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return
+	}
+	set, args, err := setClause(fields, clientUpdatableColumns)
+	if err != nil {
+		return
+	}
+	q := `UPDATE clients SET ` + set + ` WHERE id=? AND agency_id=?`
+
+	context, close := context.WithTimeout(ctx, contextTimeUpdateClient*time.Second)
+	defer close()
+
+	tx, err := cs.db.BeginTx(context, nil)
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(context, q, append(args, id, agencyID)...)
+	if err != nil {
+		return 0, duplicateEmail(err)
+	}
+
+	aff, err = result.RowsAffected()
+	if err != nil {
+		return
+	}
+
+	if err = insertAudit(context, tx, agencyID, id, audit); err != nil {
+		return
+	}
+
+	return aff, tx.Commit()
+}
+
+func (cs *clientAdapter) Delete(ctx context.Context, id int, audit *entity.AuditEntry) error {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return err
+	}
+	q := `DELETE FROM clients WHERE id=? AND agency_id=?`
+
+	context, close := context.WithTimeout(ctx, contextTimeDeleteClient*time.Second)
+	defer close()
+
+	tx, err := cs.db.BeginTx(context, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(context, q, id, agencyID)
+	if err != nil {
+		return err
+	}
+
+	aff, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if aff == 0 {
+		return sql.ErrNoRows
+	}
+
+	if err = insertAudit(context, tx, agencyID, id, audit); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
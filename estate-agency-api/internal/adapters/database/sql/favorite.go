@@ -0,0 +1,166 @@
+package adapterSql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"gilab.com/estate-agency-api/internal/entity"
+)
+
+const (
+	contextTimeAddFavorite    = 1
+	contextTimeRemoveFavorite = 1
+	contextTimeGetFavorites   = 2
+	contextTimeTopFavorites   = 2
+)
+
+type favoriteAdapter struct {
+	db *sql.DB
+}
+
+func NewFavoriteAdapter(db *sql.DB) *favoriteAdapter {
+	return &favoriteAdapter{db: db}
+}
+
+// Add stores the favorite. Adding an existing favorite again only updates its note,
+// so the price it was favorited at is preserved. Nothing is stored unless both the client
+// and the apartment belong to the agency, and then no audit entry is written either.
+func (fs *favoriteAdapter) Add(ctx context.Context, favorite *entity.Favorite, audit *entity.AuditEntry) error {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return err
+	}
+	q := `INSERT INTO favorites (id_client, id_apartment, note, price_at_favorite, created_at)
+		SELECT c.id, a.id, ?, ?, ? FROM clients c JOIN apartments a ON a.agency_id=c.agency_id
+		WHERE c.id=? AND a.id=? AND c.agency_id=?
+		ON DUPLICATE KEY UPDATE note=VALUES(note)`
+
+	context, close := context.WithTimeout(ctx, contextTimeAddFavorite*time.Second)
+	defer close()
+
+	tx, err := fs.db.BeginTx(context, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(context, q, favorite.Note, favorite.PriceAtFavorite, favorite.CreatedAt, favorite.IDClient, favorite.IDApartment, agencyID)
+	if err != nil {
+		return err
+	}
+
+	aff, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if aff == 0 {
+		return nil
+	}
+
+	if err = insertAudit(context, tx, agencyID, favorite.IDClient, audit); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (fs *favoriteAdapter) Remove(ctx context.Context, clientID int, apartmentID int, audit *entity.AuditEntry) error {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return err
+	}
+	q := `DELETE f FROM favorites f JOIN clients c ON c.id=f.id_client WHERE f.id_client=? AND f.id_apartment=? AND c.agency_id=?`
+
+	context, close := context.WithTimeout(ctx, contextTimeRemoveFavorite*time.Second)
+	defer close()
+
+	tx, err := fs.db.BeginTx(context, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(context, q, clientID, apartmentID, agencyID)
+	if err != nil {
+		return err
+	}
+
+	aff, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if aff == 0 {
+		return sql.ErrNoRows
+	}
+
+	if err = insertAudit(context, tx, agencyID, clientID, audit); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (fs *favoriteAdapter) GetByClient(ctx context.Context, clientID int, page int, pageSize int) (favorites []*entity.FavoriteView, err error) {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return
+	}
+	q := `SELECT f.id_client, f.id_apartment, f.note, f.price_at_favorite, f.created_at, a.title, a.city, a.price, a.status, a.deleted_at IS NOT NULL
+		FROM favorites f JOIN apartments a ON a.id=f.id_apartment
+		WHERE f.id_client=? AND a.agency_id=? ORDER BY f.created_at DESC LIMIT ?,?`
+
+	context, close := context.WithTimeout(ctx, contextTimeGetFavorites*time.Second)
+	defer close()
+
+	rows, err := fs.db.QueryContext(context, q, clientID, agencyID, page*pageSize, pageSize)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		f := &entity.FavoriteView{}
+		if err = rows.Scan(&f.IDClient, &f.IDApartment, &f.Note, &f.PriceAtFavorite, &f.CreatedAt, &f.Title, &f.City, &f.Price, &f.Status, &f.Deleted); err != nil {
+			return
+		}
+		favorites = append(favorites, f)
+	}
+
+	return favorites, rows.Err()
+}
+
+// TopByRealtor counts favorites of the realtor's listings, most favorited first.
+func (fs *favoriteAdapter) TopByRealtor(ctx context.Context, realtorID int, limit int) (counts []*entity.FavoriteCount, err error) {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return
+	}
+	q := `SELECT a.id, a.title, COUNT(*) AS favorites
+		FROM favorites f JOIN apartments a ON a.id=f.id_apartment
+		WHERE a.id_realtor=? AND a.agency_id=? AND a.deleted_at IS NULL
+		GROUP BY a.id, a.title ORDER BY favorites DESC, a.id LIMIT ?`
+
+	context, close := context.WithTimeout(ctx, contextTimeTopFavorites*time.Second)
+	defer close()
+
+	rows, err := fs.db.QueryContext(context, q, realtorID, agencyID, limit)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		c := &entity.FavoriteCount{}
+		if err = rows.Scan(&c.IDApartment, &c.Title, &c.Favorites); err != nil {
+			return
+		}
+		counts = append(counts, c)
+	}
+
+	return counts, rows.Err()
+}
@@ -0,0 +1,49 @@
+package adapterSql
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var apartmentUpdatableColumns = map[string]bool{
+	"title": true, "price": true, "city": true, "rooms": true, "address": true,
+	"square": true, "id_realtor": true, "status": true, "update_time": true,
+	"listing_type": true, "monthly_rent": true, "deposit": true, "min_term_months": true,
+	"utilities_included": true, "pets_allowed": true, "lat": true, "lng": true,
+	"address_street": true, "address_house": true, "address_building": true, "address_city": true,
+}
+
+var realtorUpdatableColumns = map[string]bool{
+	"first_name": true, "last_name": true, "phone": true, "email": true, "rating": true, "experience": true,
+}
+
+var webhookUpdatableColumns = map[string]bool{
+	"url": true, "event_types": true, "secret": true, "active": true,
+}
+
+var clientUpdatableColumns = map[string]bool{
+	"first_name": true, "last_name": true, "phone": true, "email": true,
+}
+
+// setClause builds the SET list of an UPDATE from the changed columns. Column names are
+// checked against allowed because they end up in the query text.
+func setClause(fields map[string]any, allowed map[string]bool) (string, []any, error) {
+	columns := make([]string, 0, len(fields))
+	for column := range fields {
+		if !allowed[column] {
+			return "", nil, fmt.Errorf("column %q can not be updated", column)
+		}
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	sets := make([]string, len(columns))
+	args := make([]any, len(columns))
+	for i, column := range columns {
+		sets[i] = column + "=?"
+		args[i] = fields[column]
+	}
+
+	return strings.Join(sets, ", "), args, nil
+}
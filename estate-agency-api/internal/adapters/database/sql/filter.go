@@ -0,0 +1,140 @@
+package adapterSql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gilab.com/estate-agency-api/internal/entity"
+)
+
+func apartmentFilterClause(agencyID int, filter entity.ApartmentFilter) (string, []any) {
+	var (
+		conds = []string{"agency_id=?", "deleted_at IS NULL"}
+		args  = []any{agencyID}
+	)
+
+	if len(filter.City) != 0 {
+		conds = append(conds, "city=?")
+		args = append(args, filter.City)
+	}
+	if filter.MinPrice != 0 {
+		conds = append(conds, "price>=?")
+		args = append(args, filter.MinPrice)
+	}
+	if filter.MaxPrice != 0 {
+		conds = append(conds, "price<=?")
+		args = append(args, filter.MaxPrice)
+	}
+	if filter.Rooms != 0 {
+		conds = append(conds, "rooms=?")
+		args = append(args, filter.Rooms)
+	}
+	if filter.MinSquare != 0 {
+		conds = append(conds, "square>=?")
+		args = append(args, filter.MinSquare)
+	}
+	if filter.MaxSquare != 0 {
+		conds = append(conds, "square<=?")
+		args = append(args, filter.MaxSquare)
+	}
+	if filter.IDRealtor != 0 {
+		conds = append(conds, "id_realtor=?")
+		args = append(args, filter.IDRealtor)
+	}
+	if len(filter.Status) != 0 {
+		conds = append(conds, "status=?")
+		args = append(args, filter.Status)
+	}
+	if len(filter.ListingType) != 0 {
+		conds = append(conds, "listing_type=?")
+		args = append(args, filter.ListingType)
+	}
+	if filter.IDTeam != 0 {
+		conds = append(conds, "id_realtor IN (SELECT id FROM realtors WHERE id_team=? AND agency_id=?)")
+		args = append(args, filter.IDTeam, agencyID)
+	}
+	if filter.HasGeo() {
+		conds = append(conds, "lat IS NOT NULL")
+	}
+	if filter.Box != nil {
+		conds = append(conds, "MBRContains(ST_GeomFromText(?, 4326), location)")
+		args = append(args, boxPolygon(*filter.Box))
+	}
+	if filter.Center != nil && filter.RadiusKM != 0 {
+		// The enclosing box lets the spatial index narrow the rows down before the distances are computed.
+		if box, ok := entity.BoxAround(*filter.Center, filter.RadiusKM); ok {
+			conds = append(conds, "MBRContains(ST_GeomFromText(?, 4326), location)")
+			args = append(args, boxPolygon(box))
+		}
+		conds = append(conds, apartmentDistance+"<=?")
+		args = append(args, filter.Center.Lat, filter.Center.Lng, filter.RadiusKM)
+	}
+
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+// boxPolygon is the WKT of the box in the lat-lng axis order of SRID 4326.
+func boxPolygon(box entity.GeoBox) string {
+	minLat, minLng := strconv.FormatFloat(box.Min.Lat, 'f', -1, 64), strconv.FormatFloat(box.Min.Lng, 'f', -1, 64)
+	maxLat, maxLng := strconv.FormatFloat(box.Max.Lat, 'f', -1, 64), strconv.FormatFloat(box.Max.Lng, 'f', -1, 64)
+
+	return fmt.Sprintf("POLYGON((%[1]s %[2]s, %[3]s %[2]s, %[3]s %[4]s, %[1]s %[4]s, %[1]s %[2]s))", minLat, minLng, maxLat, maxLng)
+}
+
+func auditFilterClause(agencyID int, filter entity.AuditFilter) (string, []any) {
+	var (
+		conds = []string{"agency_id=?"}
+		args  = []any{agencyID}
+	)
+
+	if len(filter.EntityType) != 0 {
+		conds = append(conds, "entity_type=?")
+		args = append(args, filter.EntityType)
+	}
+	if filter.EntityID != 0 {
+		conds = append(conds, "entity_id=?")
+		args = append(args, filter.EntityID)
+	}
+	if len(filter.Actor) != 0 {
+		conds = append(conds, "actor=?")
+		args = append(args, filter.Actor)
+	}
+	if len(filter.Action) != 0 {
+		conds = append(conds, "action=?")
+		args = append(args, filter.Action)
+	}
+	if !filter.From.IsZero() {
+		conds = append(conds, "created_at>=?")
+		args = append(args, filter.From)
+	}
+	if !filter.To.IsZero() {
+		conds = append(conds, "created_at<?")
+		args = append(args, filter.To)
+	}
+
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+// leaseFilterClause filters leases l joined with their apartments a.
+func leaseFilterClause(agencyID int, filter entity.LeaseFilter) (string, []any) {
+	var (
+		conds = []string{"a.agency_id=?"}
+		args  = []any{agencyID}
+	)
+
+	if filter.IDApartment != 0 {
+		conds = append(conds, "l.id_apartment=?")
+		args = append(args, filter.IDApartment)
+	}
+	if filter.IDClient != 0 {
+		conds = append(conds, "l.id_client=?")
+		args = append(args, filter.IDClient)
+	}
+	if len(filter.Status) != 0 {
+		conds = append(conds, "l.status=?")
+		args = append(args, filter.Status)
+	}
+
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
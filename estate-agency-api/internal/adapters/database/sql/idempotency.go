@@ -0,0 +1,119 @@
+package adapterSql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"gilab.com/estate-agency-api/internal/entity"
+	"github.com/go-sql-driver/mysql"
+)
+
+const (
+	contextTimeGetIdempotency      = 1
+	contextTimeReserveIdempotency  = 1
+	contextTimeCompleteIdempotency = 1
+	contextTimeReleaseIdempotency  = 1
+	contextTimeCleanIdempotency    = 5
+)
+
+// idempotencyCleanBatch bounds the rows deleted by one statement, so that cleaning never holds long locks.
+const idempotencyCleanBatch = 1000
+
+const mysqlErrDuplicateEntry = 1062
+
+type idempotencyAdapter struct {
+	db *sql.DB
+}
+
+func NewIdempotencyAdapter(db *sql.DB) *idempotencyAdapter {
+	return &idempotencyAdapter{db: db}
+}
+
+func (ia *idempotencyAdapter) Get(ctx context.Context, key string) (record *entity.IdempotencyRecord, err error) {
+
+	q := `SELECT idem_key, request_hash, status_code, content_type, response, completed FROM idempotency_keys WHERE idem_key=? AND expires_at>?`
+
+	context, close := context.WithTimeout(ctx, contextTimeGetIdempotency*time.Second)
+	defer close()
+
+	record = &entity.IdempotencyRecord{}
+	err = ia.db.QueryRowContext(context, q, key, time.Now()).Scan(&record.Key, &record.RequestHash, &record.StatusCode, &record.ContentType, &record.Response, &record.Completed)
+	if err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+func (ia *idempotencyAdapter) Reserve(ctx context.Context, record *entity.IdempotencyRecord) error {
+
+	qExpired := `DELETE FROM idempotency_keys WHERE idem_key=? AND expires_at<=?`
+	q := `INSERT INTO idempotency_keys (idem_key, request_hash, expires_at) VALUES (?, ?, ?)`
+
+	context, close := context.WithTimeout(ctx, contextTimeReserveIdempotency*time.Second)
+	defer close()
+
+	if _, err := ia.db.ExecContext(context, qExpired, record.Key, time.Now()); err != nil {
+		return err
+	}
+
+	_, err := ia.db.ExecContext(context, q, record.Key, record.RequestHash, record.ExpiresAt)
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrDuplicateEntry {
+		return entity.ErrIdempotencyKeyExists
+	}
+
+	return err
+}
+
+func (ia *idempotencyAdapter) Complete(ctx context.Context, record *entity.IdempotencyRecord) error {
+
+	q := `UPDATE idempotency_keys SET status_code=?, content_type=?, response=?, completed=1 WHERE idem_key=?`
+
+	context, close := context.WithTimeout(ctx, contextTimeCompleteIdempotency*time.Second)
+	defer close()
+
+	_, err := ia.db.ExecContext(context, q, record.StatusCode, record.ContentType, record.Response, record.Key)
+
+	return err
+}
+
+func (ia *idempotencyAdapter) Release(ctx context.Context, key string) error {
+
+	q := `DELETE FROM idempotency_keys WHERE idem_key=? AND completed=0`
+
+	context, close := context.WithTimeout(ctx, contextTimeReleaseIdempotency*time.Second)
+	defer close()
+
+	_, err := ia.db.ExecContext(context, q, key)
+
+	return err
+}
+
+// Clean deletes the keys expired before the given time, batch by batch.
+func (ia *idempotencyAdapter) Clean(ctx context.Context, before time.Time) (aff int64, err error) {
+
+	q := `DELETE FROM idempotency_keys WHERE expires_at<=? LIMIT ?`
+
+	for {
+		n, err := ia.clean(ctx, q, before)
+		aff += n
+		if err != nil || n < idempotencyCleanBatch {
+			return aff, err
+		}
+	}
+}
+
+func (ia *idempotencyAdapter) clean(ctx context.Context, q string, before time.Time) (int64, error) {
+	context, close := context.WithTimeout(ctx, contextTimeCleanIdempotency*time.Second)
+	defer close()
+
+	result, err := ia.db.ExecContext(context, q, before, idempotencyCleanBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
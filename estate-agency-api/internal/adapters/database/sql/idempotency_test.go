@@ -0,0 +1,57 @@
+package adapterSql
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestIdempotencyClean(t *testing.T) {
+	failure := errors.New("connection lost")
+
+	tests := []struct {
+		name    string
+		batches []int64
+		err     error
+		want    int64
+	}{
+		{name: "nothing expired", batches: []int64{0}},
+		{name: "one batch", batches: []int64{3}, want: 3},
+		{name: "several batches", batches: []int64{idempotencyCleanBatch, idempotencyCleanBatch, 1}, want: 2*idempotencyCleanBatch + 1},
+		{name: "full last batch", batches: []int64{idempotencyCleanBatch, 0}, want: idempotencyCleanBatch},
+		{name: "failing batch", batches: []int64{idempotencyCleanBatch}, err: failure, want: idempotencyCleanBatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+
+			before := time.Now()
+			for _, n := range tt.batches {
+				mock.ExpectExec(`DELETE FROM idempotency_keys WHERE expires_at<=\? LIMIT \?`).
+					WithArgs(before, idempotencyCleanBatch).WillReturnResult(sqlmock.NewResult(0, n))
+			}
+			if tt.err != nil {
+				mock.ExpectExec(`DELETE FROM idempotency_keys`).WillReturnError(tt.err)
+			}
+
+			got, err := NewIdempotencyAdapter(db).Clean(context.Background(), before)
+			if !errors.Is(err, tt.err) {
+				t.Errorf("Clean() error = %v, want %v", err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("Clean() = %d, want %d", got, tt.want)
+			}
+			if err = mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,260 @@
+package adapterSql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"gilab.com/estate-agency-api/internal/entity"
+)
+
+const (
+	contextTimeGetAllLease    = 2
+	contextTimeGetOneLease    = 1
+	contextTimeCreateLease    = 2
+	contextTimeRenewLease     = 2
+	contextTimeTerminateLease = 1
+	contextTimeExpiringLease  = 2
+)
+
+// leaseColumns are qualified by the alias l, since leases are always joined with their apartment's agency.
+const leaseColumns = `l.id, l.id_apartment, l.id_client, l.start_date, l.end_date, l.monthly_rent, l.deposit, l.payment_day, l.status, l.terminated_at, l.termination_reason, l.created_at, l.updated_at`
+
+const leasesOfAgency = ` FROM leases l JOIN apartments a ON a.id=l.id_apartment`
+
+func scanLease(row scanner, lease *entity.Lease) error {
+	return row.Scan(&lease.ID, &lease.IDApartment, &lease.IDClient, &lease.StartDate, &lease.EndDate, &lease.MonthlyRent, &lease.Deposit, &lease.PaymentDay, &lease.Status, &lease.TerminatedAt, &lease.TerminationReason, &lease.CreatedAt, &lease.UpdatedAt)
+}
+
+type leaseAdapter struct {
+	db *sql.DB
+}
+
+func NewLeaseAdapter(db *sql.DB) *leaseAdapter {
+	return &leaseAdapter{db: db}
+}
+
+func (ls *leaseAdapter) GetAll(ctx context.Context, filter entity.LeaseFilter, page int, pageSize int) (leases []*entity.Lease, err error) {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return
+	}
+	where, args := leaseFilterClause(agencyID, filter)
+	q := `SELECT ` + leaseColumns + leasesOfAgency + where + ` ORDER BY l.id LIMIT ?,?`
+
+	context, close := context.WithTimeout(ctx, contextTimeGetAllLease*time.Second)
+	defer close()
+
+	return ls.query(context, q, append(args, page*pageSize, pageSize)...)
+}
+
+func (ls *leaseAdapter) GetByID(ctx context.Context, id int) (lease *entity.Lease, err error) {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return
+	}
+	q := `SELECT ` + leaseColumns + leasesOfAgency + ` WHERE l.id=? AND a.agency_id=?`
+
+	context, close := context.WithTimeout(ctx, contextTimeGetOneLease*time.Second)
+	defer close()
+
+	lease = &entity.Lease{}
+	if err = scanLease(ls.db.QueryRowContext(context, q, id, agencyID), lease); err != nil {
+		return nil, err
+	}
+
+	return lease, nil
+}
+
+// Create stores the lease together with its charges unless another active lease of the apartment overlaps its dates.
+// The apartment row is locked so that concurrent leases of the same apartment are serialized.
+// The apartment and the client must belong to the agency, otherwise sql.ErrNoRows is returned.
+func (ls *leaseAdapter) Create(ctx context.Context, lease *entity.Lease, charges []*entity.RentCharge, audit *entity.AuditEntry) (id int64, err error) {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return
+	}
+	q := `INSERT INTO leases (id_apartment, id_client, start_date, end_date, monthly_rent, deposit, payment_day, status, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	context, close := context.WithTimeout(ctx, contextTimeCreateLease*time.Second)
+	defer close()
+
+	tx, err := ls.db.BeginTx(context, nil)
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	if err = checkLeaseOverlap(context, tx, agencyID, 0, lease.IDApartment, lease.StartDate, lease.EndDate); err != nil {
+		return
+	}
+	var clientID int
+	if err = tx.QueryRowContext(context, `SELECT id FROM clients WHERE id=? AND agency_id=?`, lease.IDClient, agencyID).Scan(&clientID); err != nil {
+		return
+	}
+
+	row, err := tx.ExecContext(context, q, lease.IDApartment, lease.IDClient, lease.StartDate, lease.EndDate, lease.MonthlyRent, lease.Deposit, lease.PaymentDay, lease.Status, lease.CreatedAt, lease.UpdatedAt)
+	if err != nil {
+		return
+	}
+
+	id, err = row.LastInsertId()
+	if err != nil {
+		return
+	}
+	lease.ID = int(id)
+
+	for _, c := range charges {
+		c.IDLease = lease.ID
+	}
+	if err = insertCharges(context, tx, agencyID, charges); err != nil {
+		return
+	}
+	if err = insertAudit(context, tx, agencyID, lease.ID, audit); err != nil {
+		return
+	}
+
+	return id, tx.Commit()
+}
+
+// Renew moves the end date of an active lease, sets its rent and adds the charges of the periods it did not have.
+func (ls *leaseAdapter) Renew(ctx context.Context, lease *entity.Lease, charges []*entity.RentCharge, audit *entity.AuditEntry) error {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return err
+	}
+	q := `UPDATE leases l JOIN apartments a ON a.id=l.id_apartment SET l.end_date=?, l.monthly_rent=?, l.updated_at=? WHERE l.id=? AND a.agency_id=? AND l.status=?`
+
+	context, close := context.WithTimeout(ctx, contextTimeRenewLease*time.Second)
+	defer close()
+
+	tx, err := ls.db.BeginTx(context, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = checkLeaseOverlap(context, tx, agencyID, lease.ID, lease.IDApartment, lease.StartDate, lease.EndDate); err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(context, q, lease.EndDate, lease.MonthlyRent, lease.UpdatedAt, lease.ID, agencyID, entity.LeaseStatusActive)
+	if err != nil {
+		return err
+	}
+
+	aff, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if aff == 0 {
+		return entity.ErrLeaseNotActive
+	}
+
+	if err = insertCharges(context, tx, agencyID, charges); err != nil {
+		return err
+	}
+	if err = insertAudit(context, tx, agencyID, lease.ID, audit); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Terminate ends an active lease and drops the unpaid charges of periods that start after its end.
+func (ls *leaseAdapter) Terminate(ctx context.Context, lease *entity.Lease, audit *entity.AuditEntry) error {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return err
+	}
+	q := `UPDATE leases l JOIN apartments a ON a.id=l.id_apartment SET l.status=?, l.end_date=?, l.terminated_at=?, l.termination_reason=?, l.updated_at=? WHERE l.id=? AND a.agency_id=? AND l.status=?`
+
+	context, close := context.WithTimeout(ctx, contextTimeTerminateLease*time.Second)
+	defer close()
+
+	tx, err := ls.db.BeginTx(context, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(context, q, lease.Status, lease.EndDate, lease.TerminatedAt, lease.TerminationReason, lease.UpdatedAt, lease.ID, agencyID, entity.LeaseStatusActive)
+	if err != nil {
+		return err
+	}
+
+	aff, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if aff == 0 {
+		return entity.ErrLeaseNotActive
+	}
+
+	if err = cancelCharges(context, tx, agencyID, lease.ID, lease.EndDate); err != nil {
+		return err
+	}
+	if err = insertAudit(context, tx, agencyID, lease.ID, audit); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Expiring returns active leases ending between from and to inclusive, soonest first.
+func (ls *leaseAdapter) Expiring(ctx context.Context, from entity.Date, to entity.Date) (leases []*entity.Lease, err error) {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return
+	}
+	q := `SELECT ` + leaseColumns + leasesOfAgency + ` WHERE a.agency_id=? AND l.status=? AND l.end_date BETWEEN ? AND ? ORDER BY l.end_date, l.id`
+
+	context, close := context.WithTimeout(ctx, contextTimeExpiringLease*time.Second)
+	defer close()
+
+	return ls.query(context, q, agencyID, entity.LeaseStatusActive, from, to)
+}
+
+func (ls *leaseAdapter) query(ctx context.Context, q string, args ...any) (leases []*entity.Lease, err error) {
+	rows, err := ls.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		lease := &entity.Lease{}
+		if err = scanLease(rows, lease); err != nil {
+			return
+		}
+		leases = append(leases, lease)
+	}
+
+	return leases, rows.Err()
+}
+
+// checkLeaseOverlap locks the apartment of the agency and fails with entity.ErrLeaseOverlap when an active lease
+// other than exceptID covers any day between start and end.
+func checkLeaseOverlap(ctx context.Context, tx *sql.Tx, agencyID int, exceptID int, apartmentID int, start entity.Date, end entity.Date) error {
+	var id int
+	if err := tx.QueryRowContext(ctx, `SELECT id FROM apartments WHERE id=? AND agency_id=? AND deleted_at IS NULL FOR UPDATE`, apartmentID, agencyID).Scan(&id); err != nil {
+		return err
+	}
+
+	var count int
+	q := `SELECT COUNT(*) FROM leases WHERE id_apartment=? AND id<>? AND status=? AND start_date<=? AND end_date>=?`
+	if err := tx.QueryRowContext(ctx, q, apartmentID, exceptID, entity.LeaseStatusActive, end, start).Scan(&count); err != nil {
+		return err
+	}
+	if count != 0 {
+		return entity.ErrLeaseOverlap
+	}
+
+	return nil
+}
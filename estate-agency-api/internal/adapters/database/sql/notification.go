@@ -0,0 +1,111 @@
+package adapterSql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"gilab.com/estate-agency-api/internal/entity"
+)
+
+const (
+	contextTimeEnqueueNotification = 1
+	contextTimeClaimNotifications  = 2
+	contextTimeUpdateNotification  = 1
+)
+
+// notificationColumns are qualified by the alias n, since notifications are joined with their client's agency.
+const notificationColumns = `n.id, n.id_client, n.kind, n.recipient, n.subject, n.body, n.dedup_key, n.status, n.attempts, n.next_attempt_at, n.last_error, n.created_at, n.sent_at`
+
+func scanNotification(row scanner, n *entity.Notification) error {
+	return row.Scan(&n.ID, &n.IDClient, &n.Kind, &n.Recipient, &n.Subject, &n.Body, &n.DedupKey, &n.Status, &n.Attempts, &n.NextAttemptAt, &n.LastError, &n.CreatedAt, &n.SentAt)
+}
+
+type notificationAdapter struct {
+	db *sql.DB
+}
+
+func NewNotificationAdapter(db *sql.DB) *notificationAdapter {
+	return &notificationAdapter{db: db}
+}
+
+// Enqueue stores a pending notification to a client of the agency; a notification with an existing dedup key is ignored.
+func (ns *notificationAdapter) Enqueue(ctx context.Context, n *entity.Notification) error {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return err
+	}
+	q := `INSERT IGNORE INTO notifications (id_client, kind, recipient, subject, body, dedup_key, status, attempts, next_attempt_at, last_error, created_at)
+		SELECT id, ?, ?, ?, ?, ?, ?, 0, ?, '', ? FROM clients WHERE id=? AND agency_id=?`
+
+	context, close := context.WithTimeout(ctx, contextTimeEnqueueNotification*time.Second)
+	defer close()
+
+	_, err = ns.db.ExecContext(context, q, n.Kind, n.Recipient, n.Subject, n.Body, n.DedupKey, n.Status, n.NextAttemptAt, n.CreatedAt, n.IDClient, agencyID)
+
+	return err
+}
+
+// Claim returns pending notifications of the agency that are due and postpones them by lease.
+func (ns *notificationAdapter) Claim(ctx context.Context, limit int, lease time.Duration) (notifications []*entity.Notification, err error) {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return
+	}
+	q := `SELECT ` + notificationColumns + ` FROM notifications n JOIN clients c ON c.id=n.id_client
+		WHERE c.agency_id=? AND n.status=? AND n.next_attempt_at<=? ORDER BY n.next_attempt_at LIMIT ? FOR UPDATE OF n SKIP LOCKED`
+
+	context, close := context.WithTimeout(ctx, contextTimeClaimNotifications*time.Second)
+	defer close()
+
+	tx, err := ns.db.BeginTx(context, nil)
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	rows, err := tx.QueryContext(context, q, agencyID, entity.NotificationPending, now, limit)
+	if err != nil {
+		return
+	}
+
+	for rows.Next() {
+		n := &entity.Notification{}
+		if err = scanNotification(rows, n); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		notifications = append(notifications, n)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, n := range notifications {
+		if _, err = tx.ExecContext(context, `UPDATE notifications SET next_attempt_at=? WHERE id=?`, now.Add(lease), n.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return notifications, tx.Commit()
+}
+
+func (ns *notificationAdapter) Update(ctx context.Context, n *entity.Notification) error {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return err
+	}
+	q := `UPDATE notifications n JOIN clients c ON c.id=n.id_client SET n.status=?, n.attempts=?, n.next_attempt_at=?, n.last_error=?, n.sent_at=? WHERE n.id=? AND c.agency_id=?`
+
+	context, close := context.WithTimeout(ctx, contextTimeUpdateNotification*time.Second)
+	defer close()
+
+	_, err = ns.db.ExecContext(context, q, n.Status, n.Attempts, n.NextAttemptAt, n.LastError, n.SentAt, n.ID, agencyID)
+
+	return err
+}
@@ -0,0 +1,159 @@
+package adapterSql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"gilab.com/estate-agency-api/internal/entity"
+	"github.com/go-sql-driver/mysql"
+)
+
+const (
+	contextTimeGetAllOffice = 2
+	contextTimeGetOneOffice = 1
+	contextTimeCreateOffice = 1
+	contextTimeDeleteOffice = 1
+)
+
+// mysqlErrRowIsReferenced is reported when a deleted row is still referenced by a foreign key.
+const mysqlErrRowIsReferenced = 1451
+
+const officeColumns = `id, name, address, created_at`
+
+func scanOffice(row scanner, office *entity.Office) error {
+	return row.Scan(&office.ID, &office.Name, &office.Address, &office.CreatedAt)
+}
+
+type officeAdapter struct {
+	db *sql.DB
+}
+
+func NewOfficeAdapter(db *sql.DB) *officeAdapter {
+	return &officeAdapter{db: db}
+}
+
+func (oa *officeAdapter) GetAll(ctx context.Context, page int, pageSize int) (offices []*entity.Office, err error) {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return
+	}
+	q := `SELECT ` + officeColumns + ` FROM offices WHERE agency_id=? ORDER BY id LIMIT ?,?`
+
+	context, close := context.WithTimeout(ctx, contextTimeGetAllOffice*time.Second)
+	defer close()
+
+	rows, err := oa.db.QueryContext(context, q, agencyID, page*pageSize, pageSize)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		office := &entity.Office{}
+		if err = scanOffice(rows, office); err != nil {
+			return
+		}
+		offices = append(offices, office)
+	}
+
+	return offices, rows.Err()
+}
+
+func (oa *officeAdapter) GetByID(ctx context.Context, id int) (office *entity.Office, err error) {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return
+	}
+	q := `SELECT ` + officeColumns + ` FROM offices WHERE id=? AND agency_id=?`
+
+	context, close := context.WithTimeout(ctx, contextTimeGetOneOffice*time.Second)
+	defer close()
+
+	office = &entity.Office{}
+	if err = scanOffice(oa.db.QueryRowContext(context, q, id, agencyID), office); err != nil {
+		return nil, err
+	}
+
+	return office, nil
+}
+
+func (oa *officeAdapter) Create(ctx context.Context, office *entity.Office, audit *entity.AuditEntry) (id int64, err error) {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return
+	}
+	q := `INSERT INTO offices (agency_id, name, address, created_at) VALUES (?, ?, ?, ?)`
+
+	context, close := context.WithTimeout(ctx, contextTimeCreateOffice*time.Second)
+	defer close()
+
+	tx, err := oa.db.BeginTx(context, nil)
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(context, q, agencyID, office.Name, office.Address, office.CreatedAt)
+	if err != nil {
+		return
+	}
+
+	id, err = result.LastInsertId()
+	if err != nil {
+		return
+	}
+	office.ID = int(id)
+
+	if err = insertAudit(context, tx, agencyID, office.ID, audit); err != nil {
+		return
+	}
+
+	return id, tx.Commit()
+}
+
+// Delete removes an office without teams; an office with teams is reported as entity.ErrOfficeNotEmpty.
+func (oa *officeAdapter) Delete(ctx context.Context, id int, audit *entity.AuditEntry) error {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return err
+	}
+	q := `DELETE FROM offices WHERE id=? AND agency_id=?`
+
+	context, close := context.WithTimeout(ctx, contextTimeDeleteOffice*time.Second)
+	defer close()
+
+	tx, err := oa.db.BeginTx(context, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(context, q, id, agencyID)
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrRowIsReferenced {
+		return entity.ErrOfficeNotEmpty
+	}
+	if err != nil {
+		return err
+	}
+
+	aff, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if aff == 0 {
+		return sql.ErrNoRows
+	}
+
+	if err = insertAudit(context, tx, agencyID, id, audit); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,204 @@
+package adapterSql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"gilab.com/estate-agency-api/internal/entity"
+)
+
+const (
+	contextTimePendingOutbox    = 2
+	contextTimePublishedOutbox  = 1
+	contextTimeDeliveriesOutbox = 2
+	contextTimeCleanOutbox      = 10
+)
+
+// insertEvents writes the events of the agency to the outbox inside the transaction of the change they describe.
+// Events without an aggregate ID get aggregateID, which lets creations reference the freshly inserted row.
+func insertEvents(ctx context.Context, tx *sql.Tx, agencyID int, aggregateID int, events []*entity.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO outbox_events (agency_id, event_type, aggregate_type, aggregate_id, payload, occurred_at) VALUES (?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, event := range events {
+		if event.AggregateID == 0 {
+			event.AggregateID = aggregateID
+		}
+		if event.Payload == nil {
+			if event.Payload, err = json.Marshal(event.Data); err != nil {
+				return err
+			}
+		}
+
+		result, err := stmt.ExecContext(ctx, agencyID, event.Type, event.AggregateType, event.AggregateID, []byte(event.Payload), event.OccurredAt)
+		if err != nil {
+			return err
+		}
+		if event.ID, err = result.LastInsertId(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type outboxAdapter struct {
+	db *sql.DB
+}
+
+func NewOutboxAdapter(db *sql.DB) *outboxAdapter {
+	return &outboxAdapter{db: db}
+}
+
+// Pending returns the oldest unpublished events of the agency written after the event afterID, in the order they
+// were written, together with their deliveries.
+func (oa *outboxAdapter) Pending(ctx context.Context, afterID int64, limit int) (events []*entity.Event, err error) {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return
+	}
+	q := `SELECT id, event_type, aggregate_type, aggregate_id, payload, occurred_at FROM outbox_events WHERE agency_id=? AND published_at IS NULL AND id>? ORDER BY id LIMIT ?`
+
+	context, close := context.WithTimeout(ctx, contextTimePendingOutbox*time.Second)
+	defer close()
+
+	rows, err := oa.db.QueryContext(context, q, agencyID, afterID, limit)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	byID := make(map[int64]*entity.Event)
+	args := []any{agencyID}
+	for rows.Next() {
+		event := &entity.Event{Deliveries: make(map[string]*entity.OutboxDelivery)}
+		var payload []byte
+		if err = rows.Scan(&event.ID, &event.Type, &event.AggregateType, &event.AggregateID, &payload, &event.OccurredAt); err != nil {
+			return
+		}
+		event.Payload = payload
+		events = append(events, event)
+		byID[event.ID] = event
+		args = append(args, event.ID)
+	}
+	if err = rows.Err(); err != nil || len(events) == 0 {
+		return
+	}
+	rows.Close()
+
+	q = `SELECT d.event_id, d.subscriber, d.status, d.attempts, d.last_error FROM outbox_deliveries d
+		JOIN outbox_events e ON e.id=d.event_id WHERE e.agency_id=? AND d.event_id IN (?` + strings.Repeat(", ?", len(events)-1) + `)`
+
+	deliveries, err := oa.db.QueryContext(context, q, args...)
+	if err != nil {
+		return
+	}
+	defer deliveries.Close()
+
+	for deliveries.Next() {
+		delivery := &entity.OutboxDelivery{}
+		if err = deliveries.Scan(&delivery.EventID, &delivery.Subscriber, &delivery.Status, &delivery.Attempts, &delivery.LastError); err != nil {
+			return
+		}
+		byID[delivery.EventID].Deliveries[delivery.Subscriber] = delivery
+	}
+
+	return events, deliveries.Err()
+}
+
+// SaveDeliveries stores the progress of events of the agency through their subscribers.
+func (oa *outboxAdapter) SaveDeliveries(ctx context.Context, deliveries []*entity.OutboxDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return err
+	}
+	q := `INSERT INTO outbox_deliveries (event_id, subscriber, status, attempts, last_error, updated_at)
+		SELECT id, ?, ?, ?, ?, ? FROM outbox_events WHERE id=? AND agency_id=?
+		ON DUPLICATE KEY UPDATE status=VALUES(status), attempts=VALUES(attempts), last_error=VALUES(last_error), updated_at=VALUES(updated_at)`
+
+	context, close := context.WithTimeout(ctx, contextTimeDeliveriesOutbox*time.Second)
+	defer close()
+
+	tx, err := oa.db.BeginTx(context, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(context, q)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now().UTC()
+	for _, delivery := range deliveries {
+		if _, err = stmt.ExecContext(context, delivery.Subscriber, delivery.Status, delivery.Attempts, delivery.LastError, now, delivery.EventID, agencyID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (oa *outboxAdapter) MarkPublished(ctx context.Context, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return err
+	}
+	q := `UPDATE outbox_events SET published_at=? WHERE agency_id=? AND id IN (?` + strings.Repeat(", ?", len(ids)-1) + `)`
+
+	context, close := context.WithTimeout(ctx, contextTimePublishedOutbox*time.Second)
+	defer close()
+
+	args := make([]any, 0, len(ids)+2)
+	args = append(args, time.Now().UTC(), agencyID)
+	for _, id := range ids {
+		args = append(args, id)
+	}
+
+	_, err = oa.db.ExecContext(context, q, args...)
+
+	return err
+}
+
+// Clean removes events of the agency published before the given time. Events with a dead delivery are kept
+// for inspection.
+func (oa *outboxAdapter) Clean(ctx context.Context, before time.Time) (aff int64, err error) {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return
+	}
+	q := `DELETE FROM outbox_events WHERE agency_id=? AND published_at IS NOT NULL AND published_at<?
+		AND NOT EXISTS (SELECT 1 FROM outbox_deliveries d WHERE d.event_id=outbox_events.id AND d.status=?)`
+
+	context, close := context.WithTimeout(ctx, contextTimeCleanOutbox*time.Second)
+	defer close()
+
+	result, err := oa.db.ExecContext(context, q, agencyID, before, entity.OutboxDeliveryDead)
+	if err != nil {
+		return
+	}
+
+	return result.RowsAffected()
+}
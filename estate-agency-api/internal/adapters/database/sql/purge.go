@@ -0,0 +1,54 @@
+package adapterSql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// purge hard-deletes the rows of the agency in table that were soft-deleted before the given time and returns their ids.
+// Rows still referenced by others, such as apartments with leases, are kept and left out of the ids.
+func purge(ctx context.Context, db *sql.DB, table string, agencyID int, before time.Time) (ids []int, err error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `SELECT id FROM `+table+` WHERE agency_id=? AND deleted_at IS NOT NULL AND deleted_at<? FOR UPDATE`, agencyID, before)
+	if err != nil {
+		return
+	}
+
+	for rows.Next() {
+		var id int
+		if err = rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Rows are deleted one by one, so that a failed foreign key check only rolls back the statement of its row.
+	var purged []int
+	for _, id := range ids {
+		_, err = tx.ExecContext(ctx, `DELETE FROM `+table+` WHERE id=?`, id)
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrRowIsReferenced {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		purged = append(purged, id)
+	}
+
+	return purged, tx.Commit()
+}
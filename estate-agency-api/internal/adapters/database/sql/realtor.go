@@ -9,46 +9,54 @@ import (
 )
 
 const (
-	contextTimeGetAllRealtor = 2
-	contextTimeGetOneRealtor = 1
-	contextTimeCreateRealtor = 1
-	contextTimeUpdateRealtor = 1
-	contextTimeDeleteRealtor = 1
+	contextTimeGetAllRealtor  = 2
+	contextTimeGetOneRealtor  = 1
+	contextTimeCreateRealtor  = 1
+	contextTimeUpdateRealtor  = 1
+	contextTimeDeleteRealtor  = 1
+	contextTimeEachRealtor    = 1
+	contextTimeRestoreRealtor = 1
+	contextTimePurgeRealtor   = 10
 )
 
+const realtorColumns = `id, first_name, last_name, phone, email, rating, experience, version, id_team, deleted_at`
+
+func scanRealtor(row scanner, realtor *entity.Realtor) error {
+	return row.Scan(&realtor.ID, &realtor.FirstName, &realtor.LastName, &realtor.Phone, &realtor.Email, &realtor.Rating, &realtor.Experience, &realtor.Version, &realtor.IDTeam, &realtor.DeletedAt)
+}
+
 type realtorAdapter struct {
-	db      *sql.DB
-	context context.Context
+	db *sql.DB
 }
 
 func NewRealtorAdapter(db *sql.DB) *realtorAdapter {
-	return &realtorAdapter{db: db, context: context.Background()}
+	return &realtorAdapter{db: db}
 }
 
-func (rs *realtorAdapter) GetAll(page int, pageSize int) (realtors []*entity.Realtor, err error) {
+func (rs *realtorAdapter) GetAll(ctx context.Context, page int, pageSize int) (realtors []*entity.Realtor, err error) {
 
-	q := `SELECT * FROM realtors LIMIT ?,?`
-
-	context, close := context.WithTimeout(rs.context, contextTimeGetAllRealtor*time.Second)
-	defer close()
-
-	if err = rs.db.PingContext(context); err != nil {
+	agencyID, err := tenant(ctx)
+	if err != nil {
 		return
 	}
+	q := `SELECT ` + realtorColumns + ` FROM realtors WHERE agency_id=? AND deleted_at IS NULL LIMIT ?,?`
+
+	context, close := context.WithTimeout(ctx, contextTimeGetAllRealtor*time.Second)
+	defer close()
 
 	stmt, err := rs.db.PrepareContext(context, q)
 	if err != nil {
 		return
 	}
 
-	rows, err := stmt.QueryContext(context, page*pageSize, pageSize)
+	rows, err := stmt.QueryContext(context, agencyID, page*pageSize, pageSize)
 	if err != nil {
 		return
 	}
 
 	for rows.Next() {
 		realtor := &entity.Realtor{}
-		err = rows.Scan(&realtor.ID, &realtor.FirstName, &realtor.LastName, &realtor.Phone, &realtor.Email, &realtor.Rating, &realtor.Experience)
+		err = scanRealtor(rows, realtor)
 		if err != nil {
 			return
 		}
@@ -60,16 +68,54 @@ func (rs *realtorAdapter) GetAll(page int, pageSize int) (realtors []*entity.Rea
 	return realtors, nil
 }
 
-func (rs *realtorAdapter) GetByID(id int) (realtor *entity.Realtor, err error) {
+func (rs *realtorAdapter) Each(ctx context.Context, fn func(realtor *entity.Realtor) error) error {
 
-	q := `SELECT * FROM realtors WHERE id=?`
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return err
+	}
+	q := `SELECT ` + realtorColumns + ` FROM realtors WHERE agency_id=? AND deleted_at IS NULL ORDER BY id`
 
-	context, close := context.WithTimeout(rs.context, contextTimeGetOneRealtor*time.Second)
-	defer close()
+	// Only the preparation is bounded: an export streams for as long as the client reads it and ends with the
+	// request when the client goes away.
+	context, close := context.WithTimeout(ctx, contextTimeEachRealtor*time.Second)
+	stmt, err := rs.db.PrepareContext(context, q)
+	close()
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
 
-	if err = rs.db.PingContext(context); err != nil {
+	rows, err := stmt.QueryContext(ctx, agencyID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		realtor := &entity.Realtor{}
+		err = scanRealtor(rows, realtor)
+		if err != nil {
+			return err
+		}
+		if err = fn(realtor); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func (rs *realtorAdapter) GetByID(ctx context.Context, id int) (realtor *entity.Realtor, err error) {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
 		return
 	}
+	q := `SELECT ` + realtorColumns + ` FROM realtors WHERE id=? AND agency_id=? AND deleted_at IS NULL`
+
+	context, close := context.WithTimeout(ctx, contextTimeGetOneRealtor*time.Second)
+	defer close()
 
 	realtor = &entity.Realtor{}
 	stmt, err := rs.db.PrepareContext(context, q)
@@ -77,82 +123,220 @@ func (rs *realtorAdapter) GetByID(id int) (realtor *entity.Realtor, err error) {
 		return
 	}
 
-	if err = stmt.QueryRowContext(context, id).Scan(&realtor.ID, &realtor.FirstName, &realtor.LastName, &realtor.Phone, &realtor.Email, &realtor.Rating, &realtor.Experience); err != nil {
+	if err = scanRealtor(stmt.QueryRowContext(context, id, agencyID), realtor); err != nil {
 		return
 	}
 
 	return realtor, nil
 }
 
-func (rs *realtorAdapter) Create(realtor *entity.Realtor) (id int64, err error) {
+func (rs *realtorAdapter) Create(ctx context.Context, realtor *entity.Realtor, events []*entity.Event, audit *entity.AuditEntry) (id int64, err error) {
 
-	q := `INSERT INTO realtors (first_name, last_name, phone, email, rating, experience) VALUES (?, ?, ?, ?, ?, ?)`
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return
+	}
+	q := `INSERT INTO realtors (agency_id, first_name, last_name, phone, email, rating, experience) VALUES (?, ?, ?, ?, ?, ?, ?)`
 
-	context, close := context.WithTimeout(rs.context, contextTimeCreateRealtor*time.Second)
+	context, close := context.WithTimeout(ctx, contextTimeCreateRealtor*time.Second)
 	defer close()
 
-	if err = rs.db.PingContext(context); err != nil {
+	tx, err := rs.db.BeginTx(context, nil)
+	if err != nil {
 		return
 	}
+	defer tx.Rollback()
 
-	stmt, err := rs.db.PrepareContext(context, q)
+	row, err := tx.ExecContext(context, q, agencyID, realtor.FirstName, realtor.LastName, realtor.Phone, realtor.Email, realtor.Rating, realtor.Experience)
 	if err != nil {
 		return
 	}
 
-	row, err := stmt.ExecContext(context, realtor.FirstName, realtor.LastName, realtor.Phone, realtor.Email, realtor.Rating, realtor.Experience)
+	id, err = row.LastInsertId()
 	if err != nil {
 		return
 	}
+	realtor.ID = int(id)
 
-	id, err = row.LastInsertId()
+	if err = insertEvents(context, tx, agencyID, realtor.ID, events); err != nil {
+		return
+	}
+	if err = insertAudit(context, tx, agencyID, realtor.ID, audit); err != nil {
+		return
+	}
 
-	return id, err
+	return id, tx.Commit()
 }
 
-func (rs *realtorAdapter) Update(realtor *entity.Realtor) (aff int64, err error) {
+func (rs *realtorAdapter) UpdateFields(ctx context.Context, id int, version int, fields map[string]any, events []*entity.Event, audit *entity.AuditEntry) (aff int64, err error) {
 
-	q := `UPDATE realtors SET first_name=?, last_name=?, phone=?, email=?, rating=?, experience=? WHERE id=?`
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return
+	}
+	set, args, err := setClause(fields, realtorUpdatableColumns)
+	if err != nil {
+		return
+	}
+	q := `UPDATE realtors SET ` + set + `, version=version+1 WHERE id=? AND agency_id=? AND version=? AND deleted_at IS NULL`
 
-	context, close := context.WithTimeout(rs.context, contextTimeUpdateRealtor*time.Second)
+	context, close := context.WithTimeout(ctx, contextTimeUpdateRealtor*time.Second)
 	defer close()
 
-	if err = rs.db.PingContext(context); err != nil {
+	tx, err := rs.db.BeginTx(context, nil)
+	if err != nil {
 		return
 	}
+	defer tx.Rollback()
 
-	stmt, err := rs.db.PrepareContext(context, q)
+	result, err := tx.ExecContext(context, q, append(args, id, agencyID, version)...)
 	if err != nil {
 		return
 	}
 
-	result, err := stmt.ExecContext(context, realtor.FirstName, realtor.LastName, realtor.Phone, realtor.Email, realtor.Rating, realtor.Experience, realtor.ID)
+	aff, err = result.RowsAffected()
 	if err != nil {
 		return
 	}
+	if aff == 0 {
+		return aff, entity.ErrVersionConflict
+	}
 
-	aff, err = result.RowsAffected()
+	if err = insertEvents(context, tx, agencyID, id, events); err != nil {
+		return
+	}
+	if err = insertAudit(context, tx, agencyID, id, audit); err != nil {
+		return
+	}
 
-	return aff, err
+	return aff, tx.Commit()
 }
 
-func (rs *realtorAdapter) Delete(id int) error {
+func (rs *realtorAdapter) Delete(ctx context.Context, id int, version int, events []*entity.Event, audit *entity.AuditEntry) error {
 
-	q := `DELETE FROM realtors WHERE id=?`
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return err
+	}
+	q := `UPDATE realtors SET deleted_at=?, version=version+1 WHERE id=? AND agency_id=? AND version=? AND deleted_at IS NULL`
 
-	context, close := context.WithTimeout(rs.context, contextTimeDeleteRealtor*time.Second)
+	context, close := context.WithTimeout(ctx, contextTimeDeleteRealtor*time.Second)
 	defer close()
 
-	if err := rs.db.PingContext(context); err != nil {
+	tx, err := rs.db.BeginTx(context, nil)
+	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	stmt, err := rs.db.PrepareContext(context, q)
+	result, err := tx.ExecContext(context, q, time.Now(), id, agencyID, version)
+	if err != nil {
+		return err
+	}
+
+	aff, err := result.RowsAffected()
 	if err != nil {
 		return err
 	}
+	if aff == 0 {
+		return entity.ErrVersionConflict
+	}
+
+	if err = insertEvents(context, tx, agencyID, id, events); err != nil {
+		return err
+	}
+	if err = insertAudit(context, tx, agencyID, id, audit); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Restore brings the realtor back and records the restored state as the After of the audit entry.
+func (rs *realtorAdapter) Restore(ctx context.Context, id int, events []*entity.Event, audit *entity.AuditEntry) error {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return err
+	}
+	q := `UPDATE realtors SET deleted_at=NULL, version=version+1 WHERE id=? AND agency_id=? AND deleted_at IS NOT NULL`
+
+	context, close := context.WithTimeout(ctx, contextTimeRestoreRealtor*time.Second)
+	defer close()
+
+	tx, err := rs.db.BeginTx(context, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(context, q, id, agencyID)
+	if err != nil {
+		return err
+	}
+
+	aff, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if aff == 0 {
+		return sql.ErrNoRows
+	}
+
+	if err = insertEvents(context, tx, agencyID, id, events); err != nil {
+		return err
+	}
+	if audit != nil {
+		restored := &entity.Realtor{}
+		if err = scanRealtor(tx.QueryRowContext(context, `SELECT `+realtorColumns+` FROM realtors WHERE id=? AND agency_id=?`, id, agencyID), restored); err != nil {
+			return err
+		}
+		audit.After = restored
+	}
+	if err = insertAudit(context, tx, agencyID, id, audit); err != nil {
+		return err
+	}
 
-	_, err = stmt.ExecContext(context, id)
+	return tx.Commit()
+}
+
+func (rs *realtorAdapter) GetDeleted(ctx context.Context, page int, pageSize int) (realtors []*entity.Realtor, err error) {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return
+	}
+	q := `SELECT ` + realtorColumns + ` FROM realtors WHERE agency_id=? AND deleted_at IS NOT NULL ORDER BY deleted_at DESC LIMIT ?,?`
+
+	context, close := context.WithTimeout(ctx, contextTimeGetAllRealtor*time.Second)
+	defer close()
+
+	rows, err := rs.db.QueryContext(context, q, agencyID, page*pageSize, pageSize)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		realtor := &entity.Realtor{}
+		if err = scanRealtor(rows, realtor); err != nil {
+			return
+		}
+		realtors = append(realtors, realtor)
+	}
+
+	return realtors, rows.Err()
+}
+
+func (rs *realtorAdapter) Purge(ctx context.Context, before time.Time) (ids []int, err error) {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return
+	}
+
+	context, close := context.WithTimeout(ctx, contextTimePurgeRealtor*time.Second)
+	defer close()
 
-	return err
+	return purge(context, rs.db, "realtors", agencyID, before)
 }
@@ -0,0 +1,328 @@
+package adapterSql
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"gilab.com/estate-agency-api/internal/entity"
+)
+
+const (
+	contextTimeGetCharges    = 1
+	contextTimeGetPayments   = 1
+	contextTimeRecordPayment = 2
+	contextTimeLeaseBalance  = 2
+	contextTimeOverdueLeases = 3
+	contextTimeMarkOverdue   = 5
+	contextTimeDueReminders  = 2
+	contextTimeMarkReminded  = 1
+)
+
+// rentChargeColumns are qualified by the alias c of chargesOfAgency.
+const rentChargeColumns = `c.id, c.id_lease, c.period_start, c.due_date, c.amount, c.paid, c.status, c.reminders, c.reminded_at`
+
+// chargesOfAgency joins rent charges c with the lease l and its apartment a, whose agency_id scopes them.
+const chargesOfAgency = `rent_charges c JOIN leases l ON l.id=c.id_lease JOIN apartments a ON a.id=l.id_apartment`
+
+func scanRentCharge(row scanner, charge *entity.RentCharge) error {
+	return row.Scan(&charge.ID, &charge.IDLease, &charge.PeriodStart, &charge.DueDate, &charge.Amount, &charge.Paid, &charge.Status, &charge.Reminders, &charge.RemindedAt)
+}
+
+// leaseBalanceQuery selects the balance of leases l joined with their apartments a. Its placeholders are today's
+// date for charged, overdue amount and oldest due, in that order, followed by the arguments of the appended condition.
+const leaseBalanceQuery = `SELECT l.id, l.id_client, l.id_apartment,
+		COALESCE((SELECT SUM(c.amount) FROM rent_charges c WHERE c.id_lease=l.id AND c.due_date<=?), 0),
+		COALESCE((SELECT SUM(p.amount) FROM rent_payments p WHERE p.id_lease=l.id), 0),
+		COALESCE((SELECT SUM(c.amount-c.paid) FROM rent_charges c WHERE c.id_lease=l.id AND c.due_date<? AND c.paid<c.amount), 0),
+		(SELECT MIN(c.due_date) FROM rent_charges c WHERE c.id_lease=l.id AND c.due_date<? AND c.paid<c.amount) AS oldest_due
+	FROM leases l JOIN apartments a ON a.id=l.id_apartment`
+
+func scanLeaseBalance(row scanner, balance *entity.LeaseBalance) error {
+	var oldest sql.NullTime
+	if err := row.Scan(&balance.IDLease, &balance.IDClient, &balance.IDApartment, &balance.Charged, &balance.Paid, &balance.OverdueAmount, &oldest); err != nil {
+		return err
+	}
+	if oldest.Valid {
+		d := entity.NewDate(oldest.Time)
+		balance.OldestDue = &d
+	}
+	return nil
+}
+
+type rentAdapter struct {
+	db *sql.DB
+}
+
+func NewRentAdapter(db *sql.DB) *rentAdapter {
+	return &rentAdapter{db: db}
+}
+
+// insertCharges adds the charges inside the transaction of their lease, skipping periods the lease already has
+// and leases of other agencies.
+func insertCharges(ctx context.Context, tx *sql.Tx, agencyID int, charges []*entity.RentCharge) error {
+	if len(charges) == 0 {
+		return nil
+	}
+
+	values := make([]string, len(charges))
+	args := make([]any, 0, len(charges)*7)
+	for i, c := range charges {
+		values[i] = "SELECT l.id, ?, ?, ?, ?, ? FROM leases l JOIN apartments a ON a.id=l.id_apartment WHERE l.id=? AND a.agency_id=?"
+		args = append(args, c.PeriodStart, c.DueDate, c.Amount, c.Paid, c.Status, c.IDLease, agencyID)
+	}
+	q := `INSERT IGNORE INTO rent_charges (id_lease, period_start, due_date, amount, paid, status) ` + strings.Join(values, " UNION ALL ")
+
+	_, err := tx.ExecContext(ctx, q, args...)
+
+	return err
+}
+
+// cancelCharges removes unpaid charges of periods starting on or after from inside the transaction of their lease.
+func cancelCharges(ctx context.Context, tx *sql.Tx, agencyID int, leaseID int, from entity.Date) error {
+	q := `DELETE c FROM ` + chargesOfAgency + ` WHERE c.id_lease=? AND a.agency_id=? AND c.period_start>=? AND c.paid=0`
+
+	_, err := tx.ExecContext(ctx, q, leaseID, agencyID, from)
+
+	return err
+}
+
+func (rs *rentAdapter) GetCharges(ctx context.Context, leaseID int) (charges []*entity.RentCharge, err error) {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return
+	}
+	q := `SELECT ` + rentChargeColumns + ` FROM ` + chargesOfAgency + ` WHERE c.id_lease=? AND a.agency_id=? ORDER BY c.due_date, c.id`
+
+	context, close := context.WithTimeout(ctx, contextTimeGetCharges*time.Second)
+	defer close()
+
+	return rs.queryCharges(context, q, leaseID, agencyID)
+}
+
+func (rs *rentAdapter) GetPayments(ctx context.Context, leaseID int) (payments []*entity.RentPayment, err error) {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return
+	}
+	q := `SELECT p.id, p.id_lease, p.amount, p.paid_on, p.note, p.created_at FROM rent_payments p JOIN leases l ON l.id=p.id_lease JOIN apartments a ON a.id=l.id_apartment
+		WHERE p.id_lease=? AND a.agency_id=? ORDER BY p.paid_on, p.id`
+
+	context, close := context.WithTimeout(ctx, contextTimeGetPayments*time.Second)
+	defer close()
+
+	rows, err := rs.db.QueryContext(context, q, leaseID, agencyID)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		p := &entity.RentPayment{}
+		if err = rows.Scan(&p.ID, &p.IDLease, &p.Amount, &p.PaidOn, &p.Note, &p.CreatedAt); err != nil {
+			return
+		}
+		payments = append(payments, p)
+	}
+
+	return payments, rows.Err()
+}
+
+// RecordPayment stores the payment and allocates it to the lease's unpaid charges, oldest due first.
+// Whatever is left over stays on the lease as a prepayment. A lease of another agency is reported as sql.ErrNoRows.
+func (rs *rentAdapter) RecordPayment(ctx context.Context, payment *entity.RentPayment, audit *entity.AuditEntry) (id int64, err error) {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return
+	}
+	q := `INSERT INTO rent_payments (id_lease, amount, paid_on, note, created_at)
+		SELECT l.id, ?, ?, ?, ? FROM leases l JOIN apartments a ON a.id=l.id_apartment WHERE l.id=? AND a.agency_id=?`
+
+	context, close := context.WithTimeout(ctx, contextTimeRecordPayment*time.Second)
+	defer close()
+
+	tx, err := rs.db.BeginTx(context, nil)
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	row, err := tx.ExecContext(context, q, payment.Amount, payment.PaidOn, payment.Note, payment.CreatedAt, payment.IDLease, agencyID)
+	if err != nil {
+		return
+	}
+	aff, err := row.RowsAffected()
+	if err != nil {
+		return
+	}
+	if aff == 0 {
+		return 0, sql.ErrNoRows
+	}
+	id, err = row.LastInsertId()
+	if err != nil {
+		return
+	}
+	payment.ID = int(id)
+
+	rows, err := tx.QueryContext(context, `SELECT id, amount, paid FROM rent_charges WHERE id_lease=? AND paid<amount ORDER BY due_date, id FOR UPDATE`, payment.IDLease)
+	if err != nil {
+		return
+	}
+
+	type allocation struct{ id, paid int }
+	var (
+		allocations []allocation
+		left        = payment.Amount
+	)
+	for rows.Next() && left > 0 {
+		var chargeID, amount, paid int
+		if err = rows.Scan(&chargeID, &amount, &paid); err != nil {
+			rows.Close()
+			return
+		}
+		add := amount - paid
+		if add > left {
+			add = left
+		}
+		left -= add
+		allocations = append(allocations, allocation{id: chargeID, paid: paid + add})
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return
+	}
+
+	for _, a := range allocations {
+		_, err = tx.ExecContext(context, `UPDATE rent_charges SET paid=?, status=IF(?>=amount, ?, status) WHERE id=?`, a.paid, a.paid, entity.RentChargePaid, a.id)
+		if err != nil {
+			return
+		}
+	}
+
+	if err = insertAudit(context, tx, agencyID, payment.ID, audit); err != nil {
+		return
+	}
+
+	return id, tx.Commit()
+}
+
+func (rs *rentAdapter) Balance(ctx context.Context, leaseID int, today entity.Date) (balance *entity.LeaseBalance, err error) {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return
+	}
+	q := leaseBalanceQuery + ` WHERE l.id=? AND a.agency_id=?`
+
+	context, close := context.WithTimeout(ctx, contextTimeLeaseBalance*time.Second)
+	defer close()
+
+	balance = &entity.LeaseBalance{}
+	if err = scanLeaseBalance(rs.db.QueryRowContext(context, q, today, today, today, leaseID, agencyID), balance); err != nil {
+		return nil, err
+	}
+
+	return balance, nil
+}
+
+// Overdue returns the balances of leases with unpaid charges due before today, longest overdue first.
+func (rs *rentAdapter) Overdue(ctx context.Context, today entity.Date, page int, pageSize int) (balances []*entity.LeaseBalance, err error) {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return
+	}
+	q := leaseBalanceQuery + ` WHERE a.agency_id=? AND EXISTS (SELECT 1 FROM rent_charges c WHERE c.id_lease=l.id AND c.due_date<? AND c.paid<c.amount) ORDER BY oldest_due, l.id LIMIT ?,?`
+
+	context, close := context.WithTimeout(ctx, contextTimeOverdueLeases*time.Second)
+	defer close()
+
+	rows, err := rs.db.QueryContext(context, q, today, today, today, agencyID, today, page*pageSize, pageSize)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		balance := &entity.LeaseBalance{}
+		if err = scanLeaseBalance(rows, balance); err != nil {
+			return
+		}
+		balances = append(balances, balance)
+	}
+
+	return balances, rows.Err()
+}
+
+// MarkOverdue flags pending charges of the agency that were due before today and are not fully paid.
+func (rs *rentAdapter) MarkOverdue(ctx context.Context, today entity.Date) (int64, error) {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return 0, err
+	}
+	q := `UPDATE ` + chargesOfAgency + ` SET c.status=? WHERE a.agency_id=? AND c.status=? AND c.due_date<? AND c.paid<c.amount`
+
+	context, close := context.WithTimeout(ctx, contextTimeMarkOverdue*time.Second)
+	defer close()
+
+	result, err := rs.db.ExecContext(context, q, entity.RentChargeOverdue, agencyID, entity.RentChargePending, today)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// DueReminders returns overdue charges of the agency that were never reminded of or were last reminded of before the given time.
+func (rs *rentAdapter) DueReminders(ctx context.Context, before time.Time, limit int) (charges []*entity.RentCharge, err error) {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return
+	}
+	q := `SELECT ` + rentChargeColumns + ` FROM ` + chargesOfAgency + ` WHERE a.agency_id=? AND c.status=? AND (c.reminded_at IS NULL OR c.reminded_at<?) ORDER BY c.due_date, c.id LIMIT ?`
+
+	context, close := context.WithTimeout(ctx, contextTimeDueReminders*time.Second)
+	defer close()
+
+	return rs.queryCharges(context, q, agencyID, entity.RentChargeOverdue, before, limit)
+}
+
+func (rs *rentAdapter) MarkReminded(ctx context.Context, id int, at time.Time) error {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return err
+	}
+	q := `UPDATE ` + chargesOfAgency + ` SET c.reminders=c.reminders+1, c.reminded_at=? WHERE c.id=? AND a.agency_id=?`
+
+	context, close := context.WithTimeout(ctx, contextTimeMarkReminded*time.Second)
+	defer close()
+
+	_, err = rs.db.ExecContext(context, q, at, id, agencyID)
+
+	return err
+}
+
+func (rs *rentAdapter) queryCharges(ctx context.Context, q string, args ...any) (charges []*entity.RentCharge, err error) {
+	rows, err := rs.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		charge := &entity.RentCharge{}
+		if err = scanRentCharge(rows, charge); err != nil {
+			return
+		}
+		charges = append(charges, charge)
+	}
+
+	return charges, rows.Err()
+}
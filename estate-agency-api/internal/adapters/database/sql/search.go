@@ -0,0 +1,159 @@
+package adapterSql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"gilab.com/estate-agency-api/internal/entity"
+)
+
+const (
+	contextTimeGetSearches   = 2
+	contextTimeCreateSearch  = 1
+	contextTimeDeleteSearch  = 1
+	contextTimeMatchSearches = 5
+)
+
+// searchColumns are qualified by the alias s, since saved searches are always joined with their client's agency.
+const searchColumns = `s.id, s.id_client, s.name, s.city, s.min_price, s.max_price, s.rooms, s.min_square, s.max_square, s.created_at`
+
+func scanSearch(row scanner, search *entity.SavedSearch) error {
+	return row.Scan(&search.ID, &search.IDClient, &search.Name, &search.City, &search.MinPrice, &search.MaxPrice, &search.Rooms, &search.MinSquare, &search.MaxSquare, &search.CreatedAt)
+}
+
+type searchAdapter struct {
+	db *sql.DB
+}
+
+func NewSearchAdapter(db *sql.DB) *searchAdapter {
+	return &searchAdapter{db: db}
+}
+
+func (ss *searchAdapter) GetByClient(ctx context.Context, clientID int) (searches []*entity.SavedSearch, err error) {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return
+	}
+	q := `SELECT ` + searchColumns + ` FROM saved_searches s JOIN clients c ON c.id=s.id_client WHERE s.id_client=? AND c.agency_id=? ORDER BY s.id`
+
+	context, close := context.WithTimeout(ctx, contextTimeGetSearches*time.Second)
+	defer close()
+
+	return ss.query(context, q, clientID, agencyID)
+}
+
+// Create stores the search of a client of the agency; an unknown client is reported as sql.ErrNoRows.
+func (ss *searchAdapter) Create(ctx context.Context, search *entity.SavedSearch, audit *entity.AuditEntry) (id int64, err error) {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return
+	}
+	q := `INSERT INTO saved_searches (id_client, name, city, min_price, max_price, rooms, min_square, max_square, created_at) SELECT id, ?, ?, ?, ?, ?, ?, ?, ? FROM clients WHERE id=? AND agency_id=?`
+
+	context, close := context.WithTimeout(ctx, contextTimeCreateSearch*time.Second)
+	defer close()
+
+	tx, err := ss.db.BeginTx(context, nil)
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(context, q, search.Name, search.City, search.MinPrice, search.MaxPrice, search.Rooms, search.MinSquare, search.MaxSquare, search.CreatedAt, search.IDClient, agencyID)
+	if err != nil {
+		return
+	}
+
+	aff, err := result.RowsAffected()
+	if err != nil {
+		return
+	}
+	if aff == 0 {
+		return 0, sql.ErrNoRows
+	}
+
+	id, err = result.LastInsertId()
+	if err != nil {
+		return
+	}
+	search.ID = int(id)
+
+	if err = insertAudit(context, tx, agencyID, search.ID, audit); err != nil {
+		return
+	}
+
+	return id, tx.Commit()
+}
+
+func (ss *searchAdapter) Delete(ctx context.Context, clientID int, id int, audit *entity.AuditEntry) error {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return err
+	}
+	q := `DELETE s FROM saved_searches s JOIN clients c ON c.id=s.id_client WHERE s.id=? AND s.id_client=? AND c.agency_id=?`
+
+	context, close := context.WithTimeout(ctx, contextTimeDeleteSearch*time.Second)
+	defer close()
+
+	tx, err := ss.db.BeginTx(context, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(context, q, id, clientID, agencyID)
+	if err != nil {
+		return err
+	}
+
+	aff, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if aff == 0 {
+		return sql.ErrNoRows
+	}
+
+	if err = insertAudit(context, tx, agencyID, id, audit); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Matching returns the saved searches of the agency's clients whose bounds include the apartment. Zero bounds match anything.
+func (ss *searchAdapter) Matching(ctx context.Context, apartment *entity.Apartment) (searches []*entity.SavedSearch, err error) {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return
+	}
+	q := `SELECT ` + searchColumns + ` FROM saved_searches s JOIN clients c ON c.id=s.id_client WHERE c.agency_id=? AND (s.city='' OR s.city=?) AND (s.min_price=0 OR s.min_price<=?) AND (s.max_price=0 OR s.max_price>=?) AND (s.rooms=0 OR s.rooms=?) AND (s.min_square=0 OR s.min_square<=?) AND (s.max_square=0 OR s.max_square>=?)`
+
+	context, close := context.WithTimeout(ctx, contextTimeMatchSearches*time.Second)
+	defer close()
+
+	return ss.query(context, q, agencyID, apartment.City, apartment.Price, apartment.Price, apartment.Rooms, apartment.Square, apartment.Square)
+}
+
+func (ss *searchAdapter) query(ctx context.Context, q string, args ...any) (searches []*entity.SavedSearch, err error) {
+	rows, err := ss.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		search := &entity.SavedSearch{}
+		if err = scanSearch(rows, search); err != nil {
+			return
+		}
+		searches = append(searches, search)
+	}
+
+	return searches, rows.Err()
+}
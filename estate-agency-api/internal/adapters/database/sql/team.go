@@ -0,0 +1,300 @@
+package adapterSql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"gilab.com/estate-agency-api/internal/entity"
+)
+
+const (
+	contextTimeGetAllTeam     = 2
+	contextTimeGetOneTeam     = 1
+	contextTimeCreateTeam     = 1
+	contextTimeUpdateTeam     = 1
+	contextTimeDeleteTeam     = 1
+	contextTimeTeamMembers    = 2
+	contextTimeTeamStats      = 3
+	contextTimeSetRealtorTeam = 1
+)
+
+const teamColumns = `id, id_office, name, id_lead, created_at`
+
+func scanTeam(row scanner, team *entity.Team) error {
+	return row.Scan(&team.ID, &team.IDOffice, &team.Name, &team.IDLead, &team.CreatedAt)
+}
+
+// teamStatsQuery counts the listings of every member of a team and the leases signed for them.
+// Each lease joins a single apartment, so the rent is summed once per lease.
+const teamStatsQuery = `SELECT r.id,
+		COUNT(DISTINCT a.id),
+		COUNT(DISTINCT CASE WHEN a.status='published' THEN a.id END),
+		COUNT(DISTINCT CASE WHEN a.status='draft' THEN a.id END),
+		COUNT(DISTINCT CASE WHEN a.status='archived' THEN a.id END),
+		COUNT(DISTINCT l.id),
+		COUNT(DISTINCT CASE WHEN l.status='active' THEN l.id END),
+		COALESCE(SUM(CASE WHEN l.status='active' THEN l.monthly_rent END), 0)
+	FROM realtors r
+	LEFT JOIN apartments a ON a.id_realtor=r.id AND a.agency_id=r.agency_id AND a.deleted_at IS NULL
+	LEFT JOIN leases l ON l.id_apartment=a.id
+	WHERE r.id_team=? AND r.agency_id=? AND r.deleted_at IS NULL
+	GROUP BY r.id ORDER BY r.id`
+
+type teamAdapter struct {
+	db *sql.DB
+}
+
+func NewTeamAdapter(db *sql.DB) *teamAdapter {
+	return &teamAdapter{db: db}
+}
+
+func (ta *teamAdapter) GetAll(ctx context.Context, filter entity.TeamFilter, page int, pageSize int) (teams []*entity.Team, err error) {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return
+	}
+	q := `SELECT ` + teamColumns + ` FROM teams WHERE agency_id=?`
+	args := []any{agencyID}
+	if filter.IDOffice != 0 {
+		q += ` AND id_office=?`
+		args = append(args, filter.IDOffice)
+	}
+	q += ` ORDER BY id LIMIT ?,?`
+
+	context, close := context.WithTimeout(ctx, contextTimeGetAllTeam*time.Second)
+	defer close()
+
+	rows, err := ta.db.QueryContext(context, q, append(args, page*pageSize, pageSize)...)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		team := &entity.Team{}
+		if err = scanTeam(rows, team); err != nil {
+			return
+		}
+		teams = append(teams, team)
+	}
+
+	return teams, rows.Err()
+}
+
+func (ta *teamAdapter) GetByID(ctx context.Context, id int) (team *entity.Team, err error) {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return
+	}
+	q := `SELECT ` + teamColumns + ` FROM teams WHERE id=? AND agency_id=?`
+
+	context, close := context.WithTimeout(ctx, contextTimeGetOneTeam*time.Second)
+	defer close()
+
+	team = &entity.Team{}
+	if err = scanTeam(ta.db.QueryRowContext(context, q, id, agencyID), team); err != nil {
+		return nil, err
+	}
+
+	return team, nil
+}
+
+// Create stores the team in an office of the agency; an office of another agency is reported as sql.ErrNoRows.
+func (ta *teamAdapter) Create(ctx context.Context, team *entity.Team, audit *entity.AuditEntry) (id int64, err error) {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return
+	}
+	q := `INSERT INTO teams (agency_id, id_office, name, id_lead, created_at)
+		SELECT agency_id, id, ?, ?, ? FROM offices WHERE id=? AND agency_id=?`
+
+	context, close := context.WithTimeout(ctx, contextTimeCreateTeam*time.Second)
+	defer close()
+
+	tx, err := ta.db.BeginTx(context, nil)
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(context, q, team.Name, team.IDLead, team.CreatedAt, team.IDOffice, agencyID)
+	if err != nil {
+		return
+	}
+
+	aff, err := result.RowsAffected()
+	if err != nil {
+		return
+	}
+	if aff == 0 {
+		return 0, sql.ErrNoRows
+	}
+
+	id, err = result.LastInsertId()
+	if err != nil {
+		return
+	}
+	team.ID = int(id)
+
+	if err = insertAudit(context, tx, agencyID, team.ID, audit); err != nil {
+		return
+	}
+
+	return id, tx.Commit()
+}
+
+// Update replaces the office, the name and the lead of the team; the office must belong to the agency.
+func (ta *teamAdapter) Update(ctx context.Context, team *entity.Team, audit *entity.AuditEntry) error {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return err
+	}
+	q := `UPDATE teams t JOIN offices o ON o.id=? AND o.agency_id=t.agency_id
+		SET t.id_office=o.id, t.name=?, t.id_lead=? WHERE t.id=? AND t.agency_id=?`
+
+	context, close := context.WithTimeout(ctx, contextTimeUpdateTeam*time.Second)
+	defer close()
+
+	tx, err := ta.db.BeginTx(context, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(context, q, team.IDOffice, team.Name, team.IDLead, team.ID, agencyID); err != nil {
+		return err
+	}
+	if err = insertAudit(context, tx, agencyID, team.ID, audit); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Delete removes the team, its members stay in the agency without a team.
+func (ta *teamAdapter) Delete(ctx context.Context, id int, audit *entity.AuditEntry) error {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return err
+	}
+	q := `DELETE FROM teams WHERE id=? AND agency_id=?`
+
+	context, close := context.WithTimeout(ctx, contextTimeDeleteTeam*time.Second)
+	defer close()
+
+	tx, err := ta.db.BeginTx(context, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(context, q, id, agencyID)
+	if err != nil {
+		return err
+	}
+
+	aff, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if aff == 0 {
+		return sql.ErrNoRows
+	}
+
+	if err = insertAudit(context, tx, agencyID, id, audit); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (ta *teamAdapter) Members(ctx context.Context, teamID int) (realtors []*entity.Realtor, err error) {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return
+	}
+	q := `SELECT ` + realtorColumns + ` FROM realtors WHERE id_team=? AND agency_id=? AND deleted_at IS NULL ORDER BY id`
+
+	context, close := context.WithTimeout(ctx, contextTimeTeamMembers*time.Second)
+	defer close()
+
+	rows, err := ta.db.QueryContext(context, q, teamID, agencyID)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		realtor := &entity.Realtor{}
+		if err = scanRealtor(rows, realtor); err != nil {
+			return
+		}
+		realtors = append(realtors, realtor)
+	}
+
+	return realtors, rows.Err()
+}
+
+// SetRealtorTeam moves the realtor to the team of the agency, or out of any team when teamID is nil.
+func (ta *teamAdapter) SetRealtorTeam(ctx context.Context, realtorID int, teamID *int, audit *entity.AuditEntry) error {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return err
+	}
+	q := `UPDATE realtors r LEFT JOIN teams t ON t.id=? AND t.agency_id=r.agency_id
+		SET r.id_team=t.id, r.version=r.version+1 WHERE r.id=? AND r.agency_id=? AND r.deleted_at IS NULL AND (? IS NULL OR t.id IS NOT NULL)`
+
+	context, close := context.WithTimeout(ctx, contextTimeSetRealtorTeam*time.Second)
+	defer close()
+
+	tx, err := ta.db.BeginTx(context, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(context, q, teamID, realtorID, agencyID, teamID); err != nil {
+		return err
+	}
+	if err = insertAudit(context, tx, agencyID, realtorID, audit); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Stats returns the listing statistics of every member of the team.
+func (ta *teamAdapter) Stats(ctx context.Context, teamID int) (stats []*entity.MemberStats, err error) {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return
+	}
+
+	context, close := context.WithTimeout(ctx, contextTimeTeamStats*time.Second)
+	defer close()
+
+	rows, err := ta.db.QueryContext(context, teamStatsQuery, teamID, agencyID)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		s := &entity.MemberStats{}
+		if err = rows.Scan(&s.IDRealtor, &s.Listings, &s.Published, &s.Drafts, &s.Archived, &s.Deals, &s.ActiveLeases, &s.MonthlyRent); err != nil {
+			return
+		}
+		stats = append(stats, s)
+	}
+
+	return stats, rows.Err()
+}
@@ -0,0 +1,18 @@
+package adapterSql
+
+import (
+	"context"
+
+	"gilab.com/estate-agency-api/internal/entity"
+	"gilab.com/estate-agency-api/pkg/reqctx"
+)
+
+// tenant returns the agency every query of tenant data is scoped to. A context without an agency
+// fails with entity.ErrTenantRequired rather than falling back to all agencies.
+func tenant(ctx context.Context) (int, error) {
+	agencyID, ok := reqctx.Tenant(ctx)
+	if !ok {
+		return 0, entity.ErrTenantRequired
+	}
+	return agencyID, nil
+}
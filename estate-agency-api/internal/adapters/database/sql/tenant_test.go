@@ -0,0 +1,291 @@
+package adapterSql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"gilab.com/estate-agency-api/internal/entity"
+	"gilab.com/estate-agency-api/pkg/reqctx"
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// agencyID is the tenant of the requests; the rows the tests ask for belong to another agency, so the database
+// finds none of them when the queries are scoped to agencyID.
+const agencyID = 7
+
+type tenantCase struct {
+	name string
+	// expect sets up the statements the adapter must run, scoped to agencyID.
+	expect func(mock sqlmock.Sqlmock)
+	call   func(ctx context.Context, db *sql.DB) error
+	want   error
+}
+
+var tenantCases = []tenantCase{
+	{
+		name: "apartment of another agency is not found",
+		expect: func(mock sqlmock.Sqlmock) {
+			mock.ExpectPrepare(`FROM apartments WHERE id=\? AND agency_id=\? AND deleted_at IS NULL`).
+				ExpectQuery().WithArgs(1, agencyID).WillReturnRows(sqlmock.NewRows(nil))
+		},
+		call: func(ctx context.Context, db *sql.DB) error {
+			_, err := NewApartmentAdapter(db).GetByID(ctx, 1)
+			return err
+		},
+		want: sql.ErrNoRows,
+	},
+	{
+		name: "apartment of another agency is not updated",
+		expect: func(mock sqlmock.Sqlmock) {
+			mock.ExpectBegin()
+			mock.ExpectExec(`UPDATE apartments SET .* WHERE id=\? AND agency_id=\? AND version=\?`).
+				WithArgs(100, 1, agencyID, 3).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectRollback()
+		},
+		call: func(ctx context.Context, db *sql.DB) error {
+			_, err := NewApartmentAdapter(db).UpdateFields(ctx, 1, 3, map[string]any{"price": 100}, nil, &entity.AuditEntry{})
+			return err
+		},
+		want: entity.ErrVersionConflict,
+	},
+	{
+		name: "apartment is not moved to a realtor of another agency",
+		expect: func(mock sqlmock.Sqlmock) {
+			mock.ExpectBegin()
+			mock.ExpectQuery(`SELECT id FROM realtors WHERE id=\? AND agency_id=\?`).WithArgs(5, agencyID).WillReturnRows(sqlmock.NewRows(nil))
+			mock.ExpectRollback()
+		},
+		call: func(ctx context.Context, db *sql.DB) error {
+			_, err := NewApartmentAdapter(db).UpdateFields(ctx, 1, 3, map[string]any{"id_realtor": 5}, nil, &entity.AuditEntry{})
+			return err
+		},
+		want: entity.ErrValidation,
+	},
+	{
+		name: "apartment is not created for a realtor of another agency",
+		expect: func(mock sqlmock.Sqlmock) {
+			mock.ExpectBegin()
+			mock.ExpectQuery(`SELECT id FROM realtors WHERE id=\? AND agency_id=\?`).WithArgs(5, agencyID).WillReturnRows(sqlmock.NewRows(nil))
+			mock.ExpectRollback()
+		},
+		call: func(ctx context.Context, db *sql.DB) error {
+			_, err := NewApartmentAdapter(db).Create(ctx, &entity.Apartment{IDRealtor: 5}, nil, &entity.AuditEntry{})
+			return err
+		},
+		want: entity.ErrValidation,
+	},
+	{
+		name: "apartment of another agency is not deleted",
+		expect: func(mock sqlmock.Sqlmock) {
+			mock.ExpectBegin()
+			mock.ExpectExec(`UPDATE apartments SET deleted_at=\?.* WHERE id=\? AND agency_id=\? AND version=\?`).
+				WithArgs(sqlmock.AnyArg(), 1, agencyID, 3).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectRollback()
+		},
+		call: func(ctx context.Context, db *sql.DB) error {
+			return NewApartmentAdapter(db).Delete(ctx, 1, 3, nil, &entity.AuditEntry{})
+		},
+		want: entity.ErrVersionConflict,
+	},
+	{
+		name: "apartments of another agency are not exported",
+		expect: func(mock sqlmock.Sqlmock) {
+			mock.ExpectPrepare(`FROM apartments WHERE agency_id=\? AND deleted_at IS NULL`).
+				ExpectQuery().WithArgs(agencyID).WillReturnRows(sqlmock.NewRows(nil))
+		},
+		call: func(ctx context.Context, db *sql.DB) error {
+			return NewApartmentAdapter(db).Each(ctx, entity.ApartmentFilter{}, func(*entity.Apartment) error { return sql.ErrNoRows })
+		},
+	},
+	{
+		name: "realtor of another agency is not found",
+		expect: func(mock sqlmock.Sqlmock) {
+			mock.ExpectPrepare(`FROM realtors WHERE id=\? AND agency_id=\? AND deleted_at IS NULL`).
+				ExpectQuery().WithArgs(2, agencyID).WillReturnRows(sqlmock.NewRows(nil))
+		},
+		call: func(ctx context.Context, db *sql.DB) error {
+			_, err := NewRealtorAdapter(db).GetByID(ctx, 2)
+			return err
+		},
+		want: sql.ErrNoRows,
+	},
+	{
+		name: "realtor of another agency is not deleted",
+		expect: func(mock sqlmock.Sqlmock) {
+			mock.ExpectBegin()
+			mock.ExpectExec(`UPDATE realtors SET deleted_at=\?.* WHERE id=\? AND agency_id=\? AND version=\?`).
+				WithArgs(sqlmock.AnyArg(), 2, agencyID, 1).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectRollback()
+		},
+		call: func(ctx context.Context, db *sql.DB) error {
+			return NewRealtorAdapter(db).Delete(ctx, 2, 1, nil, &entity.AuditEntry{})
+		},
+		want: entity.ErrVersionConflict,
+	},
+	{
+		name: "realtors of another agency are not exported",
+		expect: func(mock sqlmock.Sqlmock) {
+			mock.ExpectPrepare(`FROM realtors WHERE agency_id=\? AND deleted_at IS NULL`).
+				ExpectQuery().WithArgs(agencyID).WillReturnRows(sqlmock.NewRows(nil))
+		},
+		call: func(ctx context.Context, db *sql.DB) error {
+			return NewRealtorAdapter(db).Each(ctx, func(*entity.Realtor) error { return sql.ErrNoRows })
+		},
+	},
+	{
+		name: "client of another agency is not found",
+		expect: func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery(`FROM clients WHERE id=\? AND agency_id=\?`).WithArgs(3, agencyID).WillReturnRows(sqlmock.NewRows(nil))
+		},
+		call: func(ctx context.Context, db *sql.DB) error {
+			_, err := NewClientAdapter(db).GetByID(ctx, 3)
+			return err
+		},
+		want: sql.ErrNoRows,
+	},
+	{
+		name: "client of another agency is not deleted",
+		expect: func(mock sqlmock.Sqlmock) {
+			mock.ExpectBegin()
+			mock.ExpectExec(`DELETE FROM clients WHERE id=\? AND agency_id=\?`).WithArgs(3, agencyID).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectRollback()
+		},
+		call: func(ctx context.Context, db *sql.DB) error {
+			return NewClientAdapter(db).Delete(ctx, 3, &entity.AuditEntry{})
+		},
+		want: sql.ErrNoRows,
+	},
+	{
+		name: "webhook of another agency is not found",
+		expect: func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery(`FROM webhooks WHERE id=\? AND agency_id=\?`).WithArgs(4, agencyID).WillReturnRows(sqlmock.NewRows(nil))
+		},
+		call: func(ctx context.Context, db *sql.DB) error {
+			_, err := NewWebhookAdapter(db).GetByID(ctx, 4)
+			return err
+		},
+		want: sql.ErrNoRows,
+	},
+	{
+		name: "team of another agency is not found",
+		expect: func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery(`FROM teams WHERE id=\? AND agency_id=\?`).WithArgs(5, agencyID).WillReturnRows(sqlmock.NewRows(nil))
+		},
+		call: func(ctx context.Context, db *sql.DB) error {
+			_, err := NewTeamAdapter(db).GetByID(ctx, 5)
+			return err
+		},
+		want: sql.ErrNoRows,
+	},
+	{
+		name: "team is not created in an office of another agency",
+		expect: func(mock sqlmock.Sqlmock) {
+			mock.ExpectBegin()
+			mock.ExpectExec(`INSERT INTO teams .* FROM offices WHERE id=\? AND agency_id=\?`).
+				WithArgs("Sales", nil, sqlmock.AnyArg(), 6, agencyID).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectRollback()
+		},
+		call: func(ctx context.Context, db *sql.DB) error {
+			_, err := NewTeamAdapter(db).Create(ctx, &entity.Team{Name: "Sales", IDOffice: 6}, &entity.AuditEntry{})
+			return err
+		},
+		want: sql.ErrNoRows,
+	},
+	{
+		name: "office of another agency is not deleted",
+		expect: func(mock sqlmock.Sqlmock) {
+			mock.ExpectBegin()
+			mock.ExpectExec(`DELETE FROM offices WHERE id=\? AND agency_id=\?`).WithArgs(6, agencyID).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectRollback()
+		},
+		call: func(ctx context.Context, db *sql.DB) error {
+			return NewOfficeAdapter(db).Delete(ctx, 6, &entity.AuditEntry{})
+		},
+		want: sql.ErrNoRows,
+	},
+	{
+		name: "lease of another agency is not found",
+		expect: func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery(`FROM leases .* WHERE l\.id=\? AND a\.agency_id=\?`).WithArgs(8, agencyID).WillReturnRows(sqlmock.NewRows(nil))
+		},
+		call: func(ctx context.Context, db *sql.DB) error {
+			_, err := NewLeaseAdapter(db).GetByID(ctx, 8)
+			return err
+		},
+		want: sql.ErrNoRows,
+	},
+	{
+		name: "lease is not created for an apartment of another agency",
+		expect: func(mock sqlmock.Sqlmock) {
+			mock.ExpectBegin()
+			mock.ExpectQuery(`SELECT id FROM apartments WHERE id=\? AND agency_id=\? AND deleted_at IS NULL FOR UPDATE`).
+				WithArgs(1, agencyID).WillReturnRows(sqlmock.NewRows(nil))
+			mock.ExpectRollback()
+		},
+		call: func(ctx context.Context, db *sql.DB) error {
+			_, err := NewLeaseAdapter(db).Create(ctx, &entity.Lease{IDApartment: 1, IDClient: 3}, nil, &entity.AuditEntry{})
+			return err
+		},
+		want: sql.ErrNoRows,
+	},
+	{
+		name: "lease is not created for a client of another agency",
+		expect: func(mock sqlmock.Sqlmock) {
+			mock.ExpectBegin()
+			mock.ExpectQuery(`SELECT id FROM apartments WHERE id=\? AND agency_id=\?`).
+				WithArgs(1, agencyID).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery(`SELECT COUNT\(\*\) FROM leases`).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+			mock.ExpectQuery(`SELECT id FROM clients WHERE id=\? AND agency_id=\?`).
+				WithArgs(3, agencyID).WillReturnRows(sqlmock.NewRows(nil))
+			mock.ExpectRollback()
+		},
+		call: func(ctx context.Context, db *sql.DB) error {
+			_, err := NewLeaseAdapter(db).Create(ctx, &entity.Lease{IDApartment: 1, IDClient: 3}, nil, &entity.AuditEntry{})
+			return err
+		},
+		want: sql.ErrNoRows,
+	},
+}
+
+func TestAdaptersScopeToTenant(t *testing.T) {
+	for _, tt := range tenantCases {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+
+			tt.expect(mock)
+			err = tt.call(reqctx.WithTenant(context.Background(), agencyID), db)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("error = %v, want %v", err, tt.want)
+			}
+			if err = mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// TestAdaptersRequireTenant runs the same calls without a tenant: they must fail before touching the database.
+func TestAdaptersRequireTenant(t *testing.T) {
+	for _, tt := range tenantCases {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+
+			if err = tt.call(context.Background(), db); !errors.Is(err, entity.ErrTenantRequired) {
+				t.Errorf("error = %v, want %v", err, entity.ErrTenantRequired)
+			}
+			if err = mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
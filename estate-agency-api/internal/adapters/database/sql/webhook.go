@@ -0,0 +1,360 @@
+package adapterSql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"gilab.com/estate-agency-api/internal/entity"
+)
+
+const (
+	contextTimeGetAllWebhook    = 2
+	contextTimeGetOneWebhook    = 1
+	contextTimeCreateWebhook    = 1
+	contextTimeUpdateWebhook    = 1
+	contextTimeDeleteWebhook    = 1
+	contextTimeEnqueueDelivery  = 1
+	contextTimeClaimDeliveries  = 2
+	contextTimeUpdateDelivery   = 1
+	contextTimeGetAllDeliveries = 2
+)
+
+const webhookColumns = `id, url, event_types, secret, active, created_at`
+
+// webhookDeliveryColumns are qualified by the alias d, since deliveries are joined with their webhook's agency.
+const webhookDeliveryColumns = `d.id, d.webhook_id, d.event_id, d.event_type, d.payload, d.status, d.attempts, d.next_attempt_at, d.last_status_code, d.last_error, d.created_at, d.delivered_at`
+
+func scanWebhook(row scanner, webhook *entity.Webhook) error {
+	var eventTypes []byte
+	if err := row.Scan(&webhook.ID, &webhook.URL, &eventTypes, &webhook.Secret, &webhook.Active, &webhook.CreatedAt); err != nil {
+		return err
+	}
+	return json.Unmarshal(eventTypes, &webhook.EventTypes)
+}
+
+func scanWebhookDelivery(row scanner, delivery *entity.WebhookDelivery) error {
+	var payload []byte
+	if err := row.Scan(&delivery.ID, &delivery.WebhookID, &delivery.EventID, &delivery.EventType, &payload, &delivery.Status, &delivery.Attempts, &delivery.NextAttemptAt, &delivery.LastStatusCode, &delivery.LastError, &delivery.CreatedAt, &delivery.DeliveredAt); err != nil {
+		return err
+	}
+	delivery.Payload = payload
+	return nil
+}
+
+type webhookAdapter struct {
+	db *sql.DB
+}
+
+func NewWebhookAdapter(db *sql.DB) *webhookAdapter {
+	return &webhookAdapter{db: db}
+}
+
+func (ws *webhookAdapter) GetAll(ctx context.Context, page int, pageSize int) (webhooks []*entity.Webhook, err error) {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return
+	}
+	q := `SELECT ` + webhookColumns + ` FROM webhooks WHERE agency_id=? ORDER BY id LIMIT ?,?`
+
+	context, close := context.WithTimeout(ctx, contextTimeGetAllWebhook*time.Second)
+	defer close()
+
+	rows, err := ws.db.QueryContext(context, q, agencyID, page*pageSize, pageSize)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		webhook := &entity.Webhook{}
+		if err = scanWebhook(rows, webhook); err != nil {
+			return
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	return webhooks, rows.Err()
+}
+
+func (ws *webhookAdapter) GetActive(ctx context.Context) (webhooks []*entity.Webhook, err error) {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return
+	}
+	q := `SELECT ` + webhookColumns + ` FROM webhooks WHERE agency_id=? AND active=1`
+
+	context, close := context.WithTimeout(ctx, contextTimeGetAllWebhook*time.Second)
+	defer close()
+
+	rows, err := ws.db.QueryContext(context, q, agencyID)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		webhook := &entity.Webhook{}
+		if err = scanWebhook(rows, webhook); err != nil {
+			return
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	return webhooks, rows.Err()
+}
+
+func (ws *webhookAdapter) GetByID(ctx context.Context, id int) (webhook *entity.Webhook, err error) {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return
+	}
+	q := `SELECT ` + webhookColumns + ` FROM webhooks WHERE id=? AND agency_id=?`
+
+	context, close := context.WithTimeout(ctx, contextTimeGetOneWebhook*time.Second)
+	defer close()
+
+	webhook = &entity.Webhook{}
+	if err = scanWebhook(ws.db.QueryRowContext(context, q, id, agencyID), webhook); err != nil {
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+func (ws *webhookAdapter) Create(ctx context.Context, webhook *entity.Webhook, audit *entity.AuditEntry) (id int64, err error) {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return
+	}
+	q := `INSERT INTO webhooks (agency_id, url, event_types, secret, active, created_at) VALUES (?, ?, ?, ?, ?, ?)`
+
+	eventTypes, err := json.Marshal(webhook.EventTypes)
+	if err != nil {
+		return
+	}
+
+	context, close := context.WithTimeout(ctx, contextTimeCreateWebhook*time.Second)
+	defer close()
+
+	tx, err := ws.db.BeginTx(context, nil)
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(context, q, agencyID, webhook.URL, eventTypes, webhook.Secret, webhook.Active, webhook.CreatedAt)
+	if err != nil {
+		return
+	}
+
+	id, err = result.LastInsertId()
+	if err != nil {
+		return
+	}
+	webhook.ID = int(id)
+
+	if err = insertAudit(context, tx, agencyID, webhook.ID, audit); err != nil {
+		return
+	}
+
+	return id, tx.Commit()
+}
+
+func (ws *webhookAdapter) UpdateFields(ctx context.Context, id int, fields map[string]any, audit *entity.AuditEntry) (aff int64, err error) {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return
+	}
+	set, args, err := setClause(fields, webhookUpdatableColumns)
+	if err != nil {
+		return
+	}
+	q := `UPDATE webhooks SET ` + set + ` WHERE id=? AND agency_id=?`
+
+	context, close := context.WithTimeout(ctx, contextTimeUpdateWebhook*time.Second)
+	defer close()
+
+	tx, err := ws.db.BeginTx(context, nil)
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(context, q, append(args, id, agencyID)...)
+	if err != nil {
+		return
+	}
+
+	aff, err = result.RowsAffected()
+	if err != nil {
+		return
+	}
+
+	if err = insertAudit(context, tx, agencyID, id, audit); err != nil {
+		return
+	}
+
+	return aff, tx.Commit()
+}
+
+func (ws *webhookAdapter) Delete(ctx context.Context, id int, audit *entity.AuditEntry) error {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return err
+	}
+	q := `DELETE FROM webhooks WHERE id=? AND agency_id=?`
+
+	context, close := context.WithTimeout(ctx, contextTimeDeleteWebhook*time.Second)
+	defer close()
+
+	tx, err := ws.db.BeginTx(context, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(context, q, id, agencyID)
+	if err != nil {
+		return err
+	}
+
+	aff, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if aff == 0 {
+		return sql.ErrNoRows
+	}
+
+	if err = insertAudit(context, tx, agencyID, id, audit); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// EnqueueDelivery stores a pending delivery to a webhook of the agency. A repeated event for the same webhook is ignored,
+// which makes the fan-out safe against redelivered outbox events.
+func (ws *webhookAdapter) EnqueueDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return err
+	}
+	q := `INSERT IGNORE INTO webhook_deliveries (webhook_id, event_id, event_type, payload, status, attempts, next_attempt_at, last_error, created_at)
+		SELECT id, ?, ?, ?, ?, 0, ?, '', ? FROM webhooks WHERE id=? AND agency_id=?`
+
+	context, close := context.WithTimeout(ctx, contextTimeEnqueueDelivery*time.Second)
+	defer close()
+
+	_, err = ws.db.ExecContext(context, q, delivery.EventID, delivery.EventType, []byte(delivery.Payload), delivery.Status, delivery.NextAttemptAt, delivery.CreatedAt, delivery.WebhookID, agencyID)
+
+	return err
+}
+
+// ClaimDeliveries returns pending deliveries of the agency that are due and postpones them by lease,
+// so that another worker does not pick them up while they are being sent.
+func (ws *webhookAdapter) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) (deliveries []*entity.WebhookDelivery, err error) {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return
+	}
+	q := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries d JOIN webhooks w ON w.id=d.webhook_id
+		WHERE w.agency_id=? AND d.status=? AND d.next_attempt_at<=? ORDER BY d.next_attempt_at LIMIT ? FOR UPDATE OF d SKIP LOCKED`
+
+	context, close := context.WithTimeout(ctx, contextTimeClaimDeliveries*time.Second)
+	defer close()
+
+	tx, err := ws.db.BeginTx(context, nil)
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	rows, err := tx.QueryContext(context, q, agencyID, entity.WebhookDeliveryPending, now, limit)
+	if err != nil {
+		return
+	}
+
+	for rows.Next() {
+		delivery := &entity.WebhookDelivery{}
+		if err = scanWebhookDelivery(rows, delivery); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, delivery := range deliveries {
+		if _, err = tx.ExecContext(context, `UPDATE webhook_deliveries SET next_attempt_at=? WHERE id=?`, now.Add(lease), delivery.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return deliveries, tx.Commit()
+}
+
+func (ws *webhookAdapter) UpdateDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return err
+	}
+	q := `UPDATE webhook_deliveries d JOIN webhooks w ON w.id=d.webhook_id
+		SET d.status=?, d.attempts=?, d.next_attempt_at=?, d.last_status_code=?, d.last_error=?, d.delivered_at=? WHERE d.id=? AND w.agency_id=?`
+
+	context, close := context.WithTimeout(ctx, contextTimeUpdateDelivery*time.Second)
+	defer close()
+
+	_, err = ws.db.ExecContext(context, q, delivery.Status, delivery.Attempts, delivery.NextAttemptAt, delivery.LastStatusCode, delivery.LastError, delivery.DeliveredAt, delivery.ID, agencyID)
+
+	return err
+}
+
+func (ws *webhookAdapter) GetDeliveries(ctx context.Context, webhookID int, filter entity.WebhookDeliveryFilter, page int, pageSize int) (deliveries []*entity.WebhookDelivery, err error) {
+
+	agencyID, err := tenant(ctx)
+	if err != nil {
+		return
+	}
+	q := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries d JOIN webhooks w ON w.id=d.webhook_id WHERE d.webhook_id=? AND w.agency_id=?`
+	args := []any{webhookID, agencyID}
+	if len(filter.Status) != 0 {
+		q += ` AND d.status=?`
+		args = append(args, filter.Status)
+	}
+	q += ` ORDER BY d.id DESC LIMIT ?,?`
+
+	context, close := context.WithTimeout(ctx, contextTimeGetAllDeliveries*time.Second)
+	defer close()
+
+	rows, err := ws.db.QueryContext(context, q, append(args, page*pageSize, pageSize)...)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		delivery := &entity.WebhookDelivery{}
+		if err = scanWebhookDelivery(rows, delivery); err != nil {
+			return
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
@@ -0,0 +1,67 @@
+package adapterEvents
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"gilab.com/estate-agency-api/internal/entity"
+)
+
+type Handler func(ctx context.Context, event *entity.Event) error
+
+// inProcessPublisher delivers events synchronously to the subscribers registered in this process.
+// Subscribers are named, so that the relay can track and retry the deliveries to each of them separately.
+type inProcessPublisher struct {
+	mu          sync.RWMutex
+	subscribers map[string]Handler
+}
+
+func NewInProcessPublisher() *inProcessPublisher {
+	return &inProcessPublisher{subscribers: make(map[string]Handler)}
+}
+
+// Subscribe registers the handler under a name unique in the process and returns a function that removes it.
+// The name identifies the subscriber in the outbox, so it must stay the same across restarts.
+func (p *inProcessPublisher) Subscribe(name string, handler Handler) (unsubscribe func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.subscribers[name]; ok {
+		panic(fmt.Sprintf("adapterEvents: subscriber %q is already registered", name))
+	}
+	p.subscribers[name] = handler
+
+	return func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		delete(p.subscribers, name)
+	}
+}
+
+// Subscribers returns the names of the registered subscribers in a stable order.
+func (p *inProcessPublisher) Subscribers() []string {
+	p.mu.RLock()
+	names := make([]string, 0, len(p.subscribers))
+	for name := range p.subscribers {
+		names = append(names, name)
+	}
+	p.mu.RUnlock()
+
+	sort.Strings(names)
+	return names
+}
+
+// Deliver passes the event to the named subscriber. A subscriber removed in the meantime is skipped.
+func (p *inProcessPublisher) Deliver(ctx context.Context, subscriber string, event *entity.Event) error {
+	p.mu.RLock()
+	handler, ok := p.subscribers[subscriber]
+	p.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	return handler(ctx, event)
+}
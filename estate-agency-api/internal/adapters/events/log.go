@@ -0,0 +1,29 @@
+package adapterEvents
+
+import (
+	"context"
+	"log/slog"
+
+	"gilab.com/estate-agency-api/internal/entity"
+)
+
+type logPublisher struct {
+	logger *slog.Logger
+}
+
+func NewLogPublisher(logger *slog.Logger) *logPublisher {
+	return &logPublisher{logger: logger}
+}
+
+func (p *logPublisher) Publish(ctx context.Context, event *entity.Event) error {
+	p.logger.Info("event",
+		slog.Int64("id", event.ID),
+		slog.String("type", event.Type),
+		slog.String("aggregate_type", event.AggregateType),
+		slog.Int("aggregate_id", event.AggregateID),
+		slog.String("payload", string(event.Payload)),
+		slog.Time("occurred_at", event.OccurredAt),
+	)
+
+	return nil
+}
@@ -0,0 +1,374 @@
+package adapterGeocoder
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gilab.com/estate-agency-api/internal/entity"
+	"gilab.com/estate-agency-api/pkg/address"
+)
+
+// place is a named location. Its point is the one given by the gazetteer or else the centroid of the houses in it.
+type place struct {
+	name     string
+	point    *entity.GeoPoint
+	lat, lng float64
+	count    int
+}
+
+func (p *place) add(point entity.GeoPoint) {
+	p.lat += point.Lat
+	p.lng += point.Lng
+	p.count++
+}
+
+func (p *place) location() *entity.GeoPoint {
+	if p.point != nil || p.count == 0 {
+		return p.point
+	}
+	return &entity.GeoPoint{Lat: p.lat / float64(p.count), Lng: p.lng / float64(p.count)}
+}
+
+type street struct {
+	place
+	streetType string
+	houses     map[string]entity.GeoPoint
+}
+
+type city struct {
+	place
+	streets map[string][]*street
+}
+
+// street finds the street by name, preferring the one of the same type. A street of another type is only
+// taken when it is the sole street of that name and one of the types is unknown.
+func (c *city) street(name string, streetType string) *street {
+	candidates := c.streets[address.Key(name)]
+	for _, s := range candidates {
+		if s.streetType == streetType {
+			return s
+		}
+	}
+	if len(candidates) == 1 && (len(streetType) == 0 || len(candidates[0].streetType) == 0) {
+		return candidates[0]
+	}
+	return nil
+}
+
+// gazetteer geocodes addresses against a list of cities, streets and houses loaded from a local file.
+// It makes no network calls and is not modified after loading, so it is safe for concurrent use.
+type gazetteer struct {
+	cities map[string]*city
+}
+
+// NewGazetteer returns an empty gazetteer, which normalizes addresses without finding their coordinates.
+func NewGazetteer() *gazetteer {
+	return &gazetteer{cities: make(map[string]*city)}
+}
+
+// LoadGazetteer reads a gazetteer from a CSV file (see ReadCSV) or an OSM XML extract (see ReadOSM),
+// told apart by the extension.
+func LoadGazetteer(path string) (*gazetteer, error) {
+	const op = "adapterGeocoder.LoadGazetteer"
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer f.Close()
+
+	g := NewGazetteer()
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		err = g.ReadCSV(f)
+	case ".osm":
+		err = g.ReadOSM(f)
+	default:
+		err = fmt.Errorf("unsupported gazetteer format %q", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s: %w", op, path, err)
+	}
+
+	return g, nil
+}
+
+// Geocode normalizes the address and finds its coordinates. The city named in the address takes precedence
+// over the given one. Names known to the gazetteer are replaced by their canonical spelling. Coordinates are
+// those of the house, or of the street when the house is unknown, and nil when the street is unknown too.
+func (g *gazetteer) Geocode(text string, cityName string) (entity.Address, *entity.GeoPoint) {
+	parsed := address.Parse(text)
+	if len(parsed.City) == 0 {
+		parsed.City = address.CityName(cityName)
+	}
+	normalized := entity.Address{Street: parsed.Street, House: parsed.House, Building: parsed.Building, City: parsed.City}
+
+	c, ok := g.cities[address.Key(parsed.City)]
+	if !ok {
+		if c, ok = g.cities[address.Key(address.CityName(cityName))]; !ok {
+			return normalized, nil
+		}
+	}
+	normalized.City = c.name
+
+	s := c.street(parsed.StreetName, parsed.StreetType)
+	if s == nil {
+		return normalized, nil
+	}
+	normalized.Street = s.name
+
+	if point, ok := s.houses[houseKey(parsed.House, parsed.Building)]; ok {
+		return normalized, &point
+	}
+	if point, ok := s.houses[houseKey(parsed.House, "")]; ok && len(parsed.House) != 0 {
+		return normalized, &point
+	}
+
+	return normalized, s.location()
+}
+
+func houseKey(house string, building string) string {
+	if len(building) == 0 {
+		return address.Key(house)
+	}
+	return address.Key(house) + "/" + address.Key(building)
+}
+
+// splitHouse splits a house number like "5к2" or "5 корп. 2" into the house and the building.
+func splitHouse(s string) (string, string) {
+	parsed := address.Parse(s)
+	if len(parsed.House) == 0 {
+		return strings.ToLower(strings.TrimSpace(s)), ""
+	}
+	return parsed.House, parsed.Building
+}
+
+func (g *gazetteer) addCity(name string, aliases []string, point *entity.GeoPoint) *city {
+	name = address.CityName(name)
+	key := address.Key(name)
+
+	c, ok := g.cities[key]
+	if !ok {
+		c = &city{place: place{name: name}, streets: make(map[string][]*street)}
+		g.cities[key] = c
+	}
+	if c.point == nil {
+		c.point = point
+	}
+
+	for _, alias := range aliases {
+		if key := address.Key(address.CityName(alias)); len(key) != 0 {
+			if _, ok := g.cities[key]; !ok {
+				g.cities[key] = c
+			}
+		}
+	}
+
+	return c
+}
+
+func (g *gazetteer) addStreet(c *city, name string, point *entity.GeoPoint) *street {
+	parsed := address.Parse(name)
+	if len(parsed.StreetName) == 0 {
+		parsed.StreetName = name
+	}
+	key := address.Key(parsed.StreetName)
+
+	for _, s := range c.streets[key] {
+		if s.streetType == parsed.StreetType {
+			if s.point == nil {
+				s.point = point
+			}
+			return s
+		}
+	}
+
+	s := &street{place: place{name: name, point: point}, streetType: parsed.StreetType, houses: make(map[string]entity.GeoPoint)}
+	c.streets[key] = append(c.streets[key], s)
+	return s
+}
+
+func (g *gazetteer) addHouse(cityName string, streetName string, house string, building string, point entity.GeoPoint) {
+	c := g.addCity(cityName, nil, nil)
+	s := g.addStreet(c, streetName, nil)
+
+	if len(building) == 0 {
+		house, building = splitHouse(house)
+	}
+	key := houseKey(house, building)
+	if _, ok := s.houses[key]; ok {
+		return
+	}
+
+	s.houses[key] = point
+	s.add(point)
+	c.add(point)
+}
+
+// ReadCSV adds the rows of a CSV file with a header naming its columns: city, street, house, building, lat, lng
+// and aliases, of which city, lat and lng are required. A row without a street gives the point of its city and
+// may list other names of the city in aliases, separated by "|". A row without a house gives the point of its street.
+func (g *gazetteer) ReadCSV(r io.Reader) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return err
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"city", "lat", "lng"} {
+		if _, ok := columns[name]; !ok {
+			return fmt.Errorf("missing column %q", name)
+		}
+	}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		line, _ := reader.FieldPos(0)
+		lat, err := strconv.ParseFloat(field("lat"), 64)
+		if err != nil {
+			return fmt.Errorf("line %d: invalid lat: %w", line, err)
+		}
+		lng, err := strconv.ParseFloat(field("lng"), 64)
+		if err != nil {
+			return fmt.Errorf("line %d: invalid lng: %w", line, err)
+		}
+		point := entity.GeoPoint{Lat: lat, Lng: lng}
+		if len(field("city")) == 0 {
+			return fmt.Errorf("line %d: city is empty", line)
+		}
+
+		switch {
+		case len(field("street")) == 0:
+			var aliases []string
+			if len(field("aliases")) != 0 {
+				aliases = strings.Split(field("aliases"), "|")
+			}
+			g.addCity(field("city"), aliases, &point)
+		case len(field("house")) == 0:
+			g.addStreet(g.addCity(field("city"), nil, nil), field("street"), &point)
+		default:
+			g.addHouse(field("city"), field("street"), field("house"), field("building"), point)
+		}
+	}
+}
+
+type osmTag struct {
+	Key   string `xml:"k,attr"`
+	Value string `xml:"v,attr"`
+}
+
+type osmNode struct {
+	ID   int64    `xml:"id,attr"`
+	Lat  float64  `xml:"lat,attr"`
+	Lon  float64  `xml:"lon,attr"`
+	Tags []osmTag `xml:"tag"`
+}
+
+type osmWay struct {
+	Refs []struct {
+		Ref int64 `xml:"ref,attr"`
+	} `xml:"nd"`
+	Tags []osmTag `xml:"tag"`
+}
+
+// ReadOSM adds the places and addresses of an OSM XML extract. Cities are the nodes tagged place=city, town,
+// village or hamlet, with their name:*, alt_name, old_name and short_name as aliases. Houses are the nodes and
+// buildings with addr:city, addr:street and addr:housenumber; a building is located at the centroid of its nodes.
+// Addresses without addr:city are skipped, since the extract has no boundaries to place them in.
+func (g *gazetteer) ReadOSM(r io.Reader) error {
+	decoder := xml.NewDecoder(r)
+	nodes := make(map[int64]entity.GeoPoint)
+
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch start.Name.Local {
+		case "node":
+			var node osmNode
+			if err = decoder.DecodeElement(&node, &start); err != nil {
+				return err
+			}
+			point := entity.GeoPoint{Lat: node.Lat, Lng: node.Lon}
+			nodes[node.ID] = point
+			g.addOSM(node.Tags, point)
+		case "way":
+			var way osmWay
+			if err = decoder.DecodeElement(&way, &start); err != nil {
+				return err
+			}
+			var centroid place
+			for _, nd := range way.Refs {
+				if point, ok := nodes[nd.Ref]; ok {
+					centroid.add(point)
+				}
+			}
+			if point := centroid.location(); point != nil {
+				g.addOSM(way.Tags, *point)
+			}
+		}
+	}
+}
+
+func (g *gazetteer) addOSM(tags []osmTag, point entity.GeoPoint) {
+	if len(tags) == 0 {
+		return
+	}
+
+	values := make(map[string]string, len(tags))
+	var aliases []string
+	for _, tag := range tags {
+		values[tag.Key] = tag.Value
+		switch {
+		case strings.HasPrefix(tag.Key, "name:"), tag.Key == "alt_name", tag.Key == "old_name", tag.Key == "short_name":
+			aliases = append(aliases, strings.Split(tag.Value, ";")...)
+		}
+	}
+
+	switch values["place"] {
+	case "city", "town", "village", "hamlet":
+		if len(values["name"]) != 0 {
+			g.addCity(values["name"], aliases, &point)
+		}
+	}
+
+	if len(values["addr:city"]) != 0 && len(values["addr:street"]) != 0 && len(values["addr:housenumber"]) != 0 {
+		g.addHouse(values["addr:city"], values["addr:street"], values["addr:housenumber"], "", point)
+	}
+}
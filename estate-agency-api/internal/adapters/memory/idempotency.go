@@ -0,0 +1,107 @@
+package adapterMemory
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"gilab.com/estate-agency-api/internal/entity"
+)
+
+const idempotencySweepInterval = time.Minute
+
+type idempotencyAdapter struct {
+	mu        sync.Mutex
+	records   map[string]*entity.IdempotencyRecord
+	lastSweep time.Time
+}
+
+func NewIdempotencyAdapter() *idempotencyAdapter {
+	return &idempotencyAdapter{records: make(map[string]*entity.IdempotencyRecord), lastSweep: time.Now()}
+}
+
+func (ia *idempotencyAdapter) Get(ctx context.Context, key string) (*entity.IdempotencyRecord, error) {
+	ia.mu.Lock()
+	defer ia.mu.Unlock()
+
+	record, ok := ia.records[key]
+	if !ok || !record.ExpiresAt.After(time.Now()) {
+		return nil, sql.ErrNoRows
+	}
+
+	copied := *record
+	return &copied, nil
+}
+
+func (ia *idempotencyAdapter) Reserve(ctx context.Context, record *entity.IdempotencyRecord) error {
+	ia.mu.Lock()
+	defer ia.mu.Unlock()
+
+	now := time.Now()
+	ia.sweep(now)
+
+	if existing, ok := ia.records[record.Key]; ok && existing.ExpiresAt.After(now) {
+		return entity.ErrIdempotencyKeyExists
+	}
+
+	copied := *record
+	ia.records[record.Key] = &copied
+
+	return nil
+}
+
+func (ia *idempotencyAdapter) Complete(ctx context.Context, record *entity.IdempotencyRecord) error {
+	ia.mu.Lock()
+	defer ia.mu.Unlock()
+
+	existing, ok := ia.records[record.Key]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	existing.StatusCode = record.StatusCode
+	existing.ContentType = record.ContentType
+	existing.Response = record.Response
+	existing.Completed = true
+
+	return nil
+}
+
+func (ia *idempotencyAdapter) Release(ctx context.Context, key string) error {
+	ia.mu.Lock()
+	defer ia.mu.Unlock()
+
+	if existing, ok := ia.records[key]; ok && !existing.Completed {
+		delete(ia.records, key)
+	}
+
+	return nil
+}
+
+func (ia *idempotencyAdapter) Clean(ctx context.Context, before time.Time) (aff int64, err error) {
+	ia.mu.Lock()
+	defer ia.mu.Unlock()
+
+	for key, record := range ia.records {
+		if !record.ExpiresAt.After(before) {
+			delete(ia.records, key)
+			aff++
+		}
+	}
+
+	return aff, nil
+}
+
+// sweep drops expired records so keys that are never retried do not pile up.
+func (ia *idempotencyAdapter) sweep(now time.Time) {
+	if now.Sub(ia.lastSweep) < idempotencySweepInterval {
+		return
+	}
+	ia.lastSweep = now
+
+	for key, record := range ia.records {
+		if !record.ExpiresAt.After(now) {
+			delete(ia.records, key)
+		}
+	}
+}
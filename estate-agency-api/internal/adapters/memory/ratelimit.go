@@ -0,0 +1,73 @@
+package adapterMemory
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"gilab.com/estate-agency-api/internal/entity"
+)
+
+const rateLimitSweepInterval = time.Minute
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	limit   entity.RateLimit
+}
+
+// refill adds the tokens earned since the last update.
+func (b *bucket) refill(now time.Time) {
+	b.tokens = math.Min(float64(b.limit.Burst), b.tokens+now.Sub(b.updated).Seconds()*b.limit.Rate)
+	b.updated = now
+}
+
+// rateLimitAdapter keeps token buckets in process memory, so every instance enforces its own limits.
+type rateLimitAdapter struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func NewRateLimitAdapter() *rateLimitAdapter {
+	return &rateLimitAdapter{buckets: make(map[string]*bucket), lastSweep: time.Now()}
+}
+
+func (ra *rateLimitAdapter) Allow(ctx context.Context, key string, limit entity.RateLimit) (entity.RateLimitResult, error) {
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+
+	now := time.Now()
+	ra.sweep(now)
+
+	b, ok := ra.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), updated: now}
+		ra.buckets[key] = b
+	}
+	b.limit = limit
+	b.refill(now)
+
+	if b.tokens < 1 {
+		return limit.Result(b.tokens, false), nil
+	}
+	b.tokens--
+
+	return limit.Result(b.tokens, true), nil
+}
+
+// sweep drops the buckets that have refilled completely, they are the same as new ones.
+func (ra *rateLimitAdapter) sweep(now time.Time) {
+	if now.Sub(ra.lastSweep) < rateLimitSweepInterval {
+		return
+	}
+	ra.lastSweep = now
+
+	for key, b := range ra.buckets {
+		b.refill(now)
+		if b.tokens >= float64(b.limit.Burst) {
+			delete(ra.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package adapterMetrics
+
+import (
+	"context"
+	"time"
+
+	"gilab.com/estate-agency-api/internal/domain/service"
+	"gilab.com/estate-agency-api/internal/entity"
+)
+
+const apartmentStorage = "apartment"
+
+// apartmentAdapter times every call of the wrapped apartment storage.
+type apartmentAdapter struct {
+	next     service.ApartmentStorage
+	observer Observer
+}
+
+func NewApartmentAdapter(next service.ApartmentStorage, observer Observer) *apartmentAdapter {
+	return &apartmentAdapter{next: next, observer: observer}
+}
+
+func (a *apartmentAdapter) GetAll(ctx context.Context, filter entity.ApartmentFilter, page int, pageSize int) (apartments []*entity.Apartment, err error) {
+	defer observe(a.observer, apartmentStorage, "GetAll", time.Now(), &err)
+	return a.next.GetAll(ctx, filter, page, pageSize)
+}
+
+func (a *apartmentAdapter) Each(ctx context.Context, filter entity.ApartmentFilter, fn func(apartment *entity.Apartment) error) (err error) {
+	defer observe(a.observer, apartmentStorage, "Each", time.Now(), &err)
+	return a.next.Each(ctx, filter, fn)
+}
+
+func (a *apartmentAdapter) GetByID(ctx context.Context, id int) (apartment *entity.Apartment, err error) {
+	defer observe(a.observer, apartmentStorage, "GetByID", time.Now(), &err)
+	return a.next.GetByID(ctx, id)
+}
+
+func (a *apartmentAdapter) Create(ctx context.Context, apartment *entity.Apartment, events []*entity.Event, audit *entity.AuditEntry) (id int64, err error) {
+	defer observe(a.observer, apartmentStorage, "Create", time.Now(), &err)
+	return a.next.Create(ctx, apartment, events, audit)
+}
+
+func (a *apartmentAdapter) UpdateFields(ctx context.Context, id int, version int, fields map[string]any, events []*entity.Event, audit *entity.AuditEntry) (aff int64, err error) {
+	defer observe(a.observer, apartmentStorage, "UpdateFields", time.Now(), &err)
+	return a.next.UpdateFields(ctx, id, version, fields, events, audit)
+}
+
+func (a *apartmentAdapter) Delete(ctx context.Context, id int, version int, events []*entity.Event, audit *entity.AuditEntry) (err error) {
+	defer observe(a.observer, apartmentStorage, "Delete", time.Now(), &err)
+	return a.next.Delete(ctx, id, version, events, audit)
+}
+
+func (a *apartmentAdapter) Restore(ctx context.Context, id int, events []*entity.Event, audit *entity.AuditEntry) (err error) {
+	defer observe(a.observer, apartmentStorage, "Restore", time.Now(), &err)
+	return a.next.Restore(ctx, id, events, audit)
+}
+
+func (a *apartmentAdapter) GetDeleted(ctx context.Context, page int, pageSize int) (apartments []*entity.Apartment, err error) {
+	defer observe(a.observer, apartmentStorage, "GetDeleted", time.Now(), &err)
+	return a.next.GetDeleted(ctx, page, pageSize)
+}
+
+func (a *apartmentAdapter) Purge(ctx context.Context, before time.Time) (ids []int, err error) {
+	defer observe(a.observer, apartmentStorage, "Purge", time.Now(), &err)
+	return a.next.Purge(ctx, before)
+}
+
+func (a *apartmentAdapter) CountPublished(ctx context.Context) (counts []*entity.ListingCount, err error) {
+	defer observe(a.observer, apartmentStorage, "CountPublished", time.Now(), &err)
+	return a.next.CountPublished(ctx)
+}
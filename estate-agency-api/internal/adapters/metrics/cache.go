@@ -0,0 +1,50 @@
+package adapterMetrics
+
+import (
+	"context"
+	"errors"
+
+	"gilab.com/estate-agency-api/internal/entity"
+	"gilab.com/estate-agency-api/internal/metrics"
+	"github.com/go-redis/cache/v9"
+)
+
+const apartmentCache = "apartment"
+
+type ApartmentCache interface {
+	SetApartment(ctx context.Context, apartment *entity.Apartment, id int) error
+	GetApartment(ctx context.Context, id int) (*entity.Apartment, error)
+}
+
+type CacheObserver interface {
+	ObserveCache(cache string, result string)
+}
+
+// apartmentCacheAdapter counts hits and misses of the wrapped Redis apartment cache.
+type apartmentCacheAdapter struct {
+	next     ApartmentCache
+	observer CacheObserver
+}
+
+func NewApartmentCacheAdapter(next ApartmentCache, observer CacheObserver) *apartmentCacheAdapter {
+	return &apartmentCacheAdapter{next: next, observer: observer}
+}
+
+func (a *apartmentCacheAdapter) SetApartment(ctx context.Context, apartment *entity.Apartment, id int) error {
+	return a.next.SetApartment(ctx, apartment, id)
+}
+
+func (a *apartmentCacheAdapter) GetApartment(ctx context.Context, id int) (*entity.Apartment, error) {
+	apartment, err := a.next.GetApartment(ctx, id)
+
+	switch {
+	case err == nil:
+		a.observer.ObserveCache(apartmentCache, metrics.CacheHit)
+	case errors.Is(err, cache.ErrCacheMiss):
+		a.observer.ObserveCache(apartmentCache, metrics.CacheMiss)
+	default:
+		a.observer.ObserveCache(apartmentCache, metrics.CacheError)
+	}
+
+	return apartment, err
+}
@@ -0,0 +1,17 @@
+package adapterMetrics
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+type Observer interface {
+	ObserveStorage(storage string, method string, duration time.Duration, failed bool)
+}
+
+// observe reports a storage call that started at start. It is deferred with a pointer to the
+// named error result, so it sees the error the call returned. A missing row is not a failure.
+func observe(observer Observer, storage string, method string, start time.Time, err *error) {
+	observer.ObserveStorage(storage, method, time.Since(start), *err != nil && !errors.Is(*err, sql.ErrNoRows))
+}
@@ -0,0 +1,66 @@
+package adapterMetrics
+
+import (
+	"context"
+	"time"
+
+	"gilab.com/estate-agency-api/internal/domain/service"
+	"gilab.com/estate-agency-api/internal/entity"
+)
+
+const realtorStorage = "realtor"
+
+// realtorAdapter times every call of the wrapped realtor storage.
+type realtorAdapter struct {
+	next     service.RealtorStorage
+	observer Observer
+}
+
+func NewRealtorAdapter(next service.RealtorStorage, observer Observer) *realtorAdapter {
+	return &realtorAdapter{next: next, observer: observer}
+}
+
+func (a *realtorAdapter) GetAll(ctx context.Context, page int, pageSize int) (realtors []*entity.Realtor, err error) {
+	defer observe(a.observer, realtorStorage, "GetAll", time.Now(), &err)
+	return a.next.GetAll(ctx, page, pageSize)
+}
+
+func (a *realtorAdapter) Each(ctx context.Context, fn func(realtor *entity.Realtor) error) (err error) {
+	defer observe(a.observer, realtorStorage, "Each", time.Now(), &err)
+	return a.next.Each(ctx, fn)
+}
+
+func (a *realtorAdapter) GetByID(ctx context.Context, id int) (realtor *entity.Realtor, err error) {
+	defer observe(a.observer, realtorStorage, "GetByID", time.Now(), &err)
+	return a.next.GetByID(ctx, id)
+}
+
+func (a *realtorAdapter) Create(ctx context.Context, realtor *entity.Realtor, events []*entity.Event, audit *entity.AuditEntry) (id int64, err error) {
+	defer observe(a.observer, realtorStorage, "Create", time.Now(), &err)
+	return a.next.Create(ctx, realtor, events, audit)
+}
+
+func (a *realtorAdapter) UpdateFields(ctx context.Context, id int, version int, fields map[string]any, events []*entity.Event, audit *entity.AuditEntry) (aff int64, err error) {
+	defer observe(a.observer, realtorStorage, "UpdateFields", time.Now(), &err)
+	return a.next.UpdateFields(ctx, id, version, fields, events, audit)
+}
+
+func (a *realtorAdapter) Delete(ctx context.Context, id int, version int, events []*entity.Event, audit *entity.AuditEntry) (err error) {
+	defer observe(a.observer, realtorStorage, "Delete", time.Now(), &err)
+	return a.next.Delete(ctx, id, version, events, audit)
+}
+
+func (a *realtorAdapter) Restore(ctx context.Context, id int, events []*entity.Event, audit *entity.AuditEntry) (err error) {
+	defer observe(a.observer, realtorStorage, "Restore", time.Now(), &err)
+	return a.next.Restore(ctx, id, events, audit)
+}
+
+func (a *realtorAdapter) GetDeleted(ctx context.Context, page int, pageSize int) (realtors []*entity.Realtor, err error) {
+	defer observe(a.observer, realtorStorage, "GetDeleted", time.Now(), &err)
+	return a.next.GetDeleted(ctx, page, pageSize)
+}
+
+func (a *realtorAdapter) Purge(ctx context.Context, before time.Time) (ids []int, err error) {
+	defer observe(a.observer, realtorStorage, "Purge", time.Now(), &err)
+	return a.next.Purge(ctx, before)
+}
@@ -0,0 +1,28 @@
+package adapterNotifier
+
+import (
+	"context"
+	"log/slog"
+
+	"gilab.com/estate-agency-api/internal/entity"
+)
+
+type logNotifier struct {
+	logger *slog.Logger
+}
+
+func NewLogNotifier(logger *slog.Logger) *logNotifier {
+	return &logNotifier{logger: logger}
+}
+
+func (l *logNotifier) Notify(ctx context.Context, n *entity.Notification) error {
+	l.logger.Info("notification",
+		slog.Int64("id", n.ID),
+		slog.String("kind", n.Kind),
+		slog.String("recipient", n.Recipient),
+		slog.String("subject", n.Subject),
+		slog.String("body", n.Body),
+	)
+
+	return nil
+}
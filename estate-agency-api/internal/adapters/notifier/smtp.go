@@ -0,0 +1,97 @@
+package adapterNotifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+
+	"gilab.com/estate-agency-api/internal/entity"
+)
+
+type smtpNotifier struct {
+	addr     string
+	host     string
+	username string
+	password string
+	from     string
+}
+
+func NewSMTPNotifier(host string, port int, username string, password string, from string) *smtpNotifier {
+	return &smtpNotifier{addr: net.JoinHostPort(host, strconv.Itoa(port)), host: host, username: username, password: password, from: from}
+}
+
+// Notify sends the notification as a plain text e-mail. STARTTLS and authentication are used
+// when the server offers them, so a local test server without TLS works as well.
+func (s *smtpNotifier) Notify(ctx context.Context, n *entity.Notification) error {
+	const op = "adapterNotifier.smtp.Notify"
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, s.host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err = client.StartTLS(&tls.Config{ServerName: s.host}); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	if ok, _ := client.Extension("AUTH"); ok && len(s.username) != 0 {
+		if err = client.Auth(smtp.PlainAuth("", s.username, s.password, s.host)); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err = client.Mail(s.from); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err = client.Rcpt(n.Recipient); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if _, err = w.Write(s.message(n)); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err = w.Close(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return client.Quit()
+}
+
+func (s *smtpNotifier) message(n *entity.Notification) []byte {
+	var msg bytes.Buffer
+
+	fmt.Fprintf(&msg, "From: %s\r\n", s.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", n.Recipient)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", n.Subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	msg.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(n.Body)
+	msg.WriteString("\r\n")
+
+	return msg.Bytes()
+}
@@ -0,0 +1,224 @@
+package adapterNotifier
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"mime"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"gilab.com/estate-agency-api/internal/entity"
+)
+
+// smtpSession is what the fake server received over one connection.
+type smtpSession struct {
+	auth string
+	from string
+	rcpt string
+	data string
+}
+
+// fakeSMTP is an SMTP server speaking just enough of the protocol for net/smtp, without TLS.
+type fakeSMTP struct {
+	listener   net.Listener
+	offerAuth  bool
+	rejectAuth bool
+	rejectRcpt bool
+	silent     bool
+	sessions   chan smtpSession
+}
+
+func newFakeSMTP(t *testing.T, server fakeSMTP) *fakeSMTP {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.listener = listener
+	server.sessions = make(chan smtpSession, 1)
+	t.Cleanup(func() { listener.Close() })
+
+	go server.serve()
+	return &server
+}
+
+func (s *fakeSMTP) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *fakeSMTP) serve() {
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	var session smtpSession
+	defer func() { s.sessions <- session }()
+
+	if s.silent {
+		io.Copy(io.Discard, conn)
+		return
+	}
+
+	c := textproto.NewConn(conn)
+	c.PrintfLine("220 localhost ESMTP fake")
+	for {
+		line, err := c.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+
+		switch strings.ToUpper(verb) {
+		case "EHLO":
+			if s.offerAuth {
+				c.PrintfLine("250-localhost")
+				c.PrintfLine("250 AUTH PLAIN")
+			} else {
+				c.PrintfLine("250 localhost")
+			}
+		case "AUTH":
+			session.auth = arg
+			if s.rejectAuth {
+				c.PrintfLine("535 5.7.8 authentication failed")
+			} else {
+				c.PrintfLine("235 2.7.0 accepted")
+			}
+		case "MAIL":
+			session.from = arg
+			c.PrintfLine("250 2.1.0 ok")
+		case "RCPT":
+			session.rcpt = arg
+			if s.rejectRcpt {
+				c.PrintfLine("550 5.1.1 no such mailbox")
+			} else {
+				c.PrintfLine("250 2.1.5 ok")
+			}
+		case "DATA":
+			c.PrintfLine("354 go ahead")
+			data, err := io.ReadAll(c.DotReader())
+			if err != nil {
+				return
+			}
+			session.data = string(data)
+			c.PrintfLine("250 2.0.0 queued")
+		case "QUIT":
+			c.PrintfLine("221 2.0.0 bye")
+			return
+		default:
+			c.PrintfLine("250 ok")
+		}
+	}
+}
+
+func TestSMTPNotify(t *testing.T) {
+	notification := &entity.Notification{
+		Recipient: "client@example.com",
+		Subject:   "Новая квартира",
+		Body:      "A new apartment matches your search.\r\n.a line starting with a dot",
+	}
+
+	plain := "PLAIN " + base64.StdEncoding.EncodeToString([]byte("\x00agency\x00secret"))
+
+	tests := []struct {
+		name     string
+		server   fakeSMTP
+		username string
+		timeout  time.Duration
+		wantErr  bool
+		wantAuth string
+	}{
+		{name: "delivered", server: fakeSMTP{}},
+		{name: "delivered without auth offered", server: fakeSMTP{}, username: "agency"},
+		{name: "authenticated", server: fakeSMTP{offerAuth: true}, username: "agency", wantAuth: plain},
+		{name: "auth offered without credentials", server: fakeSMTP{offerAuth: true}},
+		{name: "credentials rejected", server: fakeSMTP{offerAuth: true, rejectAuth: true}, username: "agency", wantErr: true, wantAuth: plain},
+		{name: "recipient rejected", server: fakeSMTP{rejectRcpt: true}, wantErr: true},
+		{name: "server not answering", server: fakeSMTP{silent: true}, timeout: 200 * time.Millisecond, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakeSMTP(t, tt.server)
+			n := NewSMTPNotifier("127.0.0.1", server.port(), tt.username, "secret", "noreply@agency.example")
+
+			ctx := context.Background()
+			if tt.timeout != 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+
+			err := n.Notify(ctx, notification)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Notify() error = %v, want error %v", err, tt.wantErr)
+			}
+
+			var session smtpSession
+			select {
+			case session = <-server.sessions:
+			case <-time.After(5 * time.Second):
+				t.Fatal("server did not finish the session")
+			}
+
+			if session.auth != tt.wantAuth {
+				t.Errorf("AUTH %q, want %q", session.auth, tt.wantAuth)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if session.from != "FROM:<noreply@agency.example>" || !strings.HasPrefix(session.rcpt, "TO:<client@example.com>") {
+				t.Errorf("envelope %q %q", session.from, session.rcpt)
+			}
+
+			msg, err := mail.ReadMessage(strings.NewReader(session.data))
+			if err != nil {
+				t.Fatalf("malformed message: %v\n%s", err, session.data)
+			}
+			subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+			if err != nil || subject != notification.Subject {
+				t.Errorf("Subject = %q, want %q", subject, notification.Subject)
+			}
+			if got := msg.Header.Get("To"); got != notification.Recipient {
+				t.Errorf("To = %q, want %q", got, notification.Recipient)
+			}
+			if got := msg.Header.Get("Content-Type"); got != "text/plain; charset=utf-8" {
+				t.Errorf("Content-Type = %q", got)
+			}
+			if _, err = msg.Header.Date(); err != nil {
+				t.Errorf("Date: %v", err)
+			}
+			body, _ := io.ReadAll(msg.Body)
+			if got := strings.TrimSuffix(strings.ReplaceAll(string(body), "\r\n", "\n"), "\n"); got != strings.ReplaceAll(notification.Body, "\r\n", "\n") {
+				t.Errorf("body = %q, want %q", got, notification.Body)
+			}
+		})
+	}
+}
+
+func TestNewSMTPNotifierAddress(t *testing.T) {
+	tests := []struct {
+		host string
+		port int
+		want string
+	}{
+		{host: "smtp.example.com", port: 587, want: "smtp.example.com:587"},
+		{host: "::1", port: 25, want: "[::1]:25"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := NewSMTPNotifier(tt.host, tt.port, "", "", "").addr; got != tt.want {
+				t.Errorf("addr = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package adapterWebhook
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gilab.com/estate-agency-api/pkg/webhook"
+)
+
+func TestSend(t *testing.T) {
+	const secret = "s3cret"
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if err = webhook.Verify(secret, r.Header.Get(webhook.TimestampHeader), r.Header.Get(webhook.SignatureHeader), body, time.Minute); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get(webhook.EventHeader) == "Fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer receiver.Close()
+
+	body := []byte(`{"id":1}`)
+	tests := []struct {
+		name   string
+		secret string
+		event  string
+		want   int
+	}{
+		{name: "verified", secret: secret, event: "ApartmentCreated", want: http.StatusOK},
+		{name: "signed with another secret", secret: "other", event: "ApartmentCreated", want: http.StatusUnauthorized},
+		{name: "receiver failing", secret: secret, event: "Fail", want: http.StatusInternalServerError},
+	}
+
+	// The receiver listens on loopback, which the client of NewClient refuses, see TestClientRefusesPrivateAddresses.
+	s := NewSender(receiver.Client())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Now().Unix()
+			headers := map[string]string{
+				"Content-Type":          "application/json",
+				webhook.EventHeader:     tt.event,
+				webhook.TimestampHeader: strconv.FormatInt(now, 10),
+				webhook.SignatureHeader: webhook.Sign(tt.secret, now, body),
+			}
+
+			status, err := s.Send(context.Background(), receiver.URL, headers, body)
+			if err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+			if status != tt.want {
+				t.Errorf("Send() = %d, want %d", status, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientRefusesPrivateAddresses(t *testing.T) {
+	var reached atomic.Bool
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached.Store(true)
+	}))
+	defer receiver.Close()
+
+	_, port, err := net.SplitHostPort(receiver.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		url  string
+	}{
+		{name: "loopback", url: receiver.URL},
+		{name: "localhost", url: "http://localhost:" + port},
+		{name: "unspecified address", url: "http://0.0.0.0:" + port},
+	}
+
+	s := NewSender(NewClient(time.Second))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.Send(context.Background(), tt.url, nil, []byte(`{}`)); err == nil {
+				t.Errorf("Send(%s) succeeded", tt.url)
+			}
+			if reached.Load() {
+				t.Errorf("Send(%s) reached the receiver", tt.url)
+			}
+		})
+	}
+}
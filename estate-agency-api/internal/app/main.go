@@ -5,42 +5,159 @@ import (
 	"database/sql"
 	"log/slog"
 	"net/http"
+	"time"
 
+	adapterBlob "gilab.com/estate-agency-api/internal/adapters/blob"
+	adapterRedis "gilab.com/estate-agency-api/internal/adapters/cache/redis"
 	adapterSql "gilab.com/estate-agency-api/internal/adapters/database/sql"
+	adapterEvents "gilab.com/estate-agency-api/internal/adapters/events"
+	adapterGeocoder "gilab.com/estate-agency-api/internal/adapters/geocoder"
+	adapterMemory "gilab.com/estate-agency-api/internal/adapters/memory"
+	adapterMetrics "gilab.com/estate-agency-api/internal/adapters/metrics"
+	adapterNotifier "gilab.com/estate-agency-api/internal/adapters/notifier"
+	adapterWebhook "gilab.com/estate-agency-api/internal/adapters/webhook"
 	"gilab.com/estate-agency-api/internal/config"
 	"gilab.com/estate-agency-api/internal/domain/service"
 	"gilab.com/estate-agency-api/internal/domain/usecase"
+	"gilab.com/estate-agency-api/internal/entity"
+	"gilab.com/estate-agency-api/internal/health"
+	"gilab.com/estate-agency-api/internal/metrics"
+	"gilab.com/estate-agency-api/internal/storage/cache/redis"
 	"gilab.com/estate-agency-api/internal/storage/database/mysql"
+	"gilab.com/estate-agency-api/internal/tracing"
 	"gilab.com/estate-agency-api/internal/transport/http/handler"
 	"gilab.com/estate-agency-api/internal/transport/http/middleware/auth"
+	"gilab.com/estate-agency-api/internal/transport/http/middleware/idempotency"
+	httpLogging "gilab.com/estate-agency-api/internal/transport/http/middleware/logging"
+	httpMetrics "gilab.com/estate-agency-api/internal/transport/http/middleware/metrics"
+	"gilab.com/estate-agency-api/internal/transport/http/middleware/ratelimit"
+	"gilab.com/estate-agency-api/internal/transport/http/middleware/requestid"
+	"gilab.com/estate-agency-api/internal/transport/http/middleware/tenant"
+	"gilab.com/estate-agency-api/internal/worker"
+	"gilab.com/estate-agency-api/pkg/reqctx"
 	"github.com/gin-gonic/gin"
+	goredis "github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
+type Worker interface {
+	Start()
+	Stop()
+}
+
 type app struct {
 	cfg *config.Config
 
-	server *http.Server
-	db     *sql.DB
+	server  *http.Server
+	db      *sql.DB
+	workers []Worker
+
+	rateLimiter     *ratelimit.RateLimiter
+	shutdownTracing func(ctx context.Context) error
 
 	logger *slog.Logger
 }
 
 func New(cfg *config.Config, logger *slog.Logger) *app {
 
+	logger.Info("Set up tracing", slog.String("exporter", cfg.TracingConfig.Exporter))
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		ServiceName:  cfg.TracingConfig.ServiceName,
+		Exporter:     cfg.TracingConfig.Exporter,
+		OTLPEndpoint: cfg.TracingConfig.OTLPEndpoint,
+		OTLPInsecure: cfg.TracingConfig.OTLPInsecure,
+		SampleRatio:  cfg.TracingConfig.SampleRatio,
+	})
+	if err != nil {
+		panic(err)
+	}
+
 	logger.Info("Connect to db")
 	db, err := mysql.New(&cfg.StorageConfig)
 	if err != nil {
 		panic(err)
 	}
 
+	metrics := metrics.New()
+	metrics.RegisterDB("mysql", db)
+
+	imageAdapter := adapterBlob.NewImageAdapter(cfg.ImagesPath)
+
+	geocoder := adapterGeocoder.NewGazetteer()
+	if len(cfg.GeocoderConfig.Gazetteer) != 0 {
+		logger.Info("Load gazetteer", slog.String("path", cfg.GeocoderConfig.Gazetteer))
+		geocoder, err = adapterGeocoder.LoadGazetteer(cfg.GeocoderConfig.Gazetteer)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	monitor := health.New(cfg.HealthConfig.Timeout, logger)
+	monitor.Add("mysql", db.PingContext)
+	monitor.Add("blob", imageAdapter.Ping)
+	var redisClient *goredis.Client
+	if len(cfg.CacheConfig.Addr) != 0 {
+		redisClient = redis.NewClient(&cfg.CacheConfig)
+		monitor.Add("redis", func(ctx context.Context) error {
+			return redisClient.Ping(ctx).Err()
+		})
+	}
+
 	logger.Info("Create router")
 	router := gin.New()
+	if err = router.SetTrustedProxies(cfg.HTTPServerConfig.TrustedProxies); err != nil {
+		panic(err)
+	}
 
-	router.Use(gin.Recovery(), gin.Logger())
-	router.Use(auth.BasicAuth(cfg.HTTPServerConfig.User, cfg.HTTPServerConfig.Password))
+	router.Use(otelgin.Middleware(cfg.TracingConfig.ServiceName), requestid.RequestID(), httpLogging.Logging(logger), httpLogging.Recovery(logger), httpMetrics.Metrics(metrics))
+	router.GET(cfg.MetricsConfig.Path, gin.WrapH(metrics.Handler()))
+	healthHandler := handler.NewHealthHandler(monitor, logger)
+	healthHandler.Register(router)
 
 	logger.Info("Set routes")
-	usecase := usecase.NewUsecase(service.NewApartmentService(adapterSql.NewApartmentAdapter(db)), service.NewRealtorService(adapterSql.NewRealtorAdapter(db)))
+	apartmentAdapter := adapterMetrics.NewApartmentAdapter(adapterSql.NewApartmentAdapter(db), metrics)
+	realtorAdapter := adapterMetrics.NewRealtorAdapter(adapterSql.NewRealtorAdapter(db), metrics)
+	feedService := service.NewFeedService(apartmentAdapter, realtorAdapter, imageAdapter, cfg.FeedConfig.PhotoBaseURL, cfg.FeedConfig.Country)
+	webhookSender := adapterWebhook.NewSender(adapterWebhook.NewClient(cfg.WebhookConfig.Timeout))
+	streamService := service.NewStreamService(cfg.StreamConfig.BufferSize)
+
+	var notifier service.Notifier = adapterNotifier.NewLogNotifier(logger.With(slog.String("notifier", "log")))
+	if cfg.NotifierConfig.Type == "smtp" {
+		smtp := cfg.NotifierConfig.SMTPConfig
+		notifier = adapterNotifier.NewSMTPNotifier(smtp.Host, smtp.Port, smtp.Username, smtp.Password, smtp.From)
+	}
+	notificationService := service.NewNotificationService(adapterSql.NewNotificationAdapter(db), notifier, cfg.NotifierConfig.MaxAttempts, cfg.NotifierConfig.RetryDelay, cfg.NotifierConfig.BatchSize)
+
+	clientAdapter := adapterSql.NewClientAdapter(db)
+	clientService := service.NewClientService(clientAdapter)
+	searchService := service.NewSearchService(adapterSql.NewSearchAdapter(db), clientAdapter, apartmentAdapter, notificationService)
+	leaseAdapter := adapterSql.NewLeaseAdapter(db)
+	rentService := service.NewRentService(adapterSql.NewRentAdapter(db), leaseAdapter, clientAdapter, notificationService, cfg.RentConfig.ReminderInterval, cfg.RentConfig.BatchSize)
+	apiKeyService := service.NewAPIKeyService(adapterSql.NewAPIKeyAdapter(db), realtorAdapter)
+	officeAdapter := adapterSql.NewOfficeAdapter(db)
+	teamService := service.NewTeamService(adapterSql.NewTeamAdapter(db), officeAdapter, realtorAdapter, apartmentAdapter)
+	webhookService := service.NewWebhookService(adapterSql.NewWebhookAdapter(db), webhookSender, cfg.WebhookConfig.Timeout, cfg.WebhookConfig.MaxAttempts, cfg.WebhookConfig.BackoffBase, cfg.WebhookConfig.BackoffMax, cfg.WebhookConfig.BatchSize)
+	usecase := usecase.NewUsecase(service.NewApartmentService(apartmentAdapter, imageAdapter, teamService, geocoder), service.NewRealtorService(realtorAdapter, imageAdapter), feedService, service.NewAuditService(adapterSql.NewAuditAdapter(db)), webhookService, streamService, clientService, searchService, service.NewFavoriteService(adapterSql.NewFavoriteAdapter(db), clientAdapter, apartmentAdapter), service.NewLeaseService(leaseAdapter, apartmentAdapter, clientAdapter), rentService, apiKeyService, service.NewAgencyService(adapterSql.NewAgencyAdapter(db)), service.NewOfficeService(officeAdapter), teamService)
+
+	var limiter ratelimit.Limiter = adapterMemory.NewRateLimitAdapter()
+	if cfg.RateLimitConfig.Backend == "redis" {
+		limiter = adapterRedis.NewRateLimitAdapter(redisClient)
+	}
+	rateLimiter := ratelimit.New(limiter, logger)
+	rateLimiter.SetRules(rateLimitRules(&cfg.RateLimitConfig))
+	router.Use(rateLimiter.IPMiddleware())
+
+	accounts, logins := httpAccounts(&cfg.HTTPServerConfig)
+	router.Use(auth.Auth(accounts, cfg.HTTPServerConfig.User, usecase, logger))
+	router.Use(rateLimiter.Middleware())
+	router.Use(tenant.Tenant(usecase, cfg.TenantConfig.Domain, cfg.TenantConfig.Default, logins, logger))
+
+	var idempotencyStorage service.IdempotencyStorage = adapterSql.NewIdempotencyAdapter(db)
+	if cfg.IdempotencyConfig.Storage == "memory" {
+		idempotencyStorage = adapterMemory.NewIdempotencyAdapter()
+	}
+	idempotencyService := service.NewIdempotencyService(idempotencyStorage, cfg.IdempotencyConfig.TTL)
+	router.Use(idempotency.Idempotency(idempotencyService, logger))
 
 	realtorHandler := handler.NewRealtorHandler(usecase, logger)
 	realtorHandler.Register(router)
@@ -48,6 +165,39 @@ func New(cfg *config.Config, logger *slog.Logger) *app {
 	apartmentHandler := handler.NewApartmentHandler(usecase, logger)
 	apartmentHandler.Register(router)
 
+	feedHandler := handler.NewFeedHandler(usecase, logger)
+	feedHandler.Register(router)
+
+	auditHandler := handler.NewAuditHandler(usecase, logger)
+	auditHandler.Register(router)
+
+	webhookHandler := handler.NewWebhookHandler(usecase, logger)
+	webhookHandler.Register(router)
+
+	streamHandler := handler.NewStreamHandler(usecase, cfg.StreamConfig.Heartbeat, logger)
+	streamHandler.Register(router)
+
+	clientHandler := handler.NewClientHandler(usecase, logger)
+	clientHandler.Register(router)
+
+	favoriteHandler := handler.NewFavoriteHandler(usecase, logger)
+	favoriteHandler.Register(router)
+
+	leaseHandler := handler.NewLeaseHandler(usecase, logger)
+	leaseHandler.Register(router)
+
+	apiKeyHandler := handler.NewAPIKeyHandler(usecase, logger)
+	apiKeyHandler.Register(router)
+
+	agencyHandler := handler.NewAgencyHandler(usecase, logger)
+	agencyHandler.Register(router)
+
+	officeHandler := handler.NewOfficeHandler(usecase, logger)
+	officeHandler.Register(router)
+
+	teamHandler := handler.NewTeamHandler(usecase, logger)
+	teamHandler.Register(router)
+
 	server := &http.Server{
 		Addr:         cfg.Address,
 		Handler:      router,
@@ -56,10 +206,128 @@ func New(cfg *config.Config, logger *slog.Logger) *app {
 		IdleTimeout:  cfg.HTTPServerConfig.IdleTimeout,
 	}
 
-	return &app{server: server, cfg: cfg, db: db, logger: logger}
+	// Storage is scoped to a single agency, so the background jobs run once for every agency.
+	purgeWorker := worker.New("purge", cfg.RetentionConfig.PurgeInterval, func(ctx context.Context) error {
+		return usecase.ForEachAgency(ctx, func(ctx context.Context) error {
+			apartments, realtors, err := usecase.PurgeDeleted(ctx, time.Now().Add(-cfg.RetentionConfig.Period))
+			if apartments+realtors > 0 {
+				agencyID, _ := reqctx.Tenant(ctx)
+				logger.Info("purged deleted rows", slog.Int("agency_id", agencyID), slog.Int("apartments", apartments), slog.Int("realtors", realtors))
+			}
+			return err
+		})
+	}, logger)
+
+	bus := adapterEvents.NewInProcessPublisher()
+	if cfg.EventsConfig.Publisher == "log" {
+		bus.Subscribe("log", adapterEvents.NewLogPublisher(logger.With(slog.String("publisher", "log"))).Publish)
+	}
+	bus.Subscribe("webhooks", webhookService.Enqueue)
+	bus.Subscribe("stream", streamService.Publish)
+	bus.Subscribe("saved_searches", searchService.Match)
+	outboxService := service.NewOutboxService(adapterSql.NewOutboxAdapter(db), bus, cfg.EventsConfig.BatchSize, cfg.EventsConfig.MaxAttempts)
+
+	relayWorker := worker.New("outbox-relay", cfg.EventsConfig.RelayInterval, func(ctx context.Context) error {
+		return usecase.ForEachAgency(ctx, func(ctx context.Context) error {
+			_, err := outboxService.Relay(ctx)
+			return err
+		})
+	}, logger)
+
+	outboxCleanWorker := worker.New("outbox-clean", cfg.RetentionConfig.PurgeInterval, func(ctx context.Context) error {
+		return usecase.ForEachAgency(ctx, func(ctx context.Context) error {
+			_, err := outboxService.Clean(ctx, time.Now().Add(-cfg.EventsConfig.Retention))
+			return err
+		})
+	}, logger)
+
+	// Idempotency keys belong to no agency, they are cleaned once for all of them.
+	idempotencyCleanWorker := worker.New("idempotency-clean", cfg.RetentionConfig.PurgeInterval, func(ctx context.Context) error {
+		_, err := idempotencyService.Clean(ctx)
+		return err
+	}, logger)
+
+	webhookWorker := worker.New("webhook-delivery", cfg.WebhookConfig.Interval, func(ctx context.Context) error {
+		return usecase.ForEachAgency(ctx, func(ctx context.Context) error {
+			_, err := webhookService.Deliver(ctx)
+			return err
+		})
+	}, logger)
+
+	notificationWorker := worker.New("notifications", cfg.NotifierConfig.Interval, func(ctx context.Context) error {
+		return usecase.ForEachAgency(ctx, func(ctx context.Context) error {
+			_, err := notificationService.Dispatch(ctx)
+			return err
+		})
+	}, logger)
+
+	rentWorker := worker.New("rent-overdue", cfg.RentConfig.CheckInterval, func(ctx context.Context) error {
+		return usecase.ForEachAgency(ctx, func(ctx context.Context) error {
+			reminded, err := rentService.CheckOverdue(ctx)
+			if reminded > 0 {
+				agencyID, _ := reqctx.Tenant(ctx)
+				logger.Info("queued rent reminders", slog.Int("agency_id", agencyID), slog.Int("count", reminded))
+			}
+			return err
+		})
+	}, logger)
+
+	healthWorker := worker.New("health", cfg.HealthConfig.Interval, monitor.Run, logger)
+
+	listingsWorker := worker.New("listings-metrics", cfg.MetricsConfig.RefreshInterval, func(ctx context.Context) error {
+		var counts []*entity.ListingCount
+		err := usecase.ForEachAgency(ctx, func(ctx context.Context) error {
+			agencyCounts, err := usecase.CountPublishedListings(ctx)
+			counts = append(counts, agencyCounts...)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		metrics.SetListings(counts)
+		return nil
+	}, logger)
+
+	return &app{server: server, cfg: cfg, db: db, workers: []Worker{purgeWorker, relayWorker, outboxCleanWorker, idempotencyCleanWorker, webhookWorker, notificationWorker, rentWorker, listingsWorker, healthWorker}, rateLimiter: rateLimiter, shutdownTracing: shutdownTracing, logger: logger}
+}
+
+// Reload applies the settings of cfg that can change without a restart, see config.Reloadable.
+func (app *app) Reload(cfg *config.Config) {
+	app.rateLimiter.SetRules(rateLimitRules(&cfg.RateLimitConfig))
+}
+
+// httpAccounts returns the passwords of the logins and the agencies of the logins bound to one. The login of
+// http_server.user is not bound, it belongs to the default agency and administers the platform.
+func httpAccounts(cfg *config.HTTPServerConfig) (accounts gin.Accounts, logins map[string]string) {
+	accounts = gin.Accounts{cfg.User: cfg.Password}
+	logins = make(map[string]string, len(cfg.Accounts))
+	for _, account := range cfg.Accounts {
+		accounts[account.User] = account.Password
+		logins[account.User] = account.Agency
+	}
+	return accounts, logins
+}
+
+func rateLimitRules(cfg *config.RateLimitConfig) (enabled bool, ip entity.RateLimit, def entity.RateLimit, rules []ratelimit.Rule) {
+	for _, route := range cfg.Routes {
+		rules = append(rules, ratelimit.Rule{Method: route.Method, Path: route.Path, Limit: rateLimit(route.Requests, route.Period, route.Burst)})
+	}
+	return cfg.Enabled, rateLimit(cfg.IP.Requests, cfg.IP.Period, cfg.IP.Burst), rateLimit(cfg.Default.Requests, cfg.Default.Period, cfg.Default.Burst), rules
+}
+
+// rateLimit turns requests per period into a token bucket, the burst defaults to the requests.
+func rateLimit(requests int, period time.Duration, burst int) entity.RateLimit {
+	if burst == 0 {
+		burst = requests
+	}
+	return entity.RateLimit{Rate: float64(requests) / period.Seconds(), Burst: burst}
 }
 
 func (app *app) Run() error {
+	for _, w := range app.workers {
+		w.Start()
+	}
+
 	return app.server.ListenAndServe()
 }
 
@@ -72,5 +340,13 @@ func (app *app) Shutdown() error {
 		return err
 	}
 
+	for _, w := range app.workers {
+		w.Stop()
+	}
+
+	if err = app.shutdownTracing(ctx); err != nil {
+		app.logger.Error("flush traces", "err", err.Error())
+	}
+
 	return app.db.Close()
 }
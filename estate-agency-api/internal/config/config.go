@@ -1,18 +1,34 @@
 package config
 
 import (
-	"log"
-	"sync"
 	"time"
 
-	"gilab.com/estate-agency-api/pkg/client/mysql"
-	"github.com/ilyakaznacheev/cleanenv"
+	"gilab.com/estate-agency-api/internal/storage/cache/redis"
+	"gilab.com/estate-agency-api/internal/storage/database/mysql"
 )
 
 type Config struct {
-	Env                 string `yaml:"env" env:"ENV" env-default:"local"`
+	Env                 string        `yaml:"env" env:"ENV" env-default:"local"`
+	TimeClose           time.Duration `yaml:"time_close" env:"TIME_CLOSE" env-default:"10s"`
+	ImagesPath          string        `yaml:"images_path" env:"IMAGES_PATH" env-default:"./../../internal/images"`
 	mysql.StorageConfig `yaml:"storage"`
+	redis.CacheConfig   `yaml:"redis"`
 	HTTPServerConfig    `yaml:"http_server"`
+	FeedConfig          `yaml:"feed"`
+	IdempotencyConfig   `yaml:"idempotency"`
+	RetentionConfig     `yaml:"retention"`
+	EventsConfig        `yaml:"events"`
+	WebhookConfig       `yaml:"webhook"`
+	StreamConfig        `yaml:"stream"`
+	NotifierConfig      `yaml:"notifier"`
+	RentConfig          `yaml:"rent"`
+	MetricsConfig       `yaml:"metrics"`
+	TracingConfig       `yaml:"tracing"`
+	HealthConfig        `yaml:"health"`
+	LoggerConfig        `yaml:"logger"`
+	RateLimitConfig     `yaml:"rate_limit"`
+	TenantConfig        `yaml:"tenant"`
+	GeocoderConfig      `yaml:"geocoder"`
 }
 
 type HTTPServerConfig struct {
@@ -21,20 +37,169 @@ type HTTPServerConfig struct {
 	IdleTimeout time.Duration `yaml:"idle_timeout" env:"HTTP_SERVER_IDLE_TIMEOUT" env-default:"60s"`
 	User        string        `yaml:"user" env:"HTTP_SERVER_USER" env-required:"true"`
 	Password    string        `yaml:"password" env:"HTTP_SERVER_PASSWORD" env-required:"true" `
+	// TrustedProxies may set X-Forwarded-For, the client IP of requests from other addresses is their own.
+	// Rate limits are applied per client IP.
+	TrustedProxies []string `yaml:"trusted_proxies" env:"HTTP_SERVER_TRUSTED_PROXIES"`
+	// Accounts are the password logins of other agencies, user and password above log in to the default agency.
+	// Only the login of user may manage the agencies of the platform.
+	Accounts []AccountConfig `yaml:"accounts"`
 }
 
-var instance *Config
-var once sync.Once
-
-func GetConfig() *Config {
-	once.Do(func() {
-		log.Println("Read application config")
-		instance = &Config{}
-		if err := cleanenv.ReadConfig("C:\\Users\\pynex\\Projects\\estate-agency-api\\internal\\config\\config.yml", instance); err != nil {
-			help, _ := cleanenv.GetDescription(instance, nil)
-			log.Println(help)
-			log.Fatal(err)
-		}
-	})
-	return instance
+// AccountConfig is a password login bound to the agency with the slug Agency.
+type AccountConfig struct {
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	Agency   string `yaml:"agency"`
+}
+
+// FeedConfig describes the listing feeds published to classified portals.
+// Photos are linked as <photo_base_url>/apartment/<id>.png and omitted when the base URL is empty.
+type FeedConfig struct {
+	PhotoBaseURL string `yaml:"photo_base_url" env:"FEED_PHOTO_BASE_URL"`
+	Country      string `yaml:"country" env:"FEED_COUNTRY" env-default:"Россия"`
+}
+
+type IdempotencyConfig struct {
+	Storage string        `yaml:"storage" env:"IDEMPOTENCY_STORAGE" env-default:"sql"`
+	TTL     time.Duration `yaml:"ttl" env:"IDEMPOTENCY_TTL" env-default:"24h"`
+}
+
+// RetentionConfig controls how long soft-deleted apartments and realtors are kept before they are purged with their photos.
+type RetentionConfig struct {
+	Period        time.Duration `yaml:"period" env:"RETENTION_PERIOD" env-default:"720h"`
+	PurgeInterval time.Duration `yaml:"purge_interval" env:"RETENTION_PURGE_INTERVAL" env-default:"1h"`
+}
+
+// EventsConfig describes the relay of domain events from the outbox table to the in-process subscribers.
+// With the "log" publisher every relayed event is also written to the log. A subscriber failing an event
+// max_attempts times gives up on it and its delivery is marked dead.
+type EventsConfig struct {
+	Publisher     string        `yaml:"publisher" env:"EVENTS_PUBLISHER" env-default:"log"`
+	RelayInterval time.Duration `yaml:"relay_interval" env:"EVENTS_RELAY_INTERVAL" env-default:"1s"`
+	BatchSize     int           `yaml:"batch_size" env:"EVENTS_BATCH_SIZE" env-default:"100"`
+	MaxAttempts   int           `yaml:"max_attempts" env:"EVENTS_MAX_ATTEMPTS" env-default:"10"`
+	Retention     time.Duration `yaml:"retention" env:"EVENTS_RETENTION" env-default:"168h"`
+}
+
+// WebhookConfig controls delivery of events to webhook subscriptions. A failed delivery is retried
+// after backoff_base, doubling up to backoff_max, and is marked dead after max_attempts.
+type WebhookConfig struct {
+	Timeout     time.Duration `yaml:"timeout" env:"WEBHOOK_TIMEOUT" env-default:"10s"`
+	MaxAttempts int           `yaml:"max_attempts" env:"WEBHOOK_MAX_ATTEMPTS" env-default:"8"`
+	BackoffBase time.Duration `yaml:"backoff_base" env:"WEBHOOK_BACKOFF_BASE" env-default:"30s"`
+	BackoffMax  time.Duration `yaml:"backoff_max" env:"WEBHOOK_BACKOFF_MAX" env-default:"6h"`
+	Interval    time.Duration `yaml:"interval" env:"WEBHOOK_INTERVAL" env-default:"5s"`
+	BatchSize   int           `yaml:"batch_size" env:"WEBHOOK_BATCH_SIZE" env-default:"50"`
+}
+
+// StreamConfig describes the Server-Sent Events stream of apartment changes. buffer_size events are kept for Last-Event-ID resumption.
+type StreamConfig struct {
+	BufferSize int           `yaml:"buffer_size" env:"STREAM_BUFFER_SIZE" env-default:"1000"`
+	Heartbeat  time.Duration `yaml:"heartbeat" env:"STREAM_HEARTBEAT" env-default:"15s"`
+}
+
+// NotifierConfig selects how client notifications are sent ("log" or "smtp") and how failed sends are retried.
+type NotifierConfig struct {
+	Type        string        `yaml:"type" env:"NOTIFIER_TYPE" env-default:"log"`
+	MaxAttempts int           `yaml:"max_attempts" env:"NOTIFIER_MAX_ATTEMPTS" env-default:"5"`
+	RetryDelay  time.Duration `yaml:"retry_delay" env:"NOTIFIER_RETRY_DELAY" env-default:"5m"`
+	Interval    time.Duration `yaml:"interval" env:"NOTIFIER_INTERVAL" env-default:"10s"`
+	BatchSize   int           `yaml:"batch_size" env:"NOTIFIER_BATCH_SIZE" env-default:"50"`
+	SMTPConfig  `yaml:"smtp"`
+}
+
+type SMTPConfig struct {
+	Host     string `yaml:"host" env:"SMTP_HOST" env-default:"localhost"`
+	Port     int    `yaml:"port" env:"SMTP_PORT" env-default:"25"`
+	Username string `yaml:"username" env:"SMTP_USERNAME"`
+	Password string `yaml:"password" env:"SMTP_PASSWORD"`
+	From     string `yaml:"from" env:"SMTP_FROM" env-default:"noreply@localhost"`
+}
+
+// RentConfig controls the daily overdue check of rent payments. Tenants are reminded of an overdue
+// charge again once reminder_interval has passed since the previous reminder.
+type RentConfig struct {
+	CheckInterval    time.Duration `yaml:"check_interval" env:"RENT_CHECK_INTERVAL" env-default:"24h"`
+	ReminderInterval time.Duration `yaml:"reminder_interval" env:"RENT_REMINDER_INTERVAL" env-default:"168h"`
+	BatchSize        int           `yaml:"batch_size" env:"RENT_BATCH_SIZE" env-default:"100"`
+}
+
+// MetricsConfig describes the Prometheus endpoint. It is served without basic auth so that
+// scrapers need no credentials; business gauges are recomputed every refresh_interval.
+type MetricsConfig struct {
+	Path            string        `yaml:"path" env:"METRICS_PATH" env-default:"/metrics"`
+	RefreshInterval time.Duration `yaml:"refresh_interval" env:"METRICS_REFRESH_INTERVAL" env-default:"1m"`
+}
+
+// TracingConfig describes the OpenTelemetry exporter: "none" disables tracing, "stdout" prints spans
+// and "otlp" sends them over HTTP to otlp_endpoint. Requests carrying a sampled traceparent are always traced.
+type TracingConfig struct {
+	Exporter     string  `yaml:"exporter" env:"TRACING_EXPORTER" env-default:"none"`
+	ServiceName  string  `yaml:"service_name" env:"TRACING_SERVICE_NAME" env-default:"estate-agency-api"`
+	OTLPEndpoint string  `yaml:"otlp_endpoint" env:"TRACING_OTLP_ENDPOINT" env-default:"localhost:4318"`
+	OTLPInsecure bool    `yaml:"otlp_insecure" env:"TRACING_OTLP_INSECURE" env-default:"false"`
+	SampleRatio  float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO" env-default:"1"`
+}
+
+// HealthConfig describes the background dependency checks behind /readyz. A dependency that does not
+// answer within timeout is reported as down until the next check.
+type HealthConfig struct {
+	Interval time.Duration `yaml:"interval" env:"HEALTH_INTERVAL" env-default:"10s"`
+	Timeout  time.Duration `yaml:"timeout" env:"HEALTH_TIMEOUT" env-default:"2s"`
+}
+
+// LoggerConfig describes the application log: level is one of debug, info, warn or error
+// and format is text or json.
+type LoggerConfig struct {
+	Level  string `yaml:"level" env:"LOG_LEVEL" env-default:"info"`
+	Format string `yaml:"format" env:"LOG_FORMAT" env-default:"text"`
+}
+
+// RateLimitConfig describes the token buckets applied to every client: a client may send burst requests at once
+// and requests per period on average. Clients are told apart by API key or login, the first route rule matching
+// a request wins and the default applies otherwise. IP limits every address before the credentials are checked,
+// it must leave room for the clients sharing an address.
+// The memory backend limits each instance separately, the redis backend shares the limits between instances.
+type RateLimitConfig struct {
+	Enabled bool             `yaml:"enabled" env:"RATE_LIMIT_ENABLED"`
+	Backend string           `yaml:"backend" env:"RATE_LIMIT_BACKEND" env-default:"memory"`
+	IP      IPRateLimit      `yaml:"ip"`
+	Default RateLimit        `yaml:"default"`
+	Routes  []RouteRateLimit `yaml:"routes"`
+}
+
+type IPRateLimit struct {
+	Requests int           `yaml:"requests" env:"RATE_LIMIT_IP_REQUESTS" env-default:"3000"`
+	Period   time.Duration `yaml:"period" env:"RATE_LIMIT_IP_PERIOD" env-default:"1m"`
+	Burst    int           `yaml:"burst" env:"RATE_LIMIT_IP_BURST"`
+}
+
+type RateLimit struct {
+	Requests int           `yaml:"requests" env:"RATE_LIMIT_REQUESTS" env-default:"600"`
+	Period   time.Duration `yaml:"period" env:"RATE_LIMIT_PERIOD" env-default:"1m"`
+	Burst    int           `yaml:"burst" env:"RATE_LIMIT_BURST"`
+}
+
+// RouteRateLimit limits a route template such as /apartments/:apartment_id, or every route starting
+// with path when it ends with *. An empty method matches any method. Burst defaults to requests.
+type RouteRateLimit struct {
+	Method   string        `yaml:"method"`
+	Path     string        `yaml:"path"`
+	Requests int           `yaml:"requests"`
+	Period   time.Duration `yaml:"period"`
+	Burst    int           `yaml:"burst"`
+}
+
+// TenantConfig describes how a request is matched to its agency. Requests belong to the agency of their
+// credentials: the agency of the API key, the agency of the account or, for http_server.user, the default agency.
+// A request to <slug>.<domain> of another agency is rejected.
+type TenantConfig struct {
+	Domain  string `yaml:"domain" env:"TENANT_DOMAIN"`
+	Default string `yaml:"default" env:"TENANT_DEFAULT" env-default:"default"`
+}
+
+// GeocoderConfig points to the local gazetteer the addresses of apartments are geocoded against, a CSV file
+// or an OSM XML extract. Without it addresses are only normalized.
+type GeocoderConfig struct {
+	Gazetteer string `yaml:"gazetteer" env:"GEOCODER_GAZETTEER"`
 }
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ilyakaznacheev/cleanenv"
+)
+
+const (
+	EnvLocal = "local"
+	EnvDev   = "dev"
+	EnvProd  = "prod"
+)
+
+// DefaultPath is used when neither the --config flag nor CONFIG_PATH is set.
+const DefaultPath = "config/config.yml"
+
+// Path resolves the config file: the --config flag wins over the CONFIG_PATH environment variable.
+func Path(flagValue string) string {
+	if len(flagValue) != 0 {
+		return flagValue
+	}
+	if path, ok := os.LookupEnv("CONFIG_PATH"); ok && len(path) != 0 {
+		return path
+	}
+	return DefaultPath
+}
+
+// Load reads the base config file and layers over it, in order, the profile file next to it
+// (config.<env>.yml for config.yml), the environment variables and the env-default tag values
+// of the fields still unset. The profile is taken from ENV, falling back to env in the base file.
+// A missing profile file is not an error.
+func Load(path string) (*Config, error) {
+	const op = "config.Load"
+
+	cfg := &Config{}
+	if err := parseFile(path, cfg); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	profile := cfg.Env
+	if env, ok := os.LookupEnv("ENV"); ok && len(env) != 0 {
+		profile = env
+	}
+	if len(profile) != 0 {
+		err := parseFile(profilePath(path, profile), cfg)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err := cleanenv.ReadEnv(cfg); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: invalid config %s:\n%w", op, path, err)
+	}
+
+	return cfg, nil
+}
+
+// profilePath turns config/config.yml into config/config.prod.yml.
+func profilePath(path string, profile string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + profile + ext
+}
+
+func parseFile(path string, cfg *Config) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// An empty profile file is allowed.
+	if err = cleanenv.ParseYAML(f, cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	return nil
+}
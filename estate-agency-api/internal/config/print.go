@@ -0,0 +1,225 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const redacted = "[REDACTED]"
+
+// secretKeys are matched against the last segment of a setting's yaml path. storage.storage_path
+// is a DSN and carries the database password.
+var secretKeys = []string{"password", "secret", "token"}
+
+// reloadableKeys are the settings applied on SIGHUP without a restart, a trailing dot covers a whole section.
+var reloadableKeys = []string{"logger.level", "rate_limit.enabled", "rate_limit.ip.", "rate_limit.default.", "rate_limit.routes"}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Marshal renders the config as YAML in the layout of the config file, with durations as strings.
+func Marshal(c *Config) ([]byte, error) {
+	node, err := yamlNode(reflect.ValueOf(c).Elem())
+	if err != nil {
+		return nil, fmt.Errorf("config.Marshal: %w", err)
+	}
+
+	var b bytes.Buffer
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err = enc.Encode(node); err != nil {
+		return nil, fmt.Errorf("config.Marshal: %w", err)
+	}
+	return b.Bytes(), enc.Close()
+}
+
+// Redacted returns a copy of the config with passwords and other secrets replaced. The copy shares no slices or
+// maps with the config, so the secrets of the running config are left alone.
+func (c *Config) Redacted() *Config {
+	cp := *c
+	detach(reflect.ValueOf(&cp).Elem())
+	walk(reflect.ValueOf(&cp).Elem(), "", func(key string, v reflect.Value) {
+		if v.Kind() == reflect.String && v.Len() != 0 && isSecret(key) {
+			v.SetString(redacted)
+		}
+	})
+	return &cp
+}
+
+// Diff lists the yaml paths of the settings whose values differ, including the ones of list entries that were
+// added or removed.
+func Diff(old *Config, new *Config) []string {
+	var keys []string
+	values := make(map[string]any)
+	walk(reflect.ValueOf(old).Elem(), "", func(key string, v reflect.Value) {
+		keys = append(keys, key)
+		values[key] = v.Interface()
+	})
+
+	var changed []string
+	seen := make(map[string]bool, len(keys))
+	walk(reflect.ValueOf(new).Elem(), "", func(key string, v reflect.Value) {
+		seen[key] = true
+		if old, ok := values[key]; !ok || !reflect.DeepEqual(old, v.Interface()) {
+			changed = append(changed, key)
+		}
+	})
+	for _, key := range keys {
+		if !seen[key] {
+			changed = append(changed, key)
+		}
+	}
+	return changed
+}
+
+// Reloadable reports whether the setting with the given yaml path can change without a restart. The entries of
+// a reloadable list are reloadable as well.
+func Reloadable(key string) bool {
+	for _, k := range reloadableKeys {
+		if key == k || strings.HasSuffix(k, ".") && strings.HasPrefix(key, k) || strings.HasPrefix(key, k+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplyReloadable copies the reloadable settings of next into the config.
+func (c *Config) ApplyReloadable(next *Config) {
+	c.LoggerConfig.Level = next.LoggerConfig.Level
+	c.RateLimitConfig.Enabled = next.RateLimitConfig.Enabled
+	c.RateLimitConfig.IP = next.RateLimitConfig.IP
+	c.RateLimitConfig.Default = next.RateLimitConfig.Default
+	c.RateLimitConfig.Routes = next.RateLimitConfig.Routes
+}
+
+func isSecret(key string) bool {
+	if key == "storage.storage_path" {
+		return true
+	}
+	last := key[strings.LastIndex(key, ".")+1:]
+	for _, secret := range secretKeys {
+		if strings.Contains(last, secret) {
+			return true
+		}
+	}
+	return false
+}
+
+func yamlKey(field reflect.StructField) string {
+	tag := strings.Split(field.Tag.Get("yaml"), ",")[0]
+	if len(tag) == 0 {
+		return strings.ToLower(field.Name)
+	}
+	return tag
+}
+
+// walk calls fn with the yaml path of every leaf setting, descending into nested sections and into the entries
+// of lists and maps of sections, as in http_server.accounts[0].password. Changes fn makes to map entries are
+// stored back in the map.
+func walk(v reflect.Value, prefix string, fn func(key string, v reflect.Value)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() || field.Tag.Get("yaml") == "-" {
+			continue
+		}
+		walkValue(v.Field(i), prefix+yamlKey(field), fn)
+	}
+}
+
+func walkValue(v reflect.Value, key string, fn func(key string, v reflect.Value)) {
+	switch {
+	case v.Kind() == reflect.Struct:
+		walk(v, key+".", fn)
+	case (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && v.Type().Elem().Kind() == reflect.Struct:
+		for i := 0; i < v.Len(); i++ {
+			walkValue(v.Index(i), fmt.Sprintf("%s[%d]", key, i), fn)
+		}
+	case v.Kind() == reflect.Map && v.Type().Elem().Kind() == reflect.Struct:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j]) })
+		for _, k := range keys {
+			entry := reflect.New(v.Type().Elem()).Elem()
+			entry.Set(v.MapIndex(k))
+			walkValue(entry, fmt.Sprintf("%s.%v", key, k), fn)
+			v.SetMapIndex(k, entry)
+		}
+	default:
+		fn(key, v)
+	}
+}
+
+// detach replaces the slices and maps reachable from v with copies, so that v can be changed without changing
+// the value it was copied from.
+func detach(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Field(i).CanSet() {
+				detach(v.Field(i))
+			}
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			detach(v.Index(i))
+		}
+	case reflect.Slice:
+		if v.IsNil() {
+			return
+		}
+		cp := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(cp, v)
+		for i := 0; i < cp.Len(); i++ {
+			detach(cp.Index(i))
+		}
+		v.Set(cp)
+	case reflect.Map:
+		if v.IsNil() {
+			return
+		}
+		cp := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			entry := reflect.New(v.Type().Elem()).Elem()
+			entry.Set(iter.Value())
+			detach(entry)
+			cp.SetMapIndex(iter.Key(), entry)
+		}
+		v.Set(cp)
+	}
+}
+
+func yamlNode(v reflect.Value) (*yaml.Node, error) {
+	if v.Type() == durationType {
+		return &yaml.Node{Kind: yaml.ScalarNode, Value: time.Duration(v.Int()).String()}, nil
+	}
+
+	if v.Kind() != reflect.Struct {
+		node := &yaml.Node{}
+		if err := node.Encode(v.Interface()); err != nil {
+			return nil, err
+		}
+		return node, nil
+	}
+
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() || field.Tag.Get("yaml") == "-" {
+			continue
+		}
+
+		value, err := yamlNode(v.Field(i))
+		if err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: yamlKey(field)}, value)
+	}
+	return node, nil
+}
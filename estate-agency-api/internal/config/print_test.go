@@ -0,0 +1,139 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testConfig() *Config {
+	cfg := &Config{
+		HTTPServerConfig: HTTPServerConfig{
+			Address:  "localhost:8080",
+			User:     "admin",
+			Password: "admin-pass",
+			Accounts: []AccountConfig{
+				{User: "north", Password: "north-pass", Agency: "north"},
+				{User: "south", Password: "south-pass", Agency: "south"},
+			},
+		},
+		RateLimitConfig: RateLimitConfig{
+			Enabled: true,
+			Routes:  []RouteRateLimit{{Method: "POST", Path: "/apartments", Requests: 10, Period: time.Minute}},
+		},
+	}
+	cfg.StoragePath = "user:db-pass@tcp(localhost:3306)/estate"
+	return cfg
+}
+
+func TestRedacted(t *testing.T) {
+	tests := []struct {
+		name   string
+		config func() *Config
+		secret string
+	}{
+		{name: "password of the default login", config: testConfig, secret: "admin-pass"},
+		{name: "password of an account", config: testConfig, secret: "north-pass"},
+		{name: "password of the last account", config: testConfig, secret: "south-pass"},
+		{name: "database password", config: testConfig, secret: "db-pass"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.config()
+			before := tt.config()
+
+			out, err := Marshal(cfg.Redacted())
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if strings.Contains(string(out), tt.secret) {
+				t.Errorf("redacted config contains %q:\n%s", tt.secret, out)
+			}
+			if !strings.Contains(string(out), redacted) {
+				t.Errorf("redacted config contains no %s:\n%s", redacted, out)
+			}
+			if !reflect.DeepEqual(cfg, before) {
+				t.Errorf("Redacted() changed the config: %+v", cfg.HTTPServerConfig.Accounts)
+			}
+		})
+	}
+}
+
+func TestRedactedKeepsSettings(t *testing.T) {
+	got := testConfig().Redacted()
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "user of an account", got: got.HTTPServerConfig.Accounts[0].User, want: "north"},
+		{name: "agency of an account", got: got.HTTPServerConfig.Accounts[1].Agency, want: "south"},
+		{name: "password of an account", got: got.HTTPServerConfig.Accounts[1].Password, want: redacted},
+		{name: "route", got: got.RateLimitConfig.Routes[0].Path, want: "/apartments"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %q, want %q", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(c *Config)
+		want   []string
+		// reloadable tells whether every change can be applied without a restart.
+		reloadable bool
+	}{
+		{name: "nothing", change: func(c *Config) {}, reloadable: true},
+		{
+			name:   "password of an account",
+			change: func(c *Config) { c.HTTPServerConfig.Accounts[1].Password = "changed" },
+			want:   []string{"http_server.accounts[1].password"},
+		},
+		{
+			name:   "account removed",
+			change: func(c *Config) { c.HTTPServerConfig.Accounts = c.HTTPServerConfig.Accounts[:1] },
+			want:   []string{"http_server.accounts[1].user", "http_server.accounts[1].password", "http_server.accounts[1].agency"},
+		},
+		{
+			name:       "route limit",
+			change:     func(c *Config) { c.RateLimitConfig.Routes[0].Requests = 20 },
+			want:       []string{"rate_limit.routes[0].requests"},
+			reloadable: true,
+		},
+		{
+			name:       "route added",
+			change:     func(c *Config) { c.RateLimitConfig.Routes = append(c.RateLimitConfig.Routes, RouteRateLimit{Path: "/feeds/*"}) },
+			want:       []string{"rate_limit.routes[1].method", "rate_limit.routes[1].path", "rate_limit.routes[1].requests", "rate_limit.routes[1].period", "rate_limit.routes[1].burst"},
+			reloadable: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			old, next := testConfig(), testConfig()
+			tt.change(next)
+
+			got := Diff(old, next)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff() = %q, want %q", got, tt.want)
+			}
+
+			reloadable := true
+			for _, key := range got {
+				reloadable = reloadable && Reloadable(key)
+			}
+			if reloadable != tt.reloadable {
+				t.Errorf("Reloadable() = %v, want %v", reloadable, tt.reloadable)
+			}
+		})
+	}
+}
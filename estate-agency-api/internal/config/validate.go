@@ -0,0 +1,121 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Validate reports every invalid setting at once, one per line, by its yaml path.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, key string, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+		}
+	}
+	positive := func(d time.Duration, key string) {
+		check(d > 0, key, "must be positive, got %s", d)
+	}
+	oneOf := func(value string, key string, allowed ...string) {
+		for _, a := range allowed {
+			if value == a {
+				return
+			}
+		}
+		check(false, key, "must be one of %v, got %q", allowed, value)
+	}
+
+	oneOf(c.Env, "env", EnvLocal, EnvDev, EnvProd)
+	positive(c.TimeClose, "time_close")
+	check(len(c.ImagesPath) != 0, "images_path", "must be set")
+
+	check(len(c.StorageConfig.StoragePath) != 0 || len(c.StorageConfig.ConfigDSN.Host) != 0 && len(c.StorageConfig.ConfigDSN.NameDB) != 0,
+		"storage", "either storage_path or dsn.host and dsn.name_db must be set")
+	check(c.StorageConfig.MaxOpenConns > 0, "storage.max_open_conns", "must be positive")
+
+	check(len(c.HTTPServerConfig.Address) != 0, "http_server.address", "must be set")
+	positive(c.HTTPServerConfig.Timeout, "http_server.timeout")
+	positive(c.HTTPServerConfig.IdleTimeout, "http_server.idle_timeout")
+	check(len(c.HTTPServerConfig.User) != 0, "http_server.user", "must be set")
+	check(len(c.HTTPServerConfig.Password) != 0, "http_server.password", "must be set")
+	users := map[string]bool{c.HTTPServerConfig.User: true}
+	for i, account := range c.HTTPServerConfig.Accounts {
+		key := fmt.Sprintf("http_server.accounts[%d]", i)
+		check(len(account.User) != 0 && !users[account.User], key+".user", "must be set and unique")
+		check(len(account.Password) != 0, key+".password", "must be set")
+		check(len(account.Agency) != 0, key+".agency", "must be set")
+		users[account.User] = true
+	}
+
+	oneOf(c.IdempotencyConfig.Storage, "idempotency.storage", "sql", "memory")
+	positive(c.IdempotencyConfig.TTL, "idempotency.ttl")
+
+	positive(c.RetentionConfig.Period, "retention.period")
+	positive(c.RetentionConfig.PurgeInterval, "retention.purge_interval")
+
+	positive(c.EventsConfig.RelayInterval, "events.relay_interval")
+	check(c.EventsConfig.BatchSize > 0, "events.batch_size", "must be positive")
+	check(c.EventsConfig.MaxAttempts > 0, "events.max_attempts", "must be positive")
+
+	positive(c.WebhookConfig.Timeout, "webhook.timeout")
+	positive(c.WebhookConfig.Interval, "webhook.interval")
+	check(c.WebhookConfig.MaxAttempts > 0, "webhook.max_attempts", "must be positive")
+	check(c.WebhookConfig.BackoffBase <= c.WebhookConfig.BackoffMax, "webhook.backoff_base", "must not exceed backoff_max")
+
+	positive(c.StreamConfig.Heartbeat, "stream.heartbeat")
+
+	oneOf(c.NotifierConfig.Type, "notifier.type", "log", "smtp")
+	positive(c.NotifierConfig.Interval, "notifier.interval")
+	if c.NotifierConfig.Type == "smtp" {
+		check(len(c.NotifierConfig.SMTPConfig.Host) != 0, "notifier.smtp.host", "must be set for the smtp notifier")
+	}
+
+	positive(c.RentConfig.CheckInterval, "rent.check_interval")
+	positive(c.MetricsConfig.RefreshInterval, "metrics.refresh_interval")
+	check(len(c.MetricsConfig.Path) != 0 && c.MetricsConfig.Path[0] == '/', "metrics.path", "must start with /")
+
+	oneOf(c.TracingConfig.Exporter, "tracing.exporter", "none", "stdout", "otlp")
+	check(c.TracingConfig.SampleRatio >= 0 && c.TracingConfig.SampleRatio <= 1, "tracing.sample_ratio", "must be between 0 and 1")
+
+	positive(c.HealthConfig.Interval, "health.interval")
+	positive(c.HealthConfig.Timeout, "health.timeout")
+
+	var level slog.Level
+	check(level.UnmarshalText([]byte(c.LoggerConfig.Level)) == nil, "logger.level", "must be one of debug, info, warn, error, got %q", c.LoggerConfig.Level)
+	oneOf(c.LoggerConfig.Format, "logger.format", "text", "json")
+
+	oneOf(c.RateLimitConfig.Backend, "rate_limit.backend", "memory", "redis")
+	if c.RateLimitConfig.Backend == "redis" {
+		check(len(c.CacheConfig.Addr) != 0, "redis.address", "must be set for the redis rate limit backend")
+	}
+	check(c.RateLimitConfig.IP.Requests > 0, "rate_limit.ip.requests", "must be positive")
+	positive(c.RateLimitConfig.IP.Period, "rate_limit.ip.period")
+	check(c.RateLimitConfig.IP.Burst >= 0, "rate_limit.ip.burst", "must not be negative")
+	check(c.RateLimitConfig.Default.Requests > 0, "rate_limit.default.requests", "must be positive")
+	positive(c.RateLimitConfig.Default.Period, "rate_limit.default.period")
+	check(c.RateLimitConfig.Default.Burst >= 0, "rate_limit.default.burst", "must not be negative")
+	for i, route := range c.RateLimitConfig.Routes {
+		key := fmt.Sprintf("rate_limit.routes[%d]", i)
+		check(len(route.Path) != 0 && route.Path[0] == '/', key+".path", "must start with /")
+		if len(route.Method) != 0 {
+			oneOf(route.Method, key+".method", http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions)
+		}
+		check(route.Requests > 0, key+".requests", "must be positive")
+		positive(route.Period, key+".period")
+		check(route.Burst >= 0, key+".burst", "must not be negative")
+	}
+
+	check(len(c.TenantConfig.Default) != 0, "tenant.default", "must be set")
+
+	if len(c.GeocoderConfig.Gazetteer) != 0 {
+		ext := strings.ToLower(filepath.Ext(c.GeocoderConfig.Gazetteer))
+		check(ext == ".csv" || ext == ".osm", "geocoder.gazetteer", "must be a .csv or .osm file, got %q", c.GeocoderConfig.Gazetteer)
+	}
+
+	return errors.Join(errs...)
+}
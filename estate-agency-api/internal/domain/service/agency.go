@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"gilab.com/estate-agency-api/internal/entity"
+	"gilab.com/estate-agency-api/pkg/reqctx"
+	"github.com/go-playground/validator/v10"
+)
+
+type AgencyStorage interface {
+	GetAll(ctx context.Context, page int, pageSize int) (agencies []*entity.Agency, err error)
+	All(ctx context.Context) (agencies []*entity.Agency, err error)
+	GetByID(ctx context.Context, id int) (agency *entity.Agency, err error)
+	GetBySlug(ctx context.Context, slug string) (agency *entity.Agency, err error)
+	Create(ctx context.Context, agency *entity.Agency, audit *entity.AuditEntry) (id int64, err error)
+}
+
+type agencyService struct {
+	storage  AgencyStorage
+	validate *validator.Validate
+
+	mu    sync.RWMutex
+	slugs map[string]int
+}
+
+func NewAgencyService(storage AgencyStorage) *agencyService {
+	validate := validator.New()
+	validate.SetTagName("binding")
+
+	return &agencyService{storage: storage, validate: validate, slugs: make(map[string]int)}
+}
+
+func (s *agencyService) GetAll(ctx context.Context, page int, pageSize int) ([]*entity.Agency, error) {
+	if err := platformOnly(ctx); err != nil {
+		return nil, err
+	}
+	return s.storage.GetAll(ctx, page, pageSize)
+}
+
+func (s *agencyService) All(ctx context.Context) ([]*entity.Agency, error) {
+	return s.storage.All(ctx)
+}
+
+func (s *agencyService) GetByID(ctx context.Context, id int) (*entity.Agency, error) {
+	if err := platformOnly(ctx); err != nil {
+		return nil, err
+	}
+	return s.storage.GetByID(ctx, id)
+}
+
+func (s *agencyService) Create(ctx context.Context, agency *entity.Agency) (id int64, err error) {
+	if err = platformOnly(ctx); err != nil {
+		return
+	}
+	if err = s.validate.Struct(agency); err != nil {
+		return 0, fmt.Errorf("%w: %s", entity.ErrValidation, err)
+	}
+	agency.CreatedAt = time.Now().UTC().Truncate(time.Second)
+
+	id, err = s.storage.Create(ctx, agency, newAuditEntry(ctx, entity.AuditEntityAgency, 0, entity.AuditActionCreate, nil, agency))
+	if err != nil {
+		return
+	}
+	agency.ID = int(id)
+
+	return id, nil
+}
+
+// platformOnly rejects requests made by anyone but the administrator of the platform. The agencies are the
+// tenants of the platform, so the staff of one agency may neither see the others nor create new ones.
+func platformOnly(ctx context.Context) error {
+	if !reqctx.PlatformAdmin(ctx) {
+		return entity.ErrForbidden
+	}
+	return nil
+}
+
+// Resolve returns the ID of the agency with the slug; an unknown slug is reported as sql.ErrNoRows.
+// Slugs never change, so resolved ones are cached for the lifetime of the process.
+func (s *agencyService) Resolve(ctx context.Context, slug string) (int, error) {
+	s.mu.RLock()
+	id, ok := s.slugs[slug]
+	s.mu.RUnlock()
+	if ok {
+		return id, nil
+	}
+
+	agency, err := s.storage.GetBySlug(ctx, slug)
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	s.slugs[slug] = agency.ID
+	s.mu.Unlock()
+
+	return agency.ID, nil
+}
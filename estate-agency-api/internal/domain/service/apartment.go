@@ -2,82 +2,316 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"os"
 	"time"
 
-	"gilab.com/estate-agency-api/internal/domain/entity"
+	"gilab.com/estate-agency-api/internal/entity"
+	"gilab.com/estate-agency-api/pkg/patch"
+	"gilab.com/estate-agency-api/pkg/reqctx"
+	"github.com/go-playground/validator/v10"
 )
 
 type ApartmentStorage interface {
-	GetAll(page int, pageSize int) (apartments []*entity.Apartment, err error)
-	GetByID(id int) (apartment *entity.Apartment, err error)
-	Create(apartment *entity.Apartment) (id int64, err error)
-	Update(apartment *entity.Apartment) (aff int64, err error)
-	Delete(id int) error
+	GetAll(ctx context.Context, filter entity.ApartmentFilter, page int, pageSize int) (apartments []*entity.Apartment, err error)
+	Each(ctx context.Context, filter entity.ApartmentFilter, fn func(apartment *entity.Apartment) error) error
+	GetByID(ctx context.Context, id int) (apartment *entity.Apartment, err error)
+	Create(ctx context.Context, apartment *entity.Apartment, events []*entity.Event, audit *entity.AuditEntry) (id int64, err error)
+	UpdateFields(ctx context.Context, id int, version int, fields map[string]any, events []*entity.Event, audit *entity.AuditEntry) (aff int64, err error)
+	Delete(ctx context.Context, id int, version int, events []*entity.Event, audit *entity.AuditEntry) error
+	// Restore sets the After of the audit entry to the restored apartment.
+	Restore(ctx context.Context, id int, events []*entity.Event, audit *entity.AuditEntry) error
+	GetDeleted(ctx context.Context, page int, pageSize int) (apartments []*entity.Apartment, err error)
+	Purge(ctx context.Context, before time.Time) (ids []int, err error)
+	CountPublished(ctx context.Context) (counts []*entity.ListingCount, err error)
+}
+
+// AccessPolicy decides whether the request may manage the listings of a realtor.
+type AccessPolicy interface {
+	CanManage(ctx context.Context, realtorID int) error
+}
+
+// Geocoder normalizes the free-text address of an apartment and finds its coordinates, nil when they are unknown.
+type Geocoder interface {
+	Geocode(address string, city string) (normalized entity.Address, location *entity.GeoPoint)
+}
+
+type ImageStorage interface {
+	Delete(kind string, id int) error
 }
 
 type apartmentService struct {
-	storage ApartmentStorage
+	storage  ApartmentStorage
+	images   ImageStorage
+	access   AccessPolicy
+	geocoder Geocoder
+	validate *validator.Validate
 }
 
-func NewApartmentService(storage ApartmentStorage) *apartmentService {
-	return &apartmentService{storage: storage}
+func NewApartmentService(storage ApartmentStorage, images ImageStorage, access AccessPolicy, geocoder Geocoder) *apartmentService {
+	validate := validator.New()
+	validate.SetTagName("binding")
+
+	return &apartmentService{storage: storage, images: images, access: access, geocoder: geocoder, validate: validate}
+}
+
+func (s *apartmentService) GetAll(ctx context.Context, filter entity.ApartmentFilter, page int, pageSize int) ([]*entity.Apartment, error) {
+	return s.storage.GetAll(ctx, filter, page, pageSize)
 }
 
-func (s *apartmentService) GetAll(ctx context.Context, page int, pageSize int) ([]*entity.Apartment, error) {
-	return s.storage.GetAll(page, pageSize)
+func (s *apartmentService) Export(ctx context.Context, filter entity.ApartmentFilter, fn func(apartment *entity.Apartment) error) error {
+	return s.storage.Each(ctx, filter, fn)
 }
 
 func (s *apartmentService) GetByID(ctx context.Context, id int) (realtor *entity.Apartment, err error) {
-	return s.storage.GetByID(id)
+	return s.storage.GetByID(ctx, id)
 }
 
 func (s *apartmentService) Create(ctx context.Context, apartment *entity.Apartment) (id int64, err error) {
 	apartment.UpdateTime = time.Now().Format("02.01.2006 15:04:05")
 	apartment.CreateTime = time.Now().Format("02.01.2006 15:04:05")
+	if len(apartment.Status) == 0 {
+		apartment.Status = entity.ApartmentStatusPublished
+	}
+	if len(apartment.ListingType) == 0 {
+		apartment.ListingType = entity.ListingTypeSale
+	}
+	if err = apartment.ValidateListing(); err != nil {
+		return
+	}
+	if err = apartment.ValidateLocation(); err != nil {
+		return
+	}
+	s.geocode(apartment)
+	if err = s.access.CanManage(ctx, apartment.IDRealtor); err != nil {
+		return
+	}
 
-	return s.storage.Create(apartment)
+	return s.storage.Create(ctx, apartment, []*entity.Event{
+		entity.NewEvent(entity.EventApartmentCreated, entity.AggregateApartment, 0, apartment),
+	}, newAuditEntry(ctx, entity.AuditEntityApartment, 0, entity.AuditActionCreate, nil, apartment))
 }
 
-func (s *apartmentService) Update(ctx context.Context, id int, apartment *entity.Apartment) (aff int64, err error) {
-	r, err := s.storage.GetByID(id)
+// Patch applies a partial update to the apartment with the given version, or any version for entity.AnyVersion,
+// and stores only the changed columns.
+func (s *apartmentService) Patch(ctx context.Context, id int, version int, doc patch.Document) (*entity.Apartment, error) {
+	current, err := s.storage.GetByID(ctx, id)
 	if err != nil {
-		return aff, err
+		return nil, err
+	}
+	if version == entity.AnyVersion {
+		version = current.Version
+	}
+	if current.Version != version {
+		return nil, entity.ErrVersionConflict
+	}
+	if err = s.access.CanManage(ctx, current.IDRealtor); err != nil {
+		return nil, err
 	}
-	apartment.ID = id
 
-	if len(apartment.Title) == 0 {
-		apartment.Title = r.Title
+	var apartment entity.Apartment
+	if err = applyPatch(current, doc, &apartment); err != nil {
+		return nil, err
 	}
-	if apartment.Price == 0 {
-		apartment.Price = r.Price
+	if apartment.IDRealtor != current.IDRealtor {
+		if err = s.access.CanManage(ctx, apartment.IDRealtor); err != nil {
+			return nil, err
+		}
 	}
-	if len(apartment.City) == 0 {
-		apartment.City = r.City
+
+	if apartment.ID != current.ID || apartment.Version != current.Version || apartment.UpdateTime != current.UpdateTime || apartment.CreateTime != current.CreateTime || apartment.DistanceKM != nil {
+		return nil, fmt.Errorf("%w: id, version, update_time, create_time and distance_km are read-only", entity.ErrValidation)
 	}
-	if apartment.Rooms == 0 {
-		apartment.Rooms = r.Rooms
+	if err = s.validate.Struct(apartment); err != nil {
+		return nil, fmt.Errorf("%w: %s", entity.ErrValidation, err)
 	}
-	if len(apartment.Address) == 0 {
-		apartment.Address = r.Address
+	if err = apartment.ValidateListing(); err != nil {
+		return nil, err
 	}
-	if apartment.Square == 0 {
-		apartment.Square = r.Square
+	if err = apartment.ValidateLocation(); err != nil {
+		return nil, err
 	}
-	if apartment.IDRealtor == 0 {
-		apartment.IDRealtor = r.IDRealtor
+
+	if apartment.Address != current.Address || apartment.City != current.City {
+		// The coordinates of the old address are dropped, unless the patch sets new ones.
+		if sameCoordinate(apartment.Lat, current.Lat) && sameCoordinate(apartment.Lng, current.Lng) {
+			apartment.Lat, apartment.Lng = nil, nil
+		}
+		s.geocode(&apartment)
+	} else {
+		apartment.NormalizedAddress = current.NormalizedAddress
+	}
+
+	fields := apartmentChanges(current, &apartment)
+	if len(fields) == 0 {
+		return current, nil
 	}
+
 	apartment.UpdateTime = time.Now().Format("02.01.2006 15:04:05")
-	apartment.CreateTime = r.CreateTime
+	fields["update_time"] = apartment.UpdateTime
+
+	apartment.Version++
+
+	events := []*entity.Event{
+		entity.NewEvent(entity.EventApartmentUpdated, entity.AggregateApartment, id, entity.ApartmentChange{ID: id, Version: apartment.Version, Changes: fields, Apartment: &apartment}),
+	}
+	if apartment.Price != current.Price {
+		events = append(events, entity.NewEvent(entity.EventApartmentPriceChanged, entity.AggregateApartment, id, entity.PriceChange{ID: id, OldPrice: current.Price, NewPrice: apartment.Price}))
+	}
+	if apartment.Status != current.Status {
+		events = append(events, entity.NewEvent(entity.EventApartmentStatusChanged, entity.AggregateApartment, id, entity.StatusChange{ID: id, OldStatus: current.Status, NewStatus: apartment.Status, Apartment: &apartment}))
+	}
+
+	audit := newAuditEntry(ctx, entity.AuditEntityApartment, id, entity.AuditActionUpdate, current, &apartment)
+	if _, err = s.storage.UpdateFields(ctx, id, version, fields, events, audit); err != nil {
+		return nil, err
+	}
 
-	return s.storage.Update(apartment)
+	return &apartment, nil
 }
 
-func (s *apartmentService) Delete(ctx context.Context, id int) error {
-	if err := s.storage.Delete(id); err != nil {
+func apartmentChanges(before *entity.Apartment, after *entity.Apartment) map[string]any {
+	fields := make(map[string]any)
+
+	if before.Title != after.Title {
+		fields["title"] = after.Title
+	}
+	if before.Price != after.Price {
+		fields["price"] = after.Price
+	}
+	if before.City != after.City {
+		fields["city"] = after.City
+	}
+	if before.Rooms != after.Rooms {
+		fields["rooms"] = after.Rooms
+	}
+	if before.Address != after.Address {
+		fields["address"] = after.Address
+	}
+	if before.Square != after.Square {
+		fields["square"] = after.Square
+	}
+	if before.IDRealtor != after.IDRealtor {
+		fields["id_realtor"] = after.IDRealtor
+	}
+	if before.Status != after.Status {
+		fields["status"] = after.Status
+	}
+	if before.ListingType != after.ListingType {
+		fields["listing_type"] = after.ListingType
+	}
+	if before.MonthlyRent != after.MonthlyRent {
+		fields["monthly_rent"] = after.MonthlyRent
+	}
+	if before.Deposit != after.Deposit {
+		fields["deposit"] = after.Deposit
+	}
+	if before.MinTermMonths != after.MinTermMonths {
+		fields["min_term_months"] = after.MinTermMonths
+	}
+	if before.UtilitiesIncluded != after.UtilitiesIncluded {
+		fields["utilities_included"] = after.UtilitiesIncluded
+	}
+	if before.PetsAllowed != after.PetsAllowed {
+		fields["pets_allowed"] = after.PetsAllowed
+	}
+	if !sameCoordinate(before.Lat, after.Lat) {
+		fields["lat"] = after.Lat
+	}
+	if !sameCoordinate(before.Lng, after.Lng) {
+		fields["lng"] = after.Lng
+	}
+	if before.NormalizedAddress.Street != after.NormalizedAddress.Street {
+		fields["address_street"] = after.NormalizedAddress.Street
+	}
+	if before.NormalizedAddress.House != after.NormalizedAddress.House {
+		fields["address_house"] = after.NormalizedAddress.House
+	}
+	if before.NormalizedAddress.Building != after.NormalizedAddress.Building {
+		fields["address_building"] = after.NormalizedAddress.Building
+	}
+	if before.NormalizedAddress.City != after.NormalizedAddress.City {
+		fields["address_city"] = after.NormalizedAddress.City
+	}
+
+	return fields
+}
+
+// geocode stores the normalized address of the apartment and, unless it has them already, its coordinates.
+func (s *apartmentService) geocode(apartment *entity.Apartment) {
+	normalized, location := s.geocoder.Geocode(apartment.Address, apartment.City)
+	apartment.NormalizedAddress = normalized
+	if location != nil && apartment.Lat == nil && apartment.Lng == nil {
+		apartment.Lat, apartment.Lng = &location.Lat, &location.Lng
+	}
+}
+
+func sameCoordinate(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func (s *apartmentService) Delete(ctx context.Context, id int, version int) error {
+	r, err := s.storage.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if version == entity.AnyVersion {
+		version = r.Version
+	}
+	if r.Version != version {
+		return entity.ErrVersionConflict
+	}
+	if err = s.access.CanManage(ctx, r.IDRealtor); err != nil {
 		return err
 	}
 
-	return os.Remove(fmt.Sprintf("./../../internal/images/apartment/%d.png", id))
+	return s.storage.Delete(ctx, id, version, []*entity.Event{
+		entity.NewEvent(entity.EventApartmentDeleted, entity.AggregateApartment, id, entity.ApartmentChange{ID: id, Version: version + 1, Apartment: r}),
+	}, newAuditEntry(ctx, entity.AuditEntityApartment, id, entity.AuditActionDelete, r, nil))
+}
+
+// Restore brings a deleted apartment back. Deleted apartments are out of reach of realtors, so only requests
+// trusted within the agency may restore them.
+func (s *apartmentService) Restore(ctx context.Context, id int) error {
+	if _, ok := reqctx.Realtor(ctx); ok {
+		return entity.ErrForbidden
+	}
+
+	return s.storage.Restore(ctx, id, []*entity.Event{
+		entity.NewEvent(entity.EventApartmentRestored, entity.AggregateApartment, id, entity.AggregateRef{ID: id}),
+	}, newAuditEntry(ctx, entity.AuditEntityApartment, id, entity.AuditActionRestore, nil, nil))
+}
+
+func (s *apartmentService) GetDeleted(ctx context.Context, page int, pageSize int) ([]*entity.Apartment, error) {
+	return s.storage.GetDeleted(ctx, page, pageSize)
+}
+
+// Purge hard-deletes apartments soft-deleted before the given time together with their photos.
+func (s *apartmentService) Purge(ctx context.Context, before time.Time) (int, error) {
+	const op = "service.apartment.Purge"
+
+	ids, err := s.storage.Purge(ctx, before)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// The rows are gone already, so a photo that fails to delete does not stop the others.
+	var errs []error
+	for _, id := range ids {
+		if err = s.images.Delete("apartment", id); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err = errors.Join(errs...); err != nil {
+		return len(ids), fmt.Errorf("%s: %w", op, err)
+	}
+
+	return len(ids), nil
+}
+
+func (s *apartmentService) CountPublished(ctx context.Context) ([]*entity.ListingCount, error) {
+	return s.storage.CountPublished(ctx)
 }
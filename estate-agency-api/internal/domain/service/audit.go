@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"gilab.com/estate-agency-api/internal/entity"
+	"gilab.com/estate-agency-api/pkg/reqctx"
+)
+
+type AuditStorage interface {
+	Find(ctx context.Context, filter entity.AuditFilter, page int, pageSize int) (entries []*entity.AuditEntry, err error)
+}
+
+type auditService struct {
+	storage AuditStorage
+}
+
+func NewAuditService(storage AuditStorage) *auditService {
+	return &auditService{storage: storage}
+}
+
+func (s *auditService) Find(ctx context.Context, filter entity.AuditFilter, page int, pageSize int) ([]*entity.AuditEntry, error) {
+	return s.storage.Find(ctx, filter, page, pageSize)
+}
+
+// newAuditEntry describes a mutation of the entity by the actor of the request. The entry is handed to the storage
+// together with the change and written in its transaction, like outbox events. Either before or after is nil
+// for creations and deletions, and entityID is 0 for creations, the storage sets it.
+func newAuditEntry(ctx context.Context, entityType string, entityID int, action string, before any, after any) *entity.AuditEntry {
+	return &entity.AuditEntry{
+		Actor:      reqctx.Actor(ctx),
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     action,
+		RequestID:  reqctx.RequestID(ctx),
+		CreatedAt:  time.Now().UTC(),
+		Before:     before,
+		After:      after,
+	}
+}
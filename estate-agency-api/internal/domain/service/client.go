@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gilab.com/estate-agency-api/internal/entity"
+	"gilab.com/estate-agency-api/pkg/patch"
+	"github.com/go-playground/validator/v10"
+)
+
+type ClientStorage interface {
+	GetAll(ctx context.Context, page int, pageSize int) (clients []*entity.Client, err error)
+	GetByID(ctx context.Context, id int) (client *entity.Client, err error)
+	Create(ctx context.Context, client *entity.Client, audit *entity.AuditEntry) (id int64, err error)
+	UpdateFields(ctx context.Context, id int, fields map[string]any, audit *entity.AuditEntry) (aff int64, err error)
+	Delete(ctx context.Context, id int, audit *entity.AuditEntry) error
+}
+
+type clientService struct {
+	storage  ClientStorage
+	validate *validator.Validate
+}
+
+func NewClientService(storage ClientStorage) *clientService {
+	validate := validator.New()
+	validate.SetTagName("binding")
+
+	return &clientService{storage: storage, validate: validate}
+}
+
+func (s *clientService) GetAll(ctx context.Context, page int, pageSize int) ([]*entity.Client, error) {
+	return s.storage.GetAll(ctx, page, pageSize)
+}
+
+func (s *clientService) GetByID(ctx context.Context, id int) (*entity.Client, error) {
+	return s.storage.GetByID(ctx, id)
+}
+
+func (s *clientService) Create(ctx context.Context, client *entity.Client) (id int64, err error) {
+	client.CreatedAt = time.Now().UTC().Truncate(time.Second)
+
+	id, err = s.storage.Create(ctx, client, newAuditEntry(ctx, entity.AuditEntityClient, 0, entity.AuditActionCreate, nil, client))
+	if err != nil {
+		return
+	}
+	client.ID = int(id)
+
+	return id, nil
+}
+
+func (s *clientService) Patch(ctx context.Context, id int, doc patch.Document) (*entity.Client, error) {
+	current, err := s.storage.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var client entity.Client
+	if err = applyPatch(current, doc, &client); err != nil {
+		return nil, err
+	}
+
+	if client.ID != current.ID || !client.CreatedAt.Equal(current.CreatedAt) {
+		return nil, fmt.Errorf("%w: id and created_at are read-only", entity.ErrValidation)
+	}
+	if err = s.validate.Struct(client); err != nil {
+		return nil, fmt.Errorf("%w: %s", entity.ErrValidation, err)
+	}
+
+	fields := make(map[string]any)
+	if client.FirstName != current.FirstName {
+		fields["first_name"] = client.FirstName
+	}
+	if client.LastName != current.LastName {
+		fields["last_name"] = client.LastName
+	}
+	if client.Phone != current.Phone {
+		fields["phone"] = client.Phone
+	}
+	if client.Email != current.Email {
+		fields["email"] = client.Email
+	}
+	if len(fields) == 0 {
+		return current, nil
+	}
+
+	if _, err = s.storage.UpdateFields(ctx, id, fields, newAuditEntry(ctx, entity.AuditEntityClient, id, entity.AuditActionUpdate, current, &client)); err != nil {
+		return nil, err
+	}
+
+	return &client, nil
+}
+
+func (s *clientService) Delete(ctx context.Context, id int) error {
+	before, err := s.storage.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return s.storage.Delete(ctx, id, newAuditEntry(ctx, entity.AuditEntityClient, id, entity.AuditActionDelete, before, nil))
+}
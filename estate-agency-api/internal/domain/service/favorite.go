@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"gilab.com/estate-agency-api/internal/entity"
+)
+
+type FavoriteStorage interface {
+	Add(ctx context.Context, favorite *entity.Favorite, audit *entity.AuditEntry) error
+	Remove(ctx context.Context, clientID int, apartmentID int, audit *entity.AuditEntry) error
+	GetByClient(ctx context.Context, clientID int, page int, pageSize int) (favorites []*entity.FavoriteView, err error)
+	TopByRealtor(ctx context.Context, realtorID int, limit int) (counts []*entity.FavoriteCount, err error)
+}
+
+type favoriteService struct {
+	storage    FavoriteStorage
+	clients    ClientStorage
+	apartments ApartmentStorage
+}
+
+func NewFavoriteService(storage FavoriteStorage, clients ClientStorage, apartments ApartmentStorage) *favoriteService {
+	return &favoriteService{storage: storage, clients: clients, apartments: apartments}
+}
+
+// Add shortlists the apartment for the client and remembers its current price. Favorites are audited
+// as entities of the client.
+func (s *favoriteService) Add(ctx context.Context, favorite *entity.Favorite) error {
+	if _, err := s.clients.GetByID(ctx, favorite.IDClient); err != nil {
+		return err
+	}
+	apartment, err := s.apartments.GetByID(ctx, favorite.IDApartment)
+	if err != nil {
+		return err
+	}
+
+	favorite.PriceAtFavorite = apartment.Price
+	favorite.CreatedAt = time.Now().UTC().Truncate(time.Second)
+
+	return s.storage.Add(ctx, favorite, newAuditEntry(ctx, entity.AuditEntityFavorite, favorite.IDClient, entity.AuditActionCreate, nil, favorite))
+}
+
+func (s *favoriteService) Remove(ctx context.Context, clientID int, apartmentID int) error {
+	before := &entity.Favorite{IDClient: clientID, IDApartment: apartmentID}
+	return s.storage.Remove(ctx, clientID, apartmentID, newAuditEntry(ctx, entity.AuditEntityFavorite, clientID, entity.AuditActionDelete, before, nil))
+}
+
+func (s *favoriteService) GetByClient(ctx context.Context, clientID int, page int, pageSize int) ([]*entity.FavoriteView, error) {
+	favorites, err := s.storage.GetByClient(ctx, clientID, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range favorites {
+		f.PriceDropped = f.Price < f.PriceAtFavorite
+	}
+
+	return favorites, nil
+}
+
+func (s *favoriteService) TopByRealtor(ctx context.Context, realtorID int, limit int) ([]*entity.FavoriteCount, error) {
+	return s.storage.TopByRealtor(ctx, realtorID, limit)
+}
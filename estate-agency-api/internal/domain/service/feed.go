@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gilab.com/estate-agency-api/internal/entity"
+	"gilab.com/estate-agency-api/pkg/reqctx"
+	"gilab.com/estate-agency-api/pkg/yrl"
+	"golang.org/x/sync/singleflight"
+)
+
+const apartmentTimeLayout = "02.01.2006 15:04:05"
+
+// PhotoStorage tells whether a photo of the entity was uploaded.
+type PhotoStorage interface {
+	Exists(kind string, id int) (bool, error)
+}
+
+type feedService struct {
+	apartmentStorage ApartmentStorage
+	realtorStorage   RealtorStorage
+	photos           PhotoStorage
+	photoBaseURL     string
+	country          string
+
+	group      singleflight.Group
+	mu         sync.Mutex
+	yrl        map[int][]byte
+	generation int
+}
+
+func NewFeedService(apartmentStorage ApartmentStorage, realtorStorage RealtorStorage, photos PhotoStorage, photoBaseURL string, country string) *feedService {
+	return &feedService{apartmentStorage: apartmentStorage, realtorStorage: realtorStorage, photos: photos, photoBaseURL: strings.TrimRight(photoBaseURL, "/"), country: country, yrl: make(map[int][]byte)}
+}
+
+// YRL returns the cached feed of the agency's published apartments, generating it again after Invalidate.
+func (s *feedService) YRL(ctx context.Context) ([]byte, error) {
+	agencyID, ok := reqctx.Tenant(ctx)
+	if !ok {
+		return nil, entity.ErrTenantRequired
+	}
+
+	s.mu.Lock()
+	feed, ok := s.yrl[agencyID]
+	generation := s.generation
+	s.mu.Unlock()
+	if ok {
+		return feed, nil
+	}
+
+	// Concurrent requests for the feed of an agency wait for a single generation, without holding up other agencies.
+	v, err, _ := s.group.Do(strconv.Itoa(agencyID), func() (any, error) {
+		feed, err := s.generateYRL(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		// A feed generated while Invalidate was called may miss the change, so it is served but not cached.
+		s.mu.Lock()
+		if s.generation == generation {
+			s.yrl[agencyID] = feed
+		}
+		s.mu.Unlock()
+
+		return feed, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return v.([]byte), nil
+}
+
+// Invalidate drops the feeds of all agencies, they are generated again on the next request.
+func (s *feedService) Invalidate() {
+	s.mu.Lock()
+	s.yrl = make(map[int][]byte)
+	s.generation++
+	s.mu.Unlock()
+}
+
+func (s *feedService) generateYRL(ctx context.Context) ([]byte, error) {
+	const op = "service.feed.generateYRL"
+
+	var offers []yrl.Offer
+	realtors := make(map[int]*entity.Realtor)
+
+	filter := entity.ApartmentFilter{Status: entity.ApartmentStatusPublished}
+	err := s.apartmentStorage.Each(ctx, filter, func(apartment *entity.Apartment) error {
+		realtor, ok := realtors[apartment.IDRealtor]
+		if !ok {
+			r, err := s.realtorStorage.GetByID(ctx, apartment.IDRealtor)
+			if errors.Is(err, sql.ErrNoRows) {
+				r = nil
+			} else if err != nil {
+				return fmt.Errorf("realtor %d: %w", apartment.IDRealtor, err)
+			}
+			realtor = r
+			realtors[apartment.IDRealtor] = realtor
+		}
+		// An offer needs a sales agent, so the apartments of a deleted realtor are left out until reassigned.
+		if realtor == nil {
+			return nil
+		}
+
+		offer, err := s.offer(apartment, realtor)
+		if err != nil {
+			return err
+		}
+		offers = append(offers, offer)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return yrl.Marshal(yrl.NewFeed(time.Now(), offers))
+}
+
+func (s *feedService) offer(apartment *entity.Apartment, realtor *entity.Realtor) (yrl.Offer, error) {
+	offer := yrl.Offer{
+		InternalID:   strconv.Itoa(apartment.ID),
+		Type:         yrl.TypeSale,
+		PropertyType: yrl.PropertyTypeLiving,
+		Category:     yrl.CategoryFlat,
+		Location: yrl.Location{
+			Country:      s.country,
+			LocalityName: apartment.City,
+			Address:      apartment.Address,
+		},
+		SalesAgent: yrl.SalesAgent{
+			Name:     strings.TrimSpace(realtor.FirstName + " " + realtor.LastName),
+			Phone:    realtor.Phone,
+			Email:    realtor.Email,
+			Category: yrl.AgentCategoryAgency,
+		},
+		Price:       yrl.Price{Value: apartment.Price, Currency: yrl.CurrencyRUB},
+		Area:        yrl.Area{Value: apartment.Square, Unit: yrl.UnitSquareMeter},
+		Rooms:       apartment.Rooms,
+		Description: apartment.Title,
+	}
+
+	if apartment.ListingType == entity.ListingTypeRent {
+		offer.Type = yrl.TypeRent
+		offer.Price = yrl.Price{Value: apartment.MonthlyRent, Currency: yrl.CurrencyRUB, Period: yrl.PeriodMonth}
+		offer.RentPledge = yrl.NewBool(apartment.Deposit != 0)
+		offer.UtilitiesIncluded = yrl.NewBool(apartment.UtilitiesIncluded)
+		offer.WithPets = yrl.NewBool(apartment.PetsAllowed)
+	}
+
+	if created, err := time.ParseInLocation(apartmentTimeLayout, apartment.CreateTime, time.Local); err == nil {
+		offer.CreationDate = yrl.FormatTime(created)
+	}
+	if updated, err := time.ParseInLocation(apartmentTimeLayout, apartment.UpdateTime, time.Local); err == nil {
+		offer.LastUpdateDate = yrl.FormatTime(updated)
+	}
+	if len(s.photoBaseURL) != 0 {
+		exists, err := s.photos.Exists("apartment", apartment.ID)
+		if err != nil {
+			return offer, fmt.Errorf("photo of apartment %d: %w", apartment.ID, err)
+		}
+		if exists {
+			offer.Images = []string{fmt.Sprintf("%s/apartment/%d.png", s.photoBaseURL, apartment.ID)}
+		}
+	}
+
+	return offer, nil
+}
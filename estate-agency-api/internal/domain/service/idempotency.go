@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gilab.com/estate-agency-api/internal/entity"
+)
+
+type IdempotencyStorage interface {
+	Get(ctx context.Context, key string) (record *entity.IdempotencyRecord, err error)
+	Reserve(ctx context.Context, record *entity.IdempotencyRecord) error
+	Complete(ctx context.Context, record *entity.IdempotencyRecord) error
+	Release(ctx context.Context, key string) error
+	Clean(ctx context.Context, before time.Time) (int64, error)
+}
+
+type idempotencyService struct {
+	storage IdempotencyStorage
+	ttl     time.Duration
+}
+
+func NewIdempotencyService(storage IdempotencyStorage, ttl time.Duration) *idempotencyService {
+	return &idempotencyService{storage: storage, ttl: ttl}
+}
+
+// Begin reserves the key for a new request. It returns the stored record when the
+// request is a retry whose response can be replayed, and nil when the request must run.
+func (s *idempotencyService) Begin(ctx context.Context, key string, requestHash string) (*entity.IdempotencyRecord, error) {
+	err := s.storage.Reserve(ctx, &entity.IdempotencyRecord{
+		Key:         key,
+		RequestHash: requestHash,
+		ExpiresAt:   time.Now().Add(s.ttl),
+	})
+	if err == nil {
+		return nil, nil
+	}
+	if !errors.Is(err, entity.ErrIdempotencyKeyExists) {
+		return nil, err
+	}
+
+	record, err := s.storage.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if record.RequestHash != requestHash {
+		return nil, entity.ErrIdempotencyKeyMismatch
+	}
+	if !record.Completed {
+		return nil, entity.ErrIdempotencyInProgress
+	}
+
+	return record, nil
+}
+
+func (s *idempotencyService) Complete(ctx context.Context, key string, statusCode int, contentType string, response []byte) error {
+	return s.storage.Complete(ctx, &entity.IdempotencyRecord{
+		Key:         key,
+		StatusCode:  statusCode,
+		ContentType: contentType,
+		Response:    response,
+		Completed:   true,
+	})
+}
+
+func (s *idempotencyService) Release(ctx context.Context, key string) error {
+	return s.storage.Release(ctx, key)
+}
+
+// Clean deletes the expired keys, the keys never retried are not deleted otherwise.
+func (s *idempotencyService) Clean(ctx context.Context) (int64, error) {
+	return s.storage.Clean(ctx, time.Now())
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gilab.com/estate-agency-api/internal/entity"
+	"github.com/go-playground/validator/v10"
+)
+
+type LeaseStorage interface {
+	GetAll(ctx context.Context, filter entity.LeaseFilter, page int, pageSize int) (leases []*entity.Lease, err error)
+	GetByID(ctx context.Context, id int) (lease *entity.Lease, err error)
+	Create(ctx context.Context, lease *entity.Lease, charges []*entity.RentCharge, audit *entity.AuditEntry) (id int64, err error)
+	Renew(ctx context.Context, lease *entity.Lease, charges []*entity.RentCharge, audit *entity.AuditEntry) error
+	Terminate(ctx context.Context, lease *entity.Lease, audit *entity.AuditEntry) error
+	Expiring(ctx context.Context, from entity.Date, to entity.Date) (leases []*entity.Lease, err error)
+}
+
+type leaseService struct {
+	storage    LeaseStorage
+	apartments ApartmentStorage
+	clients    ClientStorage
+	validate   *validator.Validate
+}
+
+func NewLeaseService(storage LeaseStorage, apartments ApartmentStorage, clients ClientStorage) *leaseService {
+	validate := validator.New()
+	validate.SetTagName("binding")
+
+	return &leaseService{storage: storage, apartments: apartments, clients: clients, validate: validate}
+}
+
+func (s *leaseService) GetAll(ctx context.Context, filter entity.LeaseFilter, page int, pageSize int) ([]*entity.Lease, error) {
+	return s.storage.GetAll(ctx, filter, page, pageSize)
+}
+
+func (s *leaseService) GetByID(ctx context.Context, id int) (*entity.Lease, error) {
+	return s.storage.GetByID(ctx, id)
+}
+
+// Create leases a rent listing to a client. Rent and deposit default to the listing's terms
+// and the lease must last at least the listing's minimum term. The rent charges of the lease are stored with it.
+func (s *leaseService) Create(ctx context.Context, lease *entity.Lease) (id int64, err error) {
+	if err = s.validate.Struct(lease); err != nil {
+		return 0, fmt.Errorf("%w: %s", entity.ErrValidation, err)
+	}
+	if lease.StartDate.IsZero() || !lease.EndDate.After(lease.StartDate.Time) {
+		return 0, fmt.Errorf("%w: end_date must be after start_date", entity.ErrValidation)
+	}
+
+	apartment, err := s.apartments.GetByID(ctx, lease.IDApartment)
+	if err != nil {
+		return
+	}
+	if apartment.ListingType != entity.ListingTypeRent {
+		return 0, fmt.Errorf("%w: apartment %d is not listed for rent", entity.ErrValidation, apartment.ID)
+	}
+	if _, err = s.clients.GetByID(ctx, lease.IDClient); err != nil {
+		return
+	}
+
+	if lease.MonthlyRent == 0 {
+		lease.MonthlyRent = apartment.MonthlyRent
+	}
+	if lease.Deposit == 0 {
+		lease.Deposit = apartment.Deposit
+	}
+	if apartment.MinTermMonths != 0 && lease.StartDate.AddDate(0, apartment.MinTermMonths, 0).After(lease.EndDate.Time) {
+		return 0, fmt.Errorf("%w: minimum term is %d months", entity.ErrValidation, apartment.MinTermMonths)
+	}
+
+	lease.Status = entity.LeaseStatusActive
+	lease.CreatedAt = time.Now().UTC().Truncate(time.Second)
+	lease.UpdatedAt = lease.CreatedAt
+
+	id, err = s.storage.Create(ctx, lease, lease.Charges(), newAuditEntry(ctx, entity.AuditEntityLease, 0, entity.AuditActionCreate, nil, lease))
+	if err != nil {
+		return
+	}
+	lease.ID = int(id)
+
+	return id, nil
+}
+
+// Renew extends an active lease to a later end date, optionally with a new rent, and charges the added periods.
+func (s *leaseService) Renew(ctx context.Context, id int, renewal entity.LeaseRenewal) (*entity.Lease, error) {
+	if err := s.validate.Struct(renewal); err != nil {
+		return nil, fmt.Errorf("%w: %s", entity.ErrValidation, err)
+	}
+
+	lease, err := s.storage.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if lease.Status != entity.LeaseStatusActive {
+		return nil, entity.ErrLeaseNotActive
+	}
+	if !renewal.EndDate.After(lease.EndDate.Time) {
+		return nil, fmt.Errorf("%w: end_date must be after the current end date %s", entity.ErrValidation, lease.EndDate)
+	}
+	before := *lease
+
+	lease.EndDate = renewal.EndDate
+	if renewal.MonthlyRent != 0 {
+		lease.MonthlyRent = renewal.MonthlyRent
+	}
+	lease.UpdatedAt = time.Now().UTC().Truncate(time.Second)
+
+	if err = s.storage.Renew(ctx, lease, lease.Charges(), newAuditEntry(ctx, entity.AuditEntityLease, id, entity.AuditActionRenew, &before, lease)); err != nil {
+		return nil, err
+	}
+
+	return lease, nil
+}
+
+// Terminate ends an active lease on the given day, which must fall within the lease, and cancels the unpaid
+// charges of later periods.
+func (s *leaseService) Terminate(ctx context.Context, id int, termination entity.LeaseTermination) (*entity.Lease, error) {
+	if err := s.validate.Struct(termination); err != nil {
+		return nil, fmt.Errorf("%w: %s", entity.ErrValidation, err)
+	}
+
+	lease, err := s.storage.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if lease.Status != entity.LeaseStatusActive {
+		return nil, entity.ErrLeaseNotActive
+	}
+
+	date := termination.Date
+	if date.IsZero() {
+		date = entity.Today()
+	}
+	if date.Before(lease.StartDate.Time) || date.After(lease.EndDate.Time) {
+		return nil, fmt.Errorf("%w: date must be between %s and %s", entity.ErrValidation, lease.StartDate, lease.EndDate)
+	}
+
+	before := *lease
+	now := time.Now().UTC().Truncate(time.Second)
+	lease.Status = entity.LeaseStatusTerminated
+	lease.EndDate = date
+	lease.TerminatedAt = &now
+	lease.TerminationReason = termination.Reason
+	lease.UpdatedAt = now
+
+	if err = s.storage.Terminate(ctx, lease, newAuditEntry(ctx, entity.AuditEntityLease, id, entity.AuditActionTerminate, &before, lease)); err != nil {
+		return nil, err
+	}
+
+	return lease, nil
+}
+
+// Expiring returns active leases that end within the next days days, today included.
+func (s *leaseService) Expiring(ctx context.Context, days int) ([]*entity.Lease, error) {
+	today := entity.Today()
+	return s.storage.Expiring(ctx, today, today.AddDays(days))
+}
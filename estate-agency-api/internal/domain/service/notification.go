@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gilab.com/estate-agency-api/internal/entity"
+)
+
+// notificationLease is how long a claimed notification stays hidden from other workers while it is being sent.
+const notificationLease = time.Minute
+
+type NotificationStorage interface {
+	Enqueue(ctx context.Context, notification *entity.Notification) error
+	Claim(ctx context.Context, limit int, lease time.Duration) (notifications []*entity.Notification, err error)
+	Update(ctx context.Context, notification *entity.Notification) error
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, notification *entity.Notification) error
+}
+
+type notificationService struct {
+	storage  NotificationStorage
+	notifier Notifier
+
+	maxAttempts int
+	retryDelay  time.Duration
+	batchSize   int
+}
+
+func NewNotificationService(storage NotificationStorage, notifier Notifier, maxAttempts int, retryDelay time.Duration, batchSize int) *notificationService {
+	return &notificationService{storage: storage, notifier: notifier, maxAttempts: maxAttempts, retryDelay: retryDelay, batchSize: batchSize}
+}
+
+// Queue stores the notification for delivery by Dispatch.
+func (s *notificationService) Queue(ctx context.Context, n *entity.Notification) error {
+	now := time.Now().UTC()
+	n.Status = entity.NotificationPending
+	n.NextAttemptAt = now
+	n.CreatedAt = now
+
+	return s.storage.Enqueue(ctx, n)
+}
+
+// Dispatch sends the queued notifications that are due. Failed ones are retried with a growing
+// delay and marked failed after maxAttempts.
+func (s *notificationService) Dispatch(ctx context.Context) (int, error) {
+	const op = "service.notification.Dispatch"
+
+	notifications, err := s.storage.Claim(ctx, s.batchSize, notificationLease)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	sent := 0
+	for _, n := range notifications {
+		if ctx.Err() != nil {
+			break
+		}
+
+		now := time.Now().UTC()
+		n.Attempts++
+		if err = s.notifier.Notify(ctx, n); err != nil {
+			n.LastError = err.Error()
+			if n.Attempts >= s.maxAttempts {
+				n.Status = entity.NotificationFailed
+			} else {
+				n.NextAttemptAt = now.Add(time.Duration(n.Attempts) * s.retryDelay)
+			}
+		} else {
+			n.Status = entity.NotificationSent
+			n.LastError = ""
+			n.SentAt = &now
+			sent++
+		}
+
+		if err = s.storage.Update(ctx, n); err != nil {
+			return sent, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return sent, nil
+}
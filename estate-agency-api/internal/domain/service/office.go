@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gilab.com/estate-agency-api/internal/entity"
+	"github.com/go-playground/validator/v10"
+)
+
+type OfficeStorage interface {
+	GetAll(ctx context.Context, page int, pageSize int) (offices []*entity.Office, err error)
+	GetByID(ctx context.Context, id int) (office *entity.Office, err error)
+	Create(ctx context.Context, office *entity.Office, audit *entity.AuditEntry) (id int64, err error)
+	Delete(ctx context.Context, id int, audit *entity.AuditEntry) error
+}
+
+type officeService struct {
+	storage  OfficeStorage
+	validate *validator.Validate
+}
+
+func NewOfficeService(storage OfficeStorage) *officeService {
+	validate := validator.New()
+	validate.SetTagName("binding")
+
+	return &officeService{storage: storage, validate: validate}
+}
+
+func (s *officeService) GetAll(ctx context.Context, page int, pageSize int) ([]*entity.Office, error) {
+	return s.storage.GetAll(ctx, page, pageSize)
+}
+
+func (s *officeService) GetByID(ctx context.Context, id int) (*entity.Office, error) {
+	return s.storage.GetByID(ctx, id)
+}
+
+func (s *officeService) Create(ctx context.Context, office *entity.Office) (id int64, err error) {
+	if err = agencyOnly(ctx); err != nil {
+		return
+	}
+	if err = s.validate.Struct(office); err != nil {
+		return 0, fmt.Errorf("%w: %s", entity.ErrValidation, err)
+	}
+	office.CreatedAt = time.Now().UTC().Truncate(time.Second)
+
+	id, err = s.storage.Create(ctx, office, newAuditEntry(ctx, entity.AuditEntityOffice, 0, entity.AuditActionCreate, nil, office))
+	if err != nil {
+		return
+	}
+	office.ID = int(id)
+
+	return id, nil
+}
+
+func (s *officeService) Delete(ctx context.Context, id int) error {
+	if err := agencyOnly(ctx); err != nil {
+		return err
+	}
+	before, err := s.storage.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return s.storage.Delete(ctx, id, newAuditEntry(ctx, entity.AuditEntityOffice, id, entity.AuditActionDelete, before, nil))
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gilab.com/estate-agency-api/internal/entity"
+)
+
+type OutboxStorage interface {
+	Pending(ctx context.Context, afterID int64, limit int) (events []*entity.Event, err error)
+	SaveDeliveries(ctx context.Context, deliveries []*entity.OutboxDelivery) error
+	MarkPublished(ctx context.Context, ids []int64) error
+	Clean(ctx context.Context, before time.Time) (aff int64, err error)
+}
+
+type EventPublisher interface {
+	Subscribers() []string
+	Deliver(ctx context.Context, subscriber string, event *entity.Event) error
+}
+
+type outboxService struct {
+	storage     OutboxStorage
+	publisher   EventPublisher
+	batchSize   int
+	maxAttempts int
+}
+
+func NewOutboxService(storage OutboxStorage, publisher EventPublisher, batchSize int, maxAttempts int) *outboxService {
+	return &outboxService{storage: storage, publisher: publisher, batchSize: batchSize, maxAttempts: maxAttempts}
+}
+
+// Relay delivers pending outbox events in order to every subscriber and marks an event as published once all
+// subscribers got it or gave up on it. Deliveries are tracked per subscriber: a failing subscriber does not get
+// the events after the failed one until it succeeds, which keeps its order without holding up the others, and
+// the delivery is marked dead after maxAttempts relays so that the subscriber moves on. A crash after a delivery
+// leads to a redelivery, so subscribers must tolerate duplicates.
+func (s *outboxService) Relay(ctx context.Context) (int, error) {
+	const op = "service.outbox.Relay"
+
+	var (
+		published int
+		after     int64
+		errs      []error
+	)
+	// Subscribers that failed an event during this relay.
+	blocked := make(map[string]bool)
+	subscribers := s.publisher.Subscribers()
+
+	for {
+		events, err := s.storage.Pending(ctx, after, s.batchSize)
+		if err != nil {
+			return published, fmt.Errorf("%s: %w", op, err)
+		}
+
+		var (
+			ids        []int64
+			deliveries []*entity.OutboxDelivery
+		)
+		for _, event := range events {
+			if err = ctx.Err(); err != nil {
+				break
+			}
+			after = event.ID
+
+			complete := true
+			var tried []*entity.OutboxDelivery
+			for _, subscriber := range subscribers {
+				delivery := event.Delivery(subscriber)
+				if delivery.Status != entity.OutboxDeliveryPending {
+					continue
+				}
+				if blocked[subscriber] {
+					complete = false
+					continue
+				}
+
+				delivery.Attempts++
+				deliverErr := s.publisher.Deliver(ctx, subscriber, event)
+				switch {
+				case deliverErr == nil:
+					delivery.Status = entity.OutboxDeliveryDelivered
+				case delivery.Attempts >= s.maxAttempts:
+					delivery.Status, delivery.LastError = entity.OutboxDeliveryDead, deliverErr.Error()
+					errs = append(errs, fmt.Errorf("event %d: %s: gave up after %d attempts: %w", event.ID, subscriber, delivery.Attempts, deliverErr))
+				default:
+					delivery.LastError = deliverErr.Error()
+					blocked[subscriber], complete = true, false
+					errs = append(errs, fmt.Errorf("event %d: %s: %w", event.ID, subscriber, deliverErr))
+				}
+				tried = append(tried, delivery)
+			}
+
+			if !complete {
+				deliveries = append(deliveries, tried...)
+				continue
+			}
+			// The deliveries of a published event only matter when one of them is dead.
+			for _, delivery := range tried {
+				if delivery.Status == entity.OutboxDeliveryDead {
+					deliveries = append(deliveries, delivery)
+				}
+			}
+			ids = append(ids, event.ID)
+		}
+
+		if err = s.storage.SaveDeliveries(ctx, deliveries); err != nil {
+			return published, fmt.Errorf("%s: %w", op, err)
+		}
+		if err = s.storage.MarkPublished(ctx, ids); err != nil {
+			return published, fmt.Errorf("%s: %w", op, err)
+		}
+		published += len(ids)
+
+		if err = ctx.Err(); err != nil {
+			errs = append(errs, err)
+		}
+		if err != nil || len(events) < s.batchSize {
+			if err = errors.Join(errs...); err != nil {
+				return published, fmt.Errorf("%s: %w", op, err)
+			}
+			return published, nil
+		}
+	}
+}
+
+// Clean removes events that were published before the given time.
+func (s *outboxService) Clean(ctx context.Context, before time.Time) (int64, error) {
+	return s.storage.Clean(ctx, before)
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"gilab.com/estate-agency-api/internal/entity"
+)
+
+// fakeOutboxStorage keeps the outbox in memory and hands out copies of the deliveries, like the database does.
+type fakeOutboxStorage struct {
+	events     []*entity.Event
+	published  map[int64]bool
+	deliveries map[int64]map[string]entity.OutboxDelivery
+}
+
+func newFakeOutboxStorage(n int) *fakeOutboxStorage {
+	s := &fakeOutboxStorage{published: make(map[int64]bool), deliveries: make(map[int64]map[string]entity.OutboxDelivery)}
+	for id := int64(1); id <= int64(n); id++ {
+		s.events = append(s.events, &entity.Event{ID: id, Type: entity.EventApartmentCreated})
+	}
+	return s
+}
+
+func (s *fakeOutboxStorage) Pending(ctx context.Context, afterID int64, limit int) ([]*entity.Event, error) {
+	var events []*entity.Event
+	for _, e := range s.events {
+		if s.published[e.ID] || e.ID <= afterID || len(events) == limit {
+			continue
+		}
+
+		event := *e
+		event.Deliveries = make(map[string]*entity.OutboxDelivery)
+		for subscriber, delivery := range s.deliveries[e.ID] {
+			delivery := delivery
+			event.Deliveries[subscriber] = &delivery
+		}
+		events = append(events, &event)
+	}
+	return events, nil
+}
+
+func (s *fakeOutboxStorage) SaveDeliveries(ctx context.Context, deliveries []*entity.OutboxDelivery) error {
+	for _, delivery := range deliveries {
+		if s.deliveries[delivery.EventID] == nil {
+			s.deliveries[delivery.EventID] = make(map[string]entity.OutboxDelivery)
+		}
+		s.deliveries[delivery.EventID][delivery.Subscriber] = *delivery
+	}
+	return nil
+}
+
+func (s *fakeOutboxStorage) MarkPublished(ctx context.Context, ids []int64) error {
+	for _, id := range ids {
+		s.published[id] = true
+	}
+	return nil
+}
+
+func (s *fakeOutboxStorage) Clean(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+// dead lists the dead deliveries as subscriber:event.
+func (s *fakeOutboxStorage) dead() []string {
+	var dead []string
+	for id, deliveries := range s.deliveries {
+		for subscriber, delivery := range deliveries {
+			if delivery.Status == entity.OutboxDeliveryDead {
+				dead = append(dead, fmt.Sprintf("%s:%d", subscriber, id))
+			}
+		}
+	}
+	sort.Strings(dead)
+	return dead
+}
+
+// fakePublisher fails the deliveries of an event to a subscriber the given number of times, or always when -1.
+type fakePublisher struct {
+	subscribers []string
+	failures    map[string]map[int64]int
+	received    map[string][]int64
+}
+
+func (p *fakePublisher) Subscribers() []string {
+	return p.subscribers
+}
+
+func (p *fakePublisher) Deliver(ctx context.Context, subscriber string, event *entity.Event) error {
+	if n := p.failures[subscriber][event.ID]; n != 0 {
+		if n > 0 {
+			p.failures[subscriber][event.ID]--
+		}
+		return errors.New("unavailable")
+	}
+	p.received[subscriber] = append(p.received[subscriber], event.ID)
+	return nil
+}
+
+func TestOutboxRelay(t *testing.T) {
+	tests := []struct {
+		name        string
+		events      int
+		batchSize   int
+		maxAttempts int
+		failures    map[string]map[int64]int
+		// wantPublished and wantErr are the results of the relays run one after another.
+		wantPublished []int
+		wantErr       []bool
+		wantReceived  map[string][]int64
+		wantDead      []string
+	}{
+		{
+			name:          "every subscriber succeeds",
+			events:        3,
+			batchSize:     10,
+			maxAttempts:   3,
+			wantPublished: []int{3},
+			wantErr:       []bool{false},
+			wantReceived:  map[string][]int64{"a": {1, 2, 3}, "b": {1, 2, 3}},
+		},
+		{
+			name:          "pages through the events",
+			events:        5,
+			batchSize:     2,
+			maxAttempts:   3,
+			wantPublished: []int{5},
+			wantErr:       []bool{false},
+			wantReceived:  map[string][]int64{"a": {1, 2, 3, 4, 5}, "b": {1, 2, 3, 4, 5}},
+		},
+		{
+			name:          "a failing subscriber keeps its order without holding up the others",
+			events:        3,
+			batchSize:     10,
+			maxAttempts:   3,
+			failures:      map[string]map[int64]int{"b": {2: 1}},
+			wantPublished: []int{1, 2},
+			wantErr:       []bool{true, false},
+			wantReceived:  map[string][]int64{"a": {1, 2, 3}, "b": {1, 2, 3}},
+		},
+		{
+			name:          "a subscriber failing every time gives up event after event",
+			events:        3,
+			batchSize:     10,
+			maxAttempts:   2,
+			failures:      map[string]map[int64]int{"b": {1: -1, 2: -1, 3: -1}},
+			wantPublished: []int{0, 1, 1, 1, 0},
+			wantErr:       []bool{true, true, true, true, false},
+			wantReceived:  map[string][]int64{"a": {1, 2, 3}},
+			wantDead:      []string{"b:1", "b:2", "b:3"},
+		},
+		{
+			name:          "a subscriber recovering before giving up",
+			events:        2,
+			batchSize:     1,
+			maxAttempts:   3,
+			failures:      map[string]map[int64]int{"a": {1: 2}},
+			wantPublished: []int{0, 0, 2},
+			wantErr:       []bool{true, true, false},
+			wantReceived:  map[string][]int64{"a": {1, 2}, "b": {1, 2}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := newFakeOutboxStorage(tt.events)
+			publisher := &fakePublisher{subscribers: []string{"a", "b"}, failures: tt.failures, received: make(map[string][]int64)}
+			s := NewOutboxService(storage, publisher, tt.batchSize, tt.maxAttempts)
+
+			for i, want := range tt.wantPublished {
+				published, err := s.Relay(context.Background())
+				if published != want {
+					t.Errorf("relay %d published %d events, want %d", i+1, published, want)
+				}
+				if (err != nil) != tt.wantErr[i] {
+					t.Errorf("relay %d error = %v, want error %v", i+1, err, tt.wantErr[i])
+				}
+			}
+
+			if len(storage.published) != tt.events {
+				t.Errorf("%d of %d events published", len(storage.published), tt.events)
+			}
+			for _, subscriber := range publisher.subscribers {
+				if got := publisher.received[subscriber]; !reflect.DeepEqual(got, tt.wantReceived[subscriber]) {
+					t.Errorf("%s received %v, want %v", subscriber, got, tt.wantReceived[subscriber])
+				}
+			}
+			if got := storage.dead(); !reflect.DeepEqual(got, tt.wantDead) {
+				t.Errorf("dead deliveries %v, want %v", got, tt.wantDead)
+			}
+		})
+	}
+}
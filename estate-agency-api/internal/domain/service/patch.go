@@ -0,0 +1,32 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"gilab.com/estate-agency-api/internal/entity"
+	"gilab.com/estate-agency-api/pkg/patch"
+)
+
+// applyPatch applies doc to the JSON form of current and decodes the merged result into patched.
+// Fields unknown to the resource are rejected instead of being silently dropped.
+func applyPatch(current any, doc patch.Document, patched any) error {
+	original, err := json.Marshal(current)
+	if err != nil {
+		return err
+	}
+
+	merged, err := doc.Apply(original)
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(merged))
+	decoder.DisallowUnknownFields()
+	if err = decoder.Decode(patched); err != nil {
+		return fmt.Errorf("%w: %s", entity.ErrValidation, err)
+	}
+
+	return nil
+}
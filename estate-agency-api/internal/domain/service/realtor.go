@@ -2,73 +2,177 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"os"
+	"time"
 
-	"gilab.com/estate-agency-api/internal/domain/entity"
+	"gilab.com/estate-agency-api/internal/entity"
+	"gilab.com/estate-agency-api/pkg/patch"
+	"github.com/go-playground/validator/v10"
 )
 
 type RealtorStorage interface {
-	GetAll(page int, pageSize int) (realtors []*entity.Realtor, err error)
-	GetByID(id int) (realtor *entity.Realtor, err error)
-	Create(realtor *entity.Realtor) (id int64, err error)
-	Update(realtor *entity.Realtor) (aff int64, err error)
-	Delete(id int) error
+	GetAll(ctx context.Context, page int, pageSize int) (realtors []*entity.Realtor, err error)
+	Each(ctx context.Context, fn func(realtor *entity.Realtor) error) error
+	GetByID(ctx context.Context, id int) (realtor *entity.Realtor, err error)
+	Create(ctx context.Context, realtor *entity.Realtor, events []*entity.Event, audit *entity.AuditEntry) (id int64, err error)
+	UpdateFields(ctx context.Context, id int, version int, fields map[string]any, events []*entity.Event, audit *entity.AuditEntry) (aff int64, err error)
+	Delete(ctx context.Context, id int, version int, events []*entity.Event, audit *entity.AuditEntry) error
+	// Restore sets the After of the audit entry to the restored realtor.
+	Restore(ctx context.Context, id int, events []*entity.Event, audit *entity.AuditEntry) error
+	GetDeleted(ctx context.Context, page int, pageSize int) (realtors []*entity.Realtor, err error)
+	Purge(ctx context.Context, before time.Time) (ids []int, err error)
 }
 
 type realtorService struct {
-	storage RealtorStorage
+	storage  RealtorStorage
+	images   ImageStorage
+	validate *validator.Validate
 }
 
-func NewRealtorService(storage RealtorStorage) *realtorService {
-	return &realtorService{storage: storage}
+func NewRealtorService(storage RealtorStorage, images ImageStorage) *realtorService {
+	return &realtorService{storage: storage, images: images, validate: validator.New()}
 }
 
 func (s *realtorService) GetAll(ctx context.Context, page int, pageSize int) ([]*entity.Realtor, error) {
-	return s.storage.GetAll(page, pageSize)
+	return s.storage.GetAll(ctx, page, pageSize)
+}
+
+func (s *realtorService) Export(ctx context.Context, fn func(realtor *entity.Realtor) error) error {
+	return s.storage.Each(ctx, fn)
 }
 
 func (s *realtorService) GetByID(ctx context.Context, id int) (realtor *entity.Realtor, err error) {
-	return s.storage.GetByID(id)
+	return s.storage.GetByID(ctx, id)
 }
 
+// Create stores the realtor. Realtors are managed by the agency, not on behalf of a realtor, and so are
+// their updates, deletions and restorations.
 func (s *realtorService) Create(ctx context.Context, realtor *entity.Realtor) (id int64, err error) {
-	return s.storage.Create(realtor)
+	if err = agencyOnly(ctx); err != nil {
+		return
+	}
+
+	return s.storage.Create(ctx, realtor, []*entity.Event{
+		entity.NewEvent(entity.EventRealtorCreated, entity.AggregateRealtor, 0, realtor),
+	}, newAuditEntry(ctx, entity.AuditEntityRealtor, 0, entity.AuditActionCreate, nil, realtor))
 }
 
-func (s *realtorService) Update(ctx context.Context, id int, realtor *entity.Realtor) (aff int64, err error) {
-	r, err := s.storage.GetByID(id)
-	if err != nil || len(r.FirstName) == 0 {
-		return aff, err
+// Patch applies a partial update to the realtor and stores only the changed columns. The update fails with
+// entity.ErrVersionConflict when the realtor changed since it was read.
+func (s *realtorService) Patch(ctx context.Context, id int, doc patch.Document) (*entity.Realtor, error) {
+	if err := agencyOnly(ctx); err != nil {
+		return nil, err
+	}
+
+	current, err := s.storage.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var realtor entity.Realtor
+	if err = applyPatch(current, doc, &realtor); err != nil {
+		return nil, err
+	}
+
+	if realtor.ID != current.ID || realtor.Version != current.Version || !sameID(realtor.IDTeam, current.IDTeam) {
+		return nil, fmt.Errorf("%w: id, version and id_team are read-only", entity.ErrValidation)
 	}
-	realtor.ID = id
+	if err = s.validate.Struct(realtor); err != nil {
+		return nil, fmt.Errorf("%w: %s", entity.ErrValidation, err)
+	}
+
+	fields := realtorChanges(current, &realtor)
+	if len(fields) == 0 {
+		return current, nil
+	}
+
+	events := []*entity.Event{
+		entity.NewEvent(entity.EventRealtorUpdated, entity.AggregateRealtor, id, entity.FieldsChange{ID: id, Changes: fields}),
+	}
+
+	realtor.Version++
+	audit := newAuditEntry(ctx, entity.AuditEntityRealtor, id, entity.AuditActionUpdate, current, &realtor)
+	if _, err = s.storage.UpdateFields(ctx, id, current.Version, fields, events, audit); err != nil {
+		return nil, err
+	}
+
+	return &realtor, nil
+}
 
-	if len(realtor.FirstName) == 0 {
-		realtor.FirstName = r.FirstName
+func realtorChanges(before *entity.Realtor, after *entity.Realtor) map[string]any {
+	fields := make(map[string]any)
+
+	if before.FirstName != after.FirstName {
+		fields["first_name"] = after.FirstName
 	}
-	if len(realtor.LastName) == 0 {
-		realtor.LastName = r.LastName
+	if before.LastName != after.LastName {
+		fields["last_name"] = after.LastName
 	}
-	if len(realtor.Phone) == 0 {
-		realtor.Phone = r.Phone
+	if before.Phone != after.Phone {
+		fields["phone"] = after.Phone
 	}
-	if len(realtor.Email) == 0 {
-		realtor.Email = r.Email
+	if before.Email != after.Email {
+		fields["email"] = after.Email
 	}
-	if realtor.Rating == 0 {
-		realtor.Rating = r.Rating
+	if before.Rating != after.Rating {
+		fields["rating"] = after.Rating
 	}
-	if realtor.Experience == 0 {
-		realtor.Experience = r.Experience
+	if before.Experience != after.Experience {
+		fields["experience"] = after.Experience
 	}
 
-	return s.storage.Update(realtor)
+	return fields
 }
 
 func (s *realtorService) Delete(ctx context.Context, id int) error {
-	if err := s.storage.Delete(id); err != nil {
+	if err := agencyOnly(ctx); err != nil {
+		return err
+	}
+
+	current, err := s.storage.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return s.storage.Delete(ctx, id, current.Version, []*entity.Event{
+		entity.NewEvent(entity.EventRealtorDeleted, entity.AggregateRealtor, id, entity.AggregateRef{ID: id}),
+	}, newAuditEntry(ctx, entity.AuditEntityRealtor, id, entity.AuditActionDelete, current, nil))
+}
+
+func (s *realtorService) Restore(ctx context.Context, id int) error {
+	if err := agencyOnly(ctx); err != nil {
 		return err
 	}
 
-	return os.Remove(fmt.Sprintf("./../../internal/images/realtor/%d.png", id))
+	return s.storage.Restore(ctx, id, []*entity.Event{
+		entity.NewEvent(entity.EventRealtorRestored, entity.AggregateRealtor, id, entity.AggregateRef{ID: id}),
+	}, newAuditEntry(ctx, entity.AuditEntityRealtor, id, entity.AuditActionRestore, nil, nil))
+}
+
+func (s *realtorService) GetDeleted(ctx context.Context, page int, pageSize int) ([]*entity.Realtor, error) {
+	return s.storage.GetDeleted(ctx, page, pageSize)
+}
+
+// Purge hard-deletes realtors soft-deleted before the given time together with their photos.
+func (s *realtorService) Purge(ctx context.Context, before time.Time) (int, error) {
+	const op = "service.realtor.Purge"
+
+	ids, err := s.storage.Purge(ctx, before)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// The rows are gone already, so a photo that fails to delete does not stop the others.
+	var errs []error
+	for _, id := range ids {
+		if err = s.images.Delete("realtor", id); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err = errors.Join(errs...); err != nil {
+		return len(ids), fmt.Errorf("%s: %w", op, err)
+	}
+
+	return len(ids), nil
 }
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gilab.com/estate-agency-api/internal/entity"
+	"github.com/go-playground/validator/v10"
+)
+
+type RentStorage interface {
+	GetCharges(ctx context.Context, leaseID int) (charges []*entity.RentCharge, err error)
+	GetPayments(ctx context.Context, leaseID int) (payments []*entity.RentPayment, err error)
+	RecordPayment(ctx context.Context, payment *entity.RentPayment, audit *entity.AuditEntry) (id int64, err error)
+	Balance(ctx context.Context, leaseID int, today entity.Date) (balance *entity.LeaseBalance, err error)
+	Overdue(ctx context.Context, today entity.Date, page int, pageSize int) (balances []*entity.LeaseBalance, err error)
+	MarkOverdue(ctx context.Context, today entity.Date) (int64, error)
+	DueReminders(ctx context.Context, before time.Time, limit int) (charges []*entity.RentCharge, err error)
+	MarkReminded(ctx context.Context, id int, at time.Time) error
+}
+
+type rentService struct {
+	storage          RentStorage
+	leases           LeaseStorage
+	clients          ClientStorage
+	notifications    NotificationQueue
+	reminderInterval time.Duration
+	batchSize        int
+	validate         *validator.Validate
+}
+
+func NewRentService(storage RentStorage, leases LeaseStorage, clients ClientStorage, notifications NotificationQueue, reminderInterval time.Duration, batchSize int) *rentService {
+	validate := validator.New()
+	validate.SetTagName("binding")
+
+	return &rentService{
+		storage:          storage,
+		leases:           leases,
+		clients:          clients,
+		notifications:    notifications,
+		reminderInterval: reminderInterval,
+		batchSize:        batchSize,
+		validate:         validate,
+	}
+}
+
+func (s *rentService) GetCharges(ctx context.Context, leaseID int) ([]*entity.RentCharge, error) {
+	if _, err := s.leases.GetByID(ctx, leaseID); err != nil {
+		return nil, err
+	}
+	return s.storage.GetCharges(ctx, leaseID)
+}
+
+func (s *rentService) GetPayments(ctx context.Context, leaseID int) ([]*entity.RentPayment, error) {
+	if _, err := s.leases.GetByID(ctx, leaseID); err != nil {
+		return nil, err
+	}
+	return s.storage.GetPayments(ctx, leaseID)
+}
+
+// RecordPayment stores a full or partial payment for the lease. A zero date means today.
+func (s *rentService) RecordPayment(ctx context.Context, payment *entity.RentPayment) (id int64, err error) {
+	if err = s.validate.Struct(payment); err != nil {
+		return 0, fmt.Errorf("%w: %s", entity.ErrValidation, err)
+	}
+	if _, err = s.leases.GetByID(ctx, payment.IDLease); err != nil {
+		return
+	}
+
+	if payment.PaidOn.IsZero() {
+		payment.PaidOn = entity.Today()
+	}
+	payment.CreatedAt = time.Now().UTC().Truncate(time.Second)
+
+	id, err = s.storage.RecordPayment(ctx, payment, newAuditEntry(ctx, entity.AuditEntityRentPayment, 0, entity.AuditActionCreate, nil, payment))
+	if err != nil {
+		return
+	}
+	payment.ID = int(id)
+
+	return id, nil
+}
+
+func (s *rentService) Balance(ctx context.Context, leaseID int) (*entity.LeaseBalance, error) {
+	today := entity.Today()
+
+	balance, err := s.storage.Balance(ctx, leaseID, today)
+	if err != nil {
+		return nil, err
+	}
+	completeBalance(balance, today)
+
+	return balance, nil
+}
+
+func (s *rentService) Overdue(ctx context.Context, page int, pageSize int) ([]*entity.LeaseBalance, error) {
+	today := entity.Today()
+
+	balances, err := s.storage.Overdue(ctx, today, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	for _, balance := range balances {
+		completeBalance(balance, today)
+	}
+
+	return balances, nil
+}
+
+func completeBalance(balance *entity.LeaseBalance, today entity.Date) {
+	balance.Balance = balance.Charged - balance.Paid
+	if balance.OldestDue != nil {
+		balance.OverdueDays = balance.OldestDue.DaysUntil(today)
+	}
+}
+
+// CheckOverdue marks unpaid charges past their due date as overdue and reminds the tenants,
+// at most once per reminder interval for each charge.
+func (s *rentService) CheckOverdue(ctx context.Context) (int, error) {
+	const op = "service.rent.CheckOverdue"
+
+	if _, err := s.storage.MarkOverdue(ctx, entity.Today()); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	charges, err := s.storage.DueReminders(ctx, now.Add(-s.reminderInterval), s.batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	reminded := 0
+	for _, charge := range charges {
+		if err = s.remind(ctx, charge); err != nil {
+			return reminded, fmt.Errorf("%s: %w", op, err)
+		}
+		if err = s.storage.MarkReminded(ctx, charge.ID, now); err != nil {
+			return reminded, fmt.Errorf("%s: %w", op, err)
+		}
+		reminded++
+	}
+
+	return reminded, nil
+}
+
+func (s *rentService) remind(ctx context.Context, charge *entity.RentCharge) error {
+	lease, err := s.leases.GetByID(ctx, charge.IDLease)
+	if err != nil {
+		return err
+	}
+	client, err := s.clients.GetByID(ctx, lease.IDClient)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.notifications.Queue(ctx, &entity.Notification{
+		IDClient:  client.ID,
+		Kind:      entity.NotificationRentReminder,
+		Recipient: client.Email,
+		Subject:   fmt.Sprintf("Rent payment overdue since %s", charge.DueDate),
+		Body:      reminderBody(client, lease, charge),
+		DedupKey:  fmt.Sprintf("rent:charge:%d:reminder:%d", charge.ID, charge.Reminders+1),
+	})
+}
+
+func reminderBody(client *entity.Client, lease *entity.Lease, charge *entity.RentCharge) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Hello, %s!\r\n\r\n", client.FirstName)
+	fmt.Fprintf(&b, "The rent for the period starting %s was due on %s.\r\n", charge.PeriodStart, charge.DueDate)
+	fmt.Fprintf(&b, "Amount: %d, paid: %d, outstanding: %d\r\n", charge.Amount, charge.Paid, charge.Amount-charge.Paid)
+	fmt.Fprintf(&b, "Lease ID: %d, apartment ID: %d\r\n", lease.ID, lease.IDApartment)
+
+	return b.String()
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gilab.com/estate-agency-api/internal/entity"
+	"github.com/go-playground/validator/v10"
+)
+
+type SearchStorage interface {
+	GetByClient(ctx context.Context, clientID int) (searches []*entity.SavedSearch, err error)
+	Create(ctx context.Context, search *entity.SavedSearch, audit *entity.AuditEntry) (id int64, err error)
+	Delete(ctx context.Context, clientID int, id int, audit *entity.AuditEntry) error
+	Matching(ctx context.Context, apartment *entity.Apartment) (searches []*entity.SavedSearch, err error)
+}
+
+type NotificationQueue interface {
+	Queue(ctx context.Context, notification *entity.Notification) error
+}
+
+type searchService struct {
+	storage       SearchStorage
+	clients       ClientStorage
+	apartments    ApartmentStorage
+	notifications NotificationQueue
+	validate      *validator.Validate
+}
+
+func NewSearchService(storage SearchStorage, clients ClientStorage, apartments ApartmentStorage, notifications NotificationQueue) *searchService {
+	validate := validator.New()
+	validate.SetTagName("binding")
+
+	return &searchService{storage: storage, clients: clients, apartments: apartments, notifications: notifications, validate: validate}
+}
+
+func (s *searchService) GetByClient(ctx context.Context, clientID int) ([]*entity.SavedSearch, error) {
+	return s.storage.GetByClient(ctx, clientID)
+}
+
+func (s *searchService) Create(ctx context.Context, search *entity.SavedSearch) (id int64, err error) {
+	if err = s.validate.Struct(search); err != nil {
+		return 0, fmt.Errorf("%w: %s", entity.ErrValidation, err)
+	}
+	if search.MaxPrice != 0 && search.MinPrice > search.MaxPrice || search.MaxSquare != 0 && search.MinSquare > search.MaxSquare {
+		return 0, fmt.Errorf("%w: minimum exceeds maximum", entity.ErrValidation)
+	}
+	if _, err = s.clients.GetByID(ctx, search.IDClient); err != nil {
+		return
+	}
+	search.CreatedAt = time.Now().UTC().Truncate(time.Second)
+
+	id, err = s.storage.Create(ctx, search, newAuditEntry(ctx, entity.AuditEntitySavedSearch, 0, entity.AuditActionCreate, nil, search))
+	if err != nil {
+		return
+	}
+	search.ID = int(id)
+
+	return id, nil
+}
+
+func (s *searchService) Delete(ctx context.Context, clientID int, id int) error {
+	searches, err := s.storage.GetByClient(ctx, clientID)
+	if err != nil {
+		return err
+	}
+
+	var before *entity.SavedSearch
+	for _, search := range searches {
+		if search.ID == id {
+			before = search
+			break
+		}
+	}
+	if before == nil {
+		return sql.ErrNoRows
+	}
+
+	return s.storage.Delete(ctx, clientID, id, newAuditEntry(ctx, entity.AuditEntitySavedSearch, id, entity.AuditActionDelete, before, nil))
+}
+
+// Match is registered as an event subscriber. It evaluates newly created and re-priced apartments
+// against the saved searches and queues a notification for every matching search.
+func (s *searchService) Match(ctx context.Context, event *entity.Event) error {
+	const op = "service.search.Match"
+
+	var apartment *entity.Apartment
+	switch event.Type {
+	case entity.EventApartmentCreated:
+		apartment = &entity.Apartment{}
+		if err := json.Unmarshal(event.Payload, apartment); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	case entity.EventApartmentPriceChanged:
+		current, err := s.apartments.GetByID(ctx, event.AggregateID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		apartment = current
+	default:
+		return nil
+	}
+
+	if apartment.Status != entity.ApartmentStatusPublished {
+		return nil
+	}
+
+	searches, err := s.storage.Matching(ctx, apartment)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	for _, search := range searches {
+		client, err := s.clients.GetByID(ctx, search.IDClient)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		err = s.notifications.Queue(ctx, &entity.Notification{
+			IDClient:  client.ID,
+			Kind:      entity.NotificationSearchMatch,
+			Recipient: client.Email,
+			Subject:   matchSubject(search, event),
+			Body:      matchBody(client, apartment),
+			DedupKey:  fmt.Sprintf("search:%d:event:%d", search.ID, event.ID),
+		})
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return nil
+}
+
+func matchSubject(search *entity.SavedSearch, event *entity.Event) string {
+	name := search.Name
+	if len(name) == 0 {
+		name = fmt.Sprintf("#%d", search.ID)
+	}
+	if event.Type == entity.EventApartmentPriceChanged {
+		return fmt.Sprintf("Price changed for an apartment matching your search %q", name)
+	}
+	return fmt.Sprintf("New apartment matching your search %q", name)
+}
+
+func matchBody(client *entity.Client, apartment *entity.Apartment) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Hello, %s!\r\n\r\n", client.FirstName)
+	fmt.Fprintf(&b, "%s\r\n", apartment.Title)
+	fmt.Fprintf(&b, "%s, %s\r\n", apartment.City, apartment.Address)
+	fmt.Fprintf(&b, "Price: %d\r\n", apartment.Price)
+	fmt.Fprintf(&b, "Rooms: %d, square: %d\r\n", apartment.Rooms, apartment.Square)
+	fmt.Fprintf(&b, "Apartment ID: %d\r\n", apartment.ID)
+
+	return b.String()
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"gilab.com/estate-agency-api/internal/entity"
+	"gilab.com/estate-agency-api/pkg/reqctx"
+)
+
+// streamSubscriberBuffer is how many events a subscriber may lag behind before it is disconnected.
+const streamSubscriberBuffer = 64
+
+type streamEntry struct {
+	event     *entity.Event
+	apartment *entity.Apartment
+}
+
+type streamSubscriber struct {
+	filter entity.ApartmentFilter
+	events chan *entity.Event
+}
+
+// agencyStream holds the buffered events and the subscribers of one agency.
+type agencyStream struct {
+	buffer      []streamEntry
+	next        int
+	lastID      int64
+	evictedID   int64
+	subscribers map[*streamSubscriber]struct{}
+}
+
+// streamService fans apartment events out to live subscribers of the same agency and keeps the latest of them
+// in a bounded buffer per agency for clients resuming with Last-Event-ID.
+type streamService struct {
+	mu         sync.Mutex
+	bufferSize int
+	streams    map[int]*agencyStream
+}
+
+func NewStreamService(bufferSize int) *streamService {
+	return &streamService{bufferSize: bufferSize, streams: make(map[int]*agencyStream)}
+}
+
+// stream returns the stream of the agency the context is scoped to. The caller must hold s.mu.
+func (s *streamService) stream(ctx context.Context) (*agencyStream, error) {
+	agencyID, ok := reqctx.Tenant(ctx)
+	if !ok {
+		return nil, entity.ErrTenantRequired
+	}
+
+	stream, ok := s.streams[agencyID]
+	if !ok {
+		stream = &agencyStream{buffer: make([]streamEntry, 0, s.bufferSize), subscribers: make(map[*streamSubscriber]struct{})}
+		s.streams[agencyID] = stream
+	}
+
+	return stream, nil
+}
+
+func streamed(eventType string) bool {
+	switch eventType {
+	case entity.EventApartmentCreated, entity.EventApartmentUpdated, entity.EventApartmentDeleted,
+		entity.EventApartmentStatusChanged, entity.EventApartmentRestored:
+		return true
+	}
+	return false
+}
+
+// streamSnapshot extracts the apartment state carried by the event; events without one are not filtered.
+func streamSnapshot(event *entity.Event) *entity.Apartment {
+	var snapshot struct {
+		entity.Apartment
+		Snapshot *entity.Apartment `json:"apartment"`
+	}
+
+	if err := json.Unmarshal(event.Payload, &snapshot); err != nil {
+		return nil
+	}
+	if event.Type == entity.EventApartmentCreated {
+		return &snapshot.Apartment
+	}
+	return snapshot.Snapshot
+}
+
+// Publish is registered as an event subscriber. Redelivered events are dropped by their ID.
+func (s *streamService) Publish(ctx context.Context, event *entity.Event) error {
+	if event.AggregateType != entity.AggregateApartment || !streamed(event.Type) {
+		return nil
+	}
+	entry := streamEntry{event: event, apartment: streamSnapshot(event)}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stream, err := s.stream(ctx)
+	if err != nil {
+		return err
+	}
+
+	if event.ID <= stream.lastID {
+		return nil
+	}
+	stream.lastID = event.ID
+
+	switch {
+	case cap(stream.buffer) == 0:
+		stream.evictedID = event.ID
+	case len(stream.buffer) < cap(stream.buffer):
+		stream.buffer = append(stream.buffer, entry)
+	default:
+		stream.evictedID = stream.buffer[stream.next].event.ID
+		stream.buffer[stream.next] = entry
+		stream.next = (stream.next + 1) % len(stream.buffer)
+	}
+
+	for sub := range stream.subscribers {
+		if entry.apartment != nil && !sub.filter.Matches(entry.apartment) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			delete(stream.subscribers, sub)
+			close(sub.events)
+		}
+	}
+
+	return nil
+}
+
+// Subscribe registers a subscriber for events of the agency matching the filter and replays the buffered
+// events newer than lastEventID. A zero lastEventID starts with live events only.
+func (s *streamService) Subscribe(ctx context.Context, filter entity.ApartmentFilter, lastEventID int64) (*entity.Subscription, error) {
+	sub := &streamSubscriber{filter: filter, events: make(chan *entity.Event, streamSubscriberBuffer)}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stream, err := s.stream(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription := &entity.Subscription{Events: sub.events}
+	if lastEventID > 0 {
+		subscription.Resync = lastEventID < stream.evictedID
+		for i := range stream.buffer {
+			entry := stream.buffer[(stream.next+i)%len(stream.buffer)]
+			if entry.event.ID <= lastEventID {
+				continue
+			}
+			if entry.apartment != nil && !filter.Matches(entry.apartment) {
+				continue
+			}
+			subscription.Replay = append(subscription.Replay, entry.event)
+		}
+	}
+
+	stream.subscribers[sub] = struct{}{}
+	subscription.Cancel = func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if _, ok := stream.subscribers[sub]; ok {
+			delete(stream.subscribers, sub)
+			close(sub.events)
+		}
+	}
+
+	return subscription, nil
+}
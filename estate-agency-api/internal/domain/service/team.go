@@ -0,0 +1,255 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"gilab.com/estate-agency-api/internal/entity"
+	"gilab.com/estate-agency-api/pkg/reqctx"
+	"github.com/go-playground/validator/v10"
+)
+
+type TeamStorage interface {
+	GetAll(ctx context.Context, filter entity.TeamFilter, page int, pageSize int) (teams []*entity.Team, err error)
+	GetByID(ctx context.Context, id int) (team *entity.Team, err error)
+	Create(ctx context.Context, team *entity.Team, audit *entity.AuditEntry) (id int64, err error)
+	Update(ctx context.Context, team *entity.Team, audit *entity.AuditEntry) error
+	Delete(ctx context.Context, id int, audit *entity.AuditEntry) error
+	Members(ctx context.Context, teamID int) (realtors []*entity.Realtor, err error)
+	SetRealtorTeam(ctx context.Context, realtorID int, teamID *int, audit *entity.AuditEntry) error
+	Stats(ctx context.Context, teamID int) (stats []*entity.MemberStats, err error)
+}
+
+type teamService struct {
+	storage    TeamStorage
+	offices    OfficeStorage
+	realtors   RealtorStorage
+	apartments ApartmentStorage
+	validate   *validator.Validate
+}
+
+func NewTeamService(storage TeamStorage, offices OfficeStorage, realtors RealtorStorage, apartments ApartmentStorage) *teamService {
+	validate := validator.New()
+	validate.SetTagName("binding")
+
+	return &teamService{storage: storage, offices: offices, realtors: realtors, apartments: apartments, validate: validate}
+}
+
+func (s *teamService) GetAll(ctx context.Context, filter entity.TeamFilter, page int, pageSize int) ([]*entity.Team, error) {
+	return s.storage.GetAll(ctx, filter, page, pageSize)
+}
+
+func (s *teamService) GetByID(ctx context.Context, id int) (*entity.Team, error) {
+	return s.storage.GetByID(ctx, id)
+}
+
+func (s *teamService) Create(ctx context.Context, team *entity.Team) (id int64, err error) {
+	if err = agencyOnly(ctx); err != nil {
+		return
+	}
+	if err = s.check(ctx, team); err != nil {
+		return
+	}
+	team.CreatedAt = time.Now().UTC().Truncate(time.Second)
+
+	id, err = s.storage.Create(ctx, team, newAuditEntry(ctx, entity.AuditEntityTeam, 0, entity.AuditActionCreate, nil, team))
+	if err != nil {
+		return
+	}
+	team.ID = int(id)
+
+	return id, nil
+}
+
+// Update replaces the office, the name and the lead of the team.
+func (s *teamService) Update(ctx context.Context, id int, team *entity.Team) (*entity.Team, error) {
+	if err := agencyOnly(ctx); err != nil {
+		return nil, err
+	}
+	current, err := s.storage.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.check(ctx, team); err != nil {
+		return nil, err
+	}
+	team.ID = current.ID
+	team.CreatedAt = current.CreatedAt
+
+	if err = s.storage.Update(ctx, team, newAuditEntry(ctx, entity.AuditEntityTeam, id, entity.AuditActionUpdate, current, team)); err != nil {
+		return nil, err
+	}
+
+	return team, nil
+}
+
+// check validates the team and makes sure its office and lead belong to the agency.
+func (s *teamService) check(ctx context.Context, team *entity.Team) error {
+	if err := s.validate.Struct(team); err != nil {
+		return fmt.Errorf("%w: %s", entity.ErrValidation, err)
+	}
+
+	_, err := s.offices.GetByID(ctx, team.IDOffice)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: office %d does not exist", entity.ErrValidation, team.IDOffice)
+	}
+	if err != nil {
+		return err
+	}
+
+	if team.IDLead != nil {
+		_, err = s.realtors.GetByID(ctx, *team.IDLead)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: realtor %d does not exist", entity.ErrValidation, *team.IDLead)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *teamService) Delete(ctx context.Context, id int) error {
+	if err := agencyOnly(ctx); err != nil {
+		return err
+	}
+	before, err := s.storage.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return s.storage.Delete(ctx, id, newAuditEntry(ctx, entity.AuditEntityTeam, id, entity.AuditActionDelete, before, nil))
+}
+
+func (s *teamService) Members(ctx context.Context, teamID int) ([]*entity.Realtor, error) {
+	if _, err := s.storage.GetByID(ctx, teamID); err != nil {
+		return nil, err
+	}
+	return s.storage.Members(ctx, teamID)
+}
+
+// AddMember moves the realtor to the team, out of the team they were in before.
+func (s *teamService) AddMember(ctx context.Context, teamID int, realtorID int) error {
+	if err := agencyOnly(ctx); err != nil {
+		return err
+	}
+	if _, err := s.storage.GetByID(ctx, teamID); err != nil {
+		return err
+	}
+	before, err := s.realtors.GetByID(ctx, realtorID)
+	if err != nil {
+		return err
+	}
+
+	updated := *before
+	updated.IDTeam = &teamID
+	updated.Version++
+
+	return s.storage.SetRealtorTeam(ctx, realtorID, &teamID, newAuditEntry(ctx, entity.AuditEntityRealtor, realtorID, entity.AuditActionUpdate, before, &updated))
+}
+
+// RemoveMember takes the realtor out of the team; a realtor who is not a member is reported as sql.ErrNoRows.
+func (s *teamService) RemoveMember(ctx context.Context, teamID int, realtorID int) error {
+	if err := agencyOnly(ctx); err != nil {
+		return err
+	}
+	before, err := s.realtors.GetByID(ctx, realtorID)
+	if err != nil {
+		return err
+	}
+	if before.IDTeam == nil || *before.IDTeam != teamID {
+		return sql.ErrNoRows
+	}
+
+	updated := *before
+	updated.IDTeam = nil
+	updated.Version++
+
+	return s.storage.SetRealtorTeam(ctx, realtorID, nil, newAuditEntry(ctx, entity.AuditEntityRealtor, realtorID, entity.AuditActionUpdate, before, &updated))
+}
+
+// Listings returns the apartments of the team's members that pass the filter.
+func (s *teamService) Listings(ctx context.Context, teamID int, filter entity.ApartmentFilter, page int, pageSize int) ([]*entity.Apartment, error) {
+	if _, err := s.storage.GetByID(ctx, teamID); err != nil {
+		return nil, err
+	}
+	filter.IDTeam = teamID
+
+	return s.apartments.GetAll(ctx, filter, page, pageSize)
+}
+
+// Stats aggregates the listings and deals of the team's members.
+func (s *teamService) Stats(ctx context.Context, teamID int) (*entity.TeamStats, error) {
+	if _, err := s.storage.GetByID(ctx, teamID); err != nil {
+		return nil, err
+	}
+
+	members, err := s.storage.Stats(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &entity.TeamStats{IDTeam: teamID, Members: members}
+	for _, member := range members {
+		stats.Total.Add(member.ListingStats)
+	}
+
+	return stats, nil
+}
+
+// CanManage is the access policy for listings. Requests that do not act on behalf of a realtor are trusted within
+// their agency. A realtor manages their own listings and, as a team lead, the listings of the team's members;
+// anything else is entity.ErrForbidden.
+func (s *teamService) CanManage(ctx context.Context, realtorID int) error {
+	actor, ok := reqctx.Realtor(ctx)
+	if !ok || actor == realtorID {
+		return nil
+	}
+	if realtorID == 0 {
+		return entity.ErrForbidden
+	}
+
+	realtor, err := s.realtors.GetByID(ctx, realtorID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.ErrForbidden
+	}
+	if err != nil {
+		return err
+	}
+	if realtor.IDTeam == nil {
+		return entity.ErrForbidden
+	}
+
+	team, err := s.storage.GetByID(ctx, *realtor.IDTeam)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.ErrForbidden
+	}
+	if err != nil {
+		return err
+	}
+	if !sameID(team.IDLead, &actor) {
+		return entity.ErrForbidden
+	}
+
+	return nil
+}
+
+// agencyOnly rejects requests made on behalf of a realtor. Realtors manage listings, while the realtors,
+// teams and offices of the agency are managed by its staff.
+func agencyOnly(ctx context.Context) error {
+	if _, ok := reqctx.Realtor(ctx); ok {
+		return entity.ErrForbidden
+	}
+	return nil
+}
+
+func sameID(a *int, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
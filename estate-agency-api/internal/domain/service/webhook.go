@@ -0,0 +1,325 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"gilab.com/estate-agency-api/internal/entity"
+	"gilab.com/estate-agency-api/pkg/patch"
+	"gilab.com/estate-agency-api/pkg/webhook"
+	"github.com/go-playground/validator/v10"
+)
+
+// webhookLeaseMargin is added to the time a batch of deliveries takes to send, to cover the storage updates.
+const webhookLeaseMargin = time.Minute
+
+type WebhookStorage interface {
+	GetAll(ctx context.Context, page int, pageSize int) (webhooks []*entity.Webhook, err error)
+	GetActive(ctx context.Context) (webhooks []*entity.Webhook, err error)
+	GetByID(ctx context.Context, id int) (webhook *entity.Webhook, err error)
+	Create(ctx context.Context, webhook *entity.Webhook, audit *entity.AuditEntry) (id int64, err error)
+	UpdateFields(ctx context.Context, id int, fields map[string]any, audit *entity.AuditEntry) (aff int64, err error)
+	Delete(ctx context.Context, id int, audit *entity.AuditEntry) error
+	EnqueueDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error
+	ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) (deliveries []*entity.WebhookDelivery, err error)
+	UpdateDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error
+	GetDeliveries(ctx context.Context, webhookID int, filter entity.WebhookDeliveryFilter, page int, pageSize int) (deliveries []*entity.WebhookDelivery, err error)
+}
+
+type WebhookSender interface {
+	Send(ctx context.Context, url string, headers map[string]string, body []byte) (statusCode int, err error)
+}
+
+// auditedWebhook is the webhook as it is recorded in the audit log, without its secret.
+type auditedWebhook struct {
+	*entity.Webhook
+	Secret string `json:"secret,omitempty"`
+}
+
+type webhookService struct {
+	storage  WebhookStorage
+	sender   WebhookSender
+	validate *validator.Validate
+
+	maxAttempts int
+	backoffBase time.Duration
+	backoffMax  time.Duration
+	batchSize   int
+	// lease is how long claimed deliveries stay hidden from other workers while they are being sent. The batch
+	// is sent one delivery after another, so it covers every send of the batch timing out.
+	lease time.Duration
+}
+
+func NewWebhookService(storage WebhookStorage, sender WebhookSender, timeout time.Duration, maxAttempts int, backoffBase time.Duration, backoffMax time.Duration, batchSize int) *webhookService {
+	validate := validator.New()
+	validate.SetTagName("binding")
+
+	return &webhookService{
+		storage:     storage,
+		sender:      sender,
+		validate:    validate,
+		maxAttempts: maxAttempts,
+		backoffBase: backoffBase,
+		backoffMax:  backoffMax,
+		batchSize:   batchSize,
+		lease:       time.Duration(batchSize)*timeout + webhookLeaseMargin,
+	}
+}
+
+func (s *webhookService) GetAll(ctx context.Context, page int, pageSize int) ([]*entity.Webhook, error) {
+	return s.storage.GetAll(ctx, page, pageSize)
+}
+
+func (s *webhookService) GetByID(ctx context.Context, id int) (*entity.Webhook, error) {
+	return s.storage.GetByID(ctx, id)
+}
+
+// Create stores the subscription and generates a secret when none is given.
+func (s *webhookService) Create(ctx context.Context, w *entity.Webhook) (id int64, err error) {
+	if err = s.check(w); err != nil {
+		return
+	}
+	if len(w.Secret) == 0 {
+		if w.Secret, err = newWebhookSecret(); err != nil {
+			return
+		}
+	}
+	w.CreatedAt = time.Now().UTC().Truncate(time.Second)
+
+	id, err = s.storage.Create(ctx, w, newAuditEntry(ctx, entity.AuditEntityWebhook, 0, entity.AuditActionCreate, nil, auditedWebhook{Webhook: w}))
+	if err != nil {
+		return
+	}
+	w.ID = int(id)
+
+	return id, nil
+}
+
+func (s *webhookService) Patch(ctx context.Context, id int, doc patch.Document) (*entity.Webhook, error) {
+	current, err := s.storage.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var w entity.Webhook
+	if err = applyPatch(current, doc, &w); err != nil {
+		return nil, err
+	}
+
+	if w.ID != current.ID || !w.CreatedAt.Equal(current.CreatedAt) {
+		return nil, fmt.Errorf("%w: id and created_at are read-only", entity.ErrValidation)
+	}
+	if err = s.check(&w); err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]any)
+	if w.URL != current.URL {
+		fields["url"] = w.URL
+	}
+	if fmt.Sprint(w.EventTypes) != fmt.Sprint(current.EventTypes) {
+		eventTypes, err := json.Marshal(w.EventTypes)
+		if err != nil {
+			return nil, err
+		}
+		fields["event_types"] = eventTypes
+	}
+	if w.Secret != current.Secret {
+		fields["secret"] = w.Secret
+	}
+	if w.Active != current.Active {
+		fields["active"] = w.Active
+	}
+	if len(fields) == 0 {
+		return current, nil
+	}
+
+	audit := newAuditEntry(ctx, entity.AuditEntityWebhook, id, entity.AuditActionUpdate, auditedWebhook{Webhook: current}, auditedWebhook{Webhook: &w})
+	if _, err = s.storage.UpdateFields(ctx, id, fields, audit); err != nil {
+		return nil, err
+	}
+
+	return &w, nil
+}
+
+func (s *webhookService) Delete(ctx context.Context, id int) error {
+	before, err := s.storage.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return s.storage.Delete(ctx, id, newAuditEntry(ctx, entity.AuditEntityWebhook, id, entity.AuditActionDelete, auditedWebhook{Webhook: before}, nil))
+}
+
+func (s *webhookService) GetDeliveries(ctx context.Context, webhookID int, filter entity.WebhookDeliveryFilter, page int, pageSize int) ([]*entity.WebhookDelivery, error) {
+	return s.storage.GetDeliveries(ctx, webhookID, filter, page, pageSize)
+}
+
+func (s *webhookService) check(w *entity.Webhook) error {
+	if err := s.validate.Struct(w); err != nil {
+		return fmt.Errorf("%w: %s", entity.ErrValidation, err)
+	}
+	if err := webhook.CheckURL(w.URL); err != nil {
+		return fmt.Errorf("%w: url: %s", entity.ErrValidation, err)
+	}
+
+	for _, t := range w.EventTypes {
+		if !knownEventType(t) {
+			return fmt.Errorf("%w: unknown event type %q", entity.ErrValidation, t)
+		}
+	}
+
+	return nil
+}
+
+func knownEventType(eventType string) bool {
+	if eventType == entity.WebhookAllEvents {
+		return true
+	}
+	for _, t := range entity.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func newWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+// Enqueue schedules a delivery of the event to every active webhook subscribed to its type.
+// It is registered as an event subscriber and may see the same event more than once.
+func (s *webhookService) Enqueue(ctx context.Context, event *entity.Event) error {
+	const op = "service.webhook.Enqueue"
+
+	webhooks, err := s.storage.GetActive(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	var body []byte
+	now := time.Now().UTC()
+	for _, w := range webhooks {
+		if !w.Accepts(event.Type) {
+			continue
+		}
+
+		if body == nil {
+			if body, err = json.Marshal(event); err != nil {
+				return fmt.Errorf("%s: %w", op, err)
+			}
+		}
+
+		err = s.storage.EnqueueDelivery(ctx, &entity.WebhookDelivery{
+			WebhookID:     w.ID,
+			EventID:       event.ID,
+			EventType:     event.Type,
+			Payload:       body,
+			Status:        entity.WebhookDeliveryPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		})
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return nil
+}
+
+// Deliver sends the deliveries that are due. A failed attempt is retried with exponential backoff
+// and the delivery becomes dead after maxAttempts failures.
+func (s *webhookService) Deliver(ctx context.Context) (int, error) {
+	const op = "service.webhook.Deliver"
+
+	deliveries, err := s.storage.ClaimDeliveries(ctx, s.batchSize, s.lease)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	webhooks := make(map[int]*entity.Webhook)
+	delivered := 0
+	for _, delivery := range deliveries {
+		if ctx.Err() != nil {
+			break
+		}
+
+		w, ok := webhooks[delivery.WebhookID]
+		if !ok {
+			if w, err = s.storage.GetByID(ctx, delivery.WebhookID); err != nil {
+				return delivered, fmt.Errorf("%s: %w", op, err)
+			}
+			webhooks[delivery.WebhookID] = w
+		}
+
+		s.attempt(ctx, w, delivery)
+		if err = s.storage.UpdateDelivery(ctx, delivery); err != nil {
+			return delivered, fmt.Errorf("%s: %w", op, err)
+		}
+		if delivery.Status == entity.WebhookDeliveryDelivered {
+			delivered++
+		}
+	}
+
+	return delivered, nil
+}
+
+func (s *webhookService) attempt(ctx context.Context, w *entity.Webhook, delivery *entity.WebhookDelivery) {
+	if !w.Active {
+		delivery.Status = entity.WebhookDeliveryDead
+		delivery.LastError = "webhook is inactive"
+		return
+	}
+
+	now := time.Now().UTC()
+	delivery.Attempts++
+
+	headers := map[string]string{
+		"Content-Type":          "application/json",
+		webhook.EventHeader:     delivery.EventType,
+		webhook.DeliveryHeader:  strconv.FormatInt(delivery.ID, 10),
+		webhook.TimestampHeader: strconv.FormatInt(now.Unix(), 10),
+		webhook.SignatureHeader: webhook.Sign(w.Secret, now.Unix(), delivery.Payload),
+	}
+
+	statusCode, err := s.sender.Send(ctx, w.URL, headers, delivery.Payload)
+	delivery.LastStatusCode = statusCode
+	switch {
+	case err != nil:
+		delivery.LastError = err.Error()
+	case statusCode < 200 || statusCode > 299:
+		delivery.LastError = fmt.Sprintf("unexpected status %d", statusCode)
+	default:
+		delivery.LastError = ""
+		delivery.Status = entity.WebhookDeliveryDelivered
+		delivery.DeliveredAt = &now
+		return
+	}
+
+	if delivery.Attempts >= s.maxAttempts {
+		delivery.Status = entity.WebhookDeliveryDead
+		return
+	}
+	delivery.NextAttemptAt = now.Add(s.backoff(delivery.Attempts))
+}
+
+func (s *webhookService) backoff(attempts int) time.Duration {
+	delay := s.backoffBase
+	for i := 1; i < attempts && delay < s.backoffMax; i++ {
+		delay *= 2
+	}
+	if delay > s.backoffMax {
+		delay = s.backoffMax
+	}
+	return delay
+}
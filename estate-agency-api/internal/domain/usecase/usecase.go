@@ -2,60 +2,270 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
 	"gilab.com/estate-agency-api/internal/entity"
+	"gilab.com/estate-agency-api/pkg/patch"
+	"gilab.com/estate-agency-api/pkg/reqctx"
+	"go.opentelemetry.io/otel"
 )
 
+var tracer = otel.Tracer("gilab.com/estate-agency-api/internal/domain/usecase")
+
 type ApartmentService interface {
-	GetAll(ctx context.Context, page int, pageSize int) (apartments []*entity.Apartment, err error)
+	GetAll(ctx context.Context, filter entity.ApartmentFilter, page int, pageSize int) (apartments []*entity.Apartment, err error)
+	Export(ctx context.Context, filter entity.ApartmentFilter, fn func(apartment *entity.Apartment) error) error
 	GetByID(ctx context.Context, id int) (apartment *entity.Apartment, err error)
 	Create(ctx context.Context, apartment *entity.Apartment) (id int64, err error)
-	Update(ctx context.Context, id int, apartment *entity.Apartment) (aff int64, err error)
-	Delete(ctx context.Context, id int) error
+	Patch(ctx context.Context, id int, version int, doc patch.Document) (apartment *entity.Apartment, err error)
+	Delete(ctx context.Context, id int, version int) error
+	Restore(ctx context.Context, id int) error
+	GetDeleted(ctx context.Context, page int, pageSize int) (apartments []*entity.Apartment, err error)
+	Purge(ctx context.Context, before time.Time) (purged int, err error)
+	CountPublished(ctx context.Context) (counts []*entity.ListingCount, err error)
 }
 
 type RealtorService interface {
 	GetAll(ctx context.Context, page int, pageSize int) (realtors []*entity.Realtor, err error)
+	Export(ctx context.Context, fn func(realtor *entity.Realtor) error) error
 	GetByID(ctx context.Context, id int) (realtor *entity.Realtor, err error)
 	Create(ctx context.Context, realtor *entity.Realtor) (id int64, err error)
-	Update(ctx context.Context, id int, realtor *entity.Realtor) (aff int64, err error)
+	Patch(ctx context.Context, id int, doc patch.Document) (realtor *entity.Realtor, err error)
+	Delete(ctx context.Context, id int) error
+	Restore(ctx context.Context, id int) error
+	GetDeleted(ctx context.Context, page int, pageSize int) (realtors []*entity.Realtor, err error)
+	Purge(ctx context.Context, before time.Time) (purged int, err error)
+}
+
+type FeedService interface {
+	YRL(ctx context.Context) (feed []byte, err error)
+	Invalidate()
+}
+
+type AuditService interface {
+	Find(ctx context.Context, filter entity.AuditFilter, page int, pageSize int) (entries []*entity.AuditEntry, err error)
+}
+
+type WebhookService interface {
+	GetAll(ctx context.Context, page int, pageSize int) (webhooks []*entity.Webhook, err error)
+	GetByID(ctx context.Context, id int) (webhook *entity.Webhook, err error)
+	Create(ctx context.Context, webhook *entity.Webhook) (id int64, err error)
+	Patch(ctx context.Context, id int, doc patch.Document) (webhook *entity.Webhook, err error)
 	Delete(ctx context.Context, id int) error
+	GetDeliveries(ctx context.Context, webhookID int, filter entity.WebhookDeliveryFilter, page int, pageSize int) (deliveries []*entity.WebhookDelivery, err error)
+}
+
+type StreamService interface {
+	Subscribe(ctx context.Context, filter entity.ApartmentFilter, lastEventID int64) (subscription *entity.Subscription, err error)
+}
+
+type ClientService interface {
+	GetAll(ctx context.Context, page int, pageSize int) (clients []*entity.Client, err error)
+	GetByID(ctx context.Context, id int) (client *entity.Client, err error)
+	Create(ctx context.Context, client *entity.Client) (id int64, err error)
+	Patch(ctx context.Context, id int, doc patch.Document) (client *entity.Client, err error)
+	Delete(ctx context.Context, id int) error
+}
+
+type SearchService interface {
+	GetByClient(ctx context.Context, clientID int) (searches []*entity.SavedSearch, err error)
+	Create(ctx context.Context, search *entity.SavedSearch) (id int64, err error)
+	Delete(ctx context.Context, clientID int, id int) error
+}
+
+type FavoriteService interface {
+	Add(ctx context.Context, favorite *entity.Favorite) error
+	Remove(ctx context.Context, clientID int, apartmentID int) error
+	GetByClient(ctx context.Context, clientID int, page int, pageSize int) (favorites []*entity.FavoriteView, err error)
+	TopByRealtor(ctx context.Context, realtorID int, limit int) (counts []*entity.FavoriteCount, err error)
+}
+
+type LeaseService interface {
+	GetAll(ctx context.Context, filter entity.LeaseFilter, page int, pageSize int) (leases []*entity.Lease, err error)
+	GetByID(ctx context.Context, id int) (lease *entity.Lease, err error)
+	Create(ctx context.Context, lease *entity.Lease) (id int64, err error)
+	Renew(ctx context.Context, id int, renewal entity.LeaseRenewal) (lease *entity.Lease, err error)
+	Terminate(ctx context.Context, id int, termination entity.LeaseTermination) (lease *entity.Lease, err error)
+	Expiring(ctx context.Context, days int) (leases []*entity.Lease, err error)
+}
+
+type RentService interface {
+	GetCharges(ctx context.Context, leaseID int) (charges []*entity.RentCharge, err error)
+	GetPayments(ctx context.Context, leaseID int) (payments []*entity.RentPayment, err error)
+	RecordPayment(ctx context.Context, payment *entity.RentPayment) (id int64, err error)
+	Balance(ctx context.Context, leaseID int) (balance *entity.LeaseBalance, err error)
+	Overdue(ctx context.Context, page int, pageSize int) (balances []*entity.LeaseBalance, err error)
+}
+
+type APIKeyService interface {
+	GetAll(ctx context.Context, page int, pageSize int) (keys []*entity.APIKey, err error)
+	GetByID(ctx context.Context, id int) (key *entity.APIKey, err error)
+	Create(ctx context.Context, key *entity.APIKey) (id int64, err error)
+	Revoke(ctx context.Context, id int) (key *entity.APIKey, err error)
+	Authenticate(ctx context.Context, token string) (key *entity.APIKey, err error)
+}
+
+type AgencyService interface {
+	GetAll(ctx context.Context, page int, pageSize int) (agencies []*entity.Agency, err error)
+	All(ctx context.Context) (agencies []*entity.Agency, err error)
+	GetByID(ctx context.Context, id int) (agency *entity.Agency, err error)
+	Create(ctx context.Context, agency *entity.Agency) (id int64, err error)
+	Resolve(ctx context.Context, slug string) (id int, err error)
+}
+
+type OfficeService interface {
+	GetAll(ctx context.Context, page int, pageSize int) (offices []*entity.Office, err error)
+	GetByID(ctx context.Context, id int) (office *entity.Office, err error)
+	Create(ctx context.Context, office *entity.Office) (id int64, err error)
+	Delete(ctx context.Context, id int) error
+}
+
+type TeamService interface {
+	GetAll(ctx context.Context, filter entity.TeamFilter, page int, pageSize int) (teams []*entity.Team, err error)
+	GetByID(ctx context.Context, id int) (team *entity.Team, err error)
+	Create(ctx context.Context, team *entity.Team) (id int64, err error)
+	Update(ctx context.Context, id int, team *entity.Team) (updated *entity.Team, err error)
+	Delete(ctx context.Context, id int) error
+	Members(ctx context.Context, teamID int) (realtors []*entity.Realtor, err error)
+	AddMember(ctx context.Context, teamID int, realtorID int) error
+	RemoveMember(ctx context.Context, teamID int, realtorID int) error
+	Listings(ctx context.Context, teamID int, filter entity.ApartmentFilter, page int, pageSize int) (apartments []*entity.Apartment, err error)
+	Stats(ctx context.Context, teamID int) (stats *entity.TeamStats, err error)
 }
 
 type usecase struct {
 	apartmentService ApartmentService
 	realtorService   RealtorService
+	feedService      FeedService
+	auditService     AuditService
+	webhookService   WebhookService
+	streamService    StreamService
+	clientService    ClientService
+	searchService    SearchService
+	favoriteService  FavoriteService
+	leaseService     LeaseService
+	rentService      RentService
+	apiKeyService    APIKeyService
+	agencyService    AgencyService
+	officeService    OfficeService
+	teamService      TeamService
 }
 
-func NewUsecase(apartmentService ApartmentService, realtorService RealtorService) *usecase {
-	return &usecase{apartmentService: apartmentService, realtorService: realtorService}
+func NewUsecase(apartmentService ApartmentService, realtorService RealtorService, feedService FeedService, auditService AuditService, webhookService WebhookService, streamService StreamService, clientService ClientService, searchService SearchService, favoriteService FavoriteService, leaseService LeaseService, rentService RentService, apiKeyService APIKeyService, agencyService AgencyService, officeService OfficeService, teamService TeamService) *usecase {
+	return &usecase{
+		apartmentService: apartmentService,
+		realtorService:   realtorService,
+		feedService:      feedService,
+		auditService:     auditService,
+		webhookService:   webhookService,
+		streamService:    streamService,
+		clientService:    clientService,
+		searchService:    searchService,
+		favoriteService:  favoriteService,
+		leaseService:     leaseService,
+		rentService:      rentService,
+		apiKeyService:    apiKeyService,
+		agencyService:    agencyService,
+		officeService:    officeService,
+		teamService:      teamService,
+	}
 }
 
 func (u *usecase) GetAllRealtor(ctx context.Context, page int, pageSize int) ([]*entity.Realtor, error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetAllRealtor")
+	defer span.End()
+
 	return u.realtorService.GetAll(ctx, page, pageSize)
 }
 
+func (u *usecase) ExportRealtors(ctx context.Context, fn func(realtor *entity.Realtor) error) error {
+	ctx, span := tracer.Start(ctx, "usecase.ExportRealtors")
+	defer span.End()
+
+	return u.realtorService.Export(ctx, fn)
+}
+
 func (u *usecase) GetRealtorByID(ctx context.Context, id int) (realtor *entity.Realtor, err error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetRealtorByID")
+	defer span.End()
+
 	return u.realtorService.GetByID(ctx, id)
 }
 
 func (u *usecase) CreateRealtor(ctx context.Context, realtor *entity.Realtor) (id int64, err error) {
-	return u.realtorService.Create(ctx, realtor)
+	ctx, span := tracer.Start(ctx, "usecase.CreateRealtor")
+	defer span.End()
+
+	id, err = u.realtorService.Create(ctx, realtor)
+	if err != nil {
+		return
+	}
+	realtor.ID = int(id)
+
+	return id, nil
 }
 
-func (u *usecase) UpdateRealtor(ctx context.Context, id int, realtor *entity.Realtor) (aff int64, err error) {
-	return u.realtorService.Update(ctx, id, realtor)
+func (u *usecase) UpdateRealtor(ctx context.Context, id int, doc patch.Document) (realtor *entity.Realtor, err error) {
+	ctx, span := tracer.Start(ctx, "usecase.UpdateRealtor")
+	defer span.End()
+
+	defer u.feedService.Invalidate()
+
+	return u.realtorService.Patch(ctx, id, doc)
 }
 
 func (u *usecase) DeleteRealtor(ctx context.Context, id int) error {
+	ctx, span := tracer.Start(ctx, "usecase.DeleteRealtor")
+	defer span.End()
+
+	defer u.feedService.Invalidate()
+
 	return u.realtorService.Delete(ctx, id)
 }
 
-func (u *usecase) GetAllApartment(ctx context.Context, page int, pageSize int) ([]*entity.Apartment, error) {
-	return u.apartmentService.GetAll(ctx, page, pageSize)
+func (u *usecase) RestoreRealtor(ctx context.Context, id int) error {
+	ctx, span := tracer.Start(ctx, "usecase.RestoreRealtor")
+	defer span.End()
+
+	defer u.feedService.Invalidate()
+
+	return u.realtorService.Restore(ctx, id)
+}
+
+func (u *usecase) GetDeletedRealtors(ctx context.Context, page int, pageSize int) ([]*entity.Realtor, error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetDeletedRealtors")
+	defer span.End()
+
+	return u.realtorService.GetDeleted(ctx, page, pageSize)
+}
+
+func (u *usecase) GetAllApartment(ctx context.Context, filter entity.ApartmentFilter, page int, pageSize int) ([]*entity.Apartment, error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetAllApartment")
+	defer span.End()
+
+	return u.apartmentService.GetAll(ctx, filter, page, pageSize)
+}
+
+func (u *usecase) ExportApartments(ctx context.Context, filter entity.ApartmentFilter, fn func(apartment *entity.Apartment) error) error {
+	ctx, span := tracer.Start(ctx, "usecase.ExportApartments")
+	defer span.End()
+
+	return u.apartmentService.Export(ctx, filter, fn)
+}
+
+func (u *usecase) StreamApartments(ctx context.Context, filter entity.ApartmentFilter, lastEventID int64) (*entity.Subscription, error) {
+	ctx, span := tracer.Start(ctx, "usecase.StreamApartments")
+	defer span.End()
+
+	return u.streamService.Subscribe(ctx, filter, lastEventID)
 }
 
 func (u *usecase) GetApartmentByID(ctx context.Context, id int) (apartment *entity.Apartment, realtor *entity.Realtor, err error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetApartmentByID")
+	defer span.End()
+
 	apartment, err = u.apartmentService.GetByID(ctx, id)
 	if err != nil {
 		return
@@ -65,13 +275,469 @@ func (u *usecase) GetApartmentByID(ctx context.Context, id int) (apartment *enti
 }
 
 func (u *usecase) CreateApartment(ctx context.Context, apartment *entity.Apartment) (id int64, err error) {
-	return u.apartmentService.Create(ctx, apartment)
+	ctx, span := tracer.Start(ctx, "usecase.CreateApartment")
+	defer span.End()
+
+	defer u.feedService.Invalidate()
+
+	id, err = u.apartmentService.Create(ctx, apartment)
+	if err != nil {
+		return
+	}
+	apartment.ID = int(id)
+
+	return id, nil
+}
+
+func (u *usecase) UpdateApartment(ctx context.Context, id int, version int, doc patch.Document) (apartment *entity.Apartment, err error) {
+	ctx, span := tracer.Start(ctx, "usecase.UpdateApartment")
+	defer span.End()
+
+	defer u.feedService.Invalidate()
+
+	return u.apartmentService.Patch(ctx, id, version, doc)
+}
+
+func (u *usecase) DeleteApartment(ctx context.Context, id int, version int) error {
+	ctx, span := tracer.Start(ctx, "usecase.DeleteApartment")
+	defer span.End()
+
+	defer u.feedService.Invalidate()
+
+	return u.apartmentService.Delete(ctx, id, version)
+}
+
+func (u *usecase) RestoreApartment(ctx context.Context, id int) error {
+	ctx, span := tracer.Start(ctx, "usecase.RestoreApartment")
+	defer span.End()
+
+	defer u.feedService.Invalidate()
+
+	return u.apartmentService.Restore(ctx, id)
+}
+
+func (u *usecase) GetDeletedApartments(ctx context.Context, page int, pageSize int) ([]*entity.Apartment, error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetDeletedApartments")
+	defer span.End()
+
+	return u.apartmentService.GetDeleted(ctx, page, pageSize)
+}
+
+// PurgeDeleted hard-deletes apartments and realtors that were soft-deleted before the given time.
+func (u *usecase) PurgeDeleted(ctx context.Context, before time.Time) (apartments int, realtors int, err error) {
+	ctx, span := tracer.Start(ctx, "usecase.PurgeDeleted")
+	defer span.End()
+
+	apartments, err = u.apartmentService.Purge(ctx, before)
+	if err != nil {
+		return
+	}
+	realtors, err = u.realtorService.Purge(ctx, before)
+	return apartments, realtors, err
+}
+
+func (u *usecase) GetYRLFeed(ctx context.Context) ([]byte, error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetYRLFeed")
+	defer span.End()
+
+	return u.feedService.YRL(ctx)
+}
+
+func (u *usecase) GetAuditLog(ctx context.Context, filter entity.AuditFilter, page int, pageSize int) ([]*entity.AuditEntry, error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetAuditLog")
+	defer span.End()
+
+	return u.auditService.Find(ctx, filter, page, pageSize)
+}
+
+func (u *usecase) GetAllWebhooks(ctx context.Context, page int, pageSize int) ([]*entity.Webhook, error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetAllWebhooks")
+	defer span.End()
+
+	return u.webhookService.GetAll(ctx, page, pageSize)
+}
+
+func (u *usecase) GetWebhookByID(ctx context.Context, id int) (*entity.Webhook, error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetWebhookByID")
+	defer span.End()
+
+	return u.webhookService.GetByID(ctx, id)
+}
+
+func (u *usecase) CreateWebhook(ctx context.Context, webhook *entity.Webhook) (int64, error) {
+	ctx, span := tracer.Start(ctx, "usecase.CreateWebhook")
+	defer span.End()
+
+	return u.webhookService.Create(ctx, webhook)
+}
+
+func (u *usecase) UpdateWebhook(ctx context.Context, id int, doc patch.Document) (*entity.Webhook, error) {
+	ctx, span := tracer.Start(ctx, "usecase.UpdateWebhook")
+	defer span.End()
+
+	return u.webhookService.Patch(ctx, id, doc)
+}
+
+func (u *usecase) DeleteWebhook(ctx context.Context, id int) error {
+	ctx, span := tracer.Start(ctx, "usecase.DeleteWebhook")
+	defer span.End()
+
+	return u.webhookService.Delete(ctx, id)
+}
+
+func (u *usecase) GetWebhookDeliveries(ctx context.Context, webhookID int, filter entity.WebhookDeliveryFilter, page int, pageSize int) ([]*entity.WebhookDelivery, error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetWebhookDeliveries")
+	defer span.End()
+
+	return u.webhookService.GetDeliveries(ctx, webhookID, filter, page, pageSize)
 }
 
-func (u *usecase) UpdateApartment(ctx context.Context, id int, apartment *entity.Apartment) (aff int64, err error) {
-	return u.apartmentService.Update(ctx, id, apartment)
+func (u *usecase) GetAllClients(ctx context.Context, page int, pageSize int) ([]*entity.Client, error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetAllClients")
+	defer span.End()
+
+	return u.clientService.GetAll(ctx, page, pageSize)
+}
+
+func (u *usecase) GetClientByID(ctx context.Context, id int) (*entity.Client, error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetClientByID")
+	defer span.End()
+
+	return u.clientService.GetByID(ctx, id)
+}
+
+func (u *usecase) CreateClient(ctx context.Context, client *entity.Client) (int64, error) {
+	ctx, span := tracer.Start(ctx, "usecase.CreateClient")
+	defer span.End()
+
+	return u.clientService.Create(ctx, client)
+}
+
+func (u *usecase) UpdateClient(ctx context.Context, id int, doc patch.Document) (*entity.Client, error) {
+	ctx, span := tracer.Start(ctx, "usecase.UpdateClient")
+	defer span.End()
+
+	return u.clientService.Patch(ctx, id, doc)
+}
+
+func (u *usecase) DeleteClient(ctx context.Context, id int) error {
+	ctx, span := tracer.Start(ctx, "usecase.DeleteClient")
+	defer span.End()
+
+	return u.clientService.Delete(ctx, id)
+}
+
+func (u *usecase) GetSavedSearches(ctx context.Context, clientID int) ([]*entity.SavedSearch, error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetSavedSearches")
+	defer span.End()
+
+	return u.searchService.GetByClient(ctx, clientID)
 }
 
-func (u *usecase) DeleteApartment(ctx context.Context, id int) error {
-	return u.apartmentService.Delete(ctx, id)
+func (u *usecase) CreateSavedSearch(ctx context.Context, search *entity.SavedSearch) (int64, error) {
+	ctx, span := tracer.Start(ctx, "usecase.CreateSavedSearch")
+	defer span.End()
+
+	return u.searchService.Create(ctx, search)
+}
+
+func (u *usecase) DeleteSavedSearch(ctx context.Context, clientID int, id int) error {
+	ctx, span := tracer.Start(ctx, "usecase.DeleteSavedSearch")
+	defer span.End()
+
+	return u.searchService.Delete(ctx, clientID, id)
+}
+
+func (u *usecase) AddFavorite(ctx context.Context, favorite *entity.Favorite) error {
+	ctx, span := tracer.Start(ctx, "usecase.AddFavorite")
+	defer span.End()
+
+	return u.favoriteService.Add(ctx, favorite)
+}
+
+func (u *usecase) RemoveFavorite(ctx context.Context, clientID int, apartmentID int) error {
+	ctx, span := tracer.Start(ctx, "usecase.RemoveFavorite")
+	defer span.End()
+
+	return u.favoriteService.Remove(ctx, clientID, apartmentID)
+}
+
+func (u *usecase) GetFavorites(ctx context.Context, clientID int, page int, pageSize int) ([]*entity.FavoriteView, error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetFavorites")
+	defer span.End()
+
+	return u.favoriteService.GetByClient(ctx, clientID, page, pageSize)
+}
+
+func (u *usecase) GetMostFavorited(ctx context.Context, realtorID int, limit int) ([]*entity.FavoriteCount, error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetMostFavorited")
+	defer span.End()
+
+	return u.favoriteService.TopByRealtor(ctx, realtorID, limit)
+}
+
+func (u *usecase) GetAllLeases(ctx context.Context, filter entity.LeaseFilter, page int, pageSize int) ([]*entity.Lease, error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetAllLeases")
+	defer span.End()
+
+	return u.leaseService.GetAll(ctx, filter, page, pageSize)
+}
+
+func (u *usecase) GetLeaseByID(ctx context.Context, id int) (*entity.Lease, error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetLeaseByID")
+	defer span.End()
+
+	return u.leaseService.GetByID(ctx, id)
+}
+
+func (u *usecase) CreateLease(ctx context.Context, lease *entity.Lease) (id int64, err error) {
+	ctx, span := tracer.Start(ctx, "usecase.CreateLease")
+	defer span.End()
+
+	return u.leaseService.Create(ctx, lease)
+}
+
+func (u *usecase) RenewLease(ctx context.Context, id int, renewal entity.LeaseRenewal) (lease *entity.Lease, err error) {
+	ctx, span := tracer.Start(ctx, "usecase.RenewLease")
+	defer span.End()
+
+	return u.leaseService.Renew(ctx, id, renewal)
+}
+
+func (u *usecase) TerminateLease(ctx context.Context, id int, termination entity.LeaseTermination) (lease *entity.Lease, err error) {
+	ctx, span := tracer.Start(ctx, "usecase.TerminateLease")
+	defer span.End()
+
+	return u.leaseService.Terminate(ctx, id, termination)
+}
+
+func (u *usecase) GetExpiringLeases(ctx context.Context, days int) ([]*entity.Lease, error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetExpiringLeases")
+	defer span.End()
+
+	return u.leaseService.Expiring(ctx, days)
+}
+
+func (u *usecase) GetRentSchedule(ctx context.Context, leaseID int) ([]*entity.RentCharge, error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetRentSchedule")
+	defer span.End()
+
+	return u.rentService.GetCharges(ctx, leaseID)
+}
+
+func (u *usecase) GetRentPayments(ctx context.Context, leaseID int) ([]*entity.RentPayment, error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetRentPayments")
+	defer span.End()
+
+	return u.rentService.GetPayments(ctx, leaseID)
+}
+
+func (u *usecase) RecordRentPayment(ctx context.Context, payment *entity.RentPayment) (id int64, err error) {
+	ctx, span := tracer.Start(ctx, "usecase.RecordRentPayment")
+	defer span.End()
+
+	return u.rentService.RecordPayment(ctx, payment)
+}
+
+func (u *usecase) GetLeaseBalance(ctx context.Context, leaseID int) (*entity.LeaseBalance, error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetLeaseBalance")
+	defer span.End()
+
+	return u.rentService.Balance(ctx, leaseID)
+}
+
+func (u *usecase) GetOverdueLeases(ctx context.Context, page int, pageSize int) ([]*entity.LeaseBalance, error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetOverdueLeases")
+	defer span.End()
+
+	return u.rentService.Overdue(ctx, page, pageSize)
+}
+
+func (u *usecase) CountPublishedListings(ctx context.Context) ([]*entity.ListingCount, error) {
+	ctx, span := tracer.Start(ctx, "usecase.CountPublishedListings")
+	defer span.End()
+
+	return u.apartmentService.CountPublished(ctx)
+}
+
+func (u *usecase) GetAllAPIKeys(ctx context.Context, page int, pageSize int) ([]*entity.APIKey, error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetAllAPIKeys")
+	defer span.End()
+
+	return u.apiKeyService.GetAll(ctx, page, pageSize)
+}
+
+func (u *usecase) GetAPIKeyByID(ctx context.Context, id int) (*entity.APIKey, error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetAPIKeyByID")
+	defer span.End()
+
+	return u.apiKeyService.GetByID(ctx, id)
+}
+
+func (u *usecase) CreateAPIKey(ctx context.Context, key *entity.APIKey) (id int64, err error) {
+	ctx, span := tracer.Start(ctx, "usecase.CreateAPIKey")
+	defer span.End()
+
+	return u.apiKeyService.Create(ctx, key)
+}
+
+func (u *usecase) RevokeAPIKey(ctx context.Context, id int) (key *entity.APIKey, err error) {
+	ctx, span := tracer.Start(ctx, "usecase.RevokeAPIKey")
+	defer span.End()
+
+	return u.apiKeyService.Revoke(ctx, id)
+}
+
+func (u *usecase) AuthenticateAPIKey(ctx context.Context, token string) (*entity.APIKey, error) {
+	ctx, span := tracer.Start(ctx, "usecase.AuthenticateAPIKey")
+	defer span.End()
+
+	return u.apiKeyService.Authenticate(ctx, token)
+}
+
+func (u *usecase) GetAllAgencies(ctx context.Context, page int, pageSize int) ([]*entity.Agency, error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetAllAgencies")
+	defer span.End()
+
+	return u.agencyService.GetAll(ctx, page, pageSize)
+}
+
+func (u *usecase) GetAgencyByID(ctx context.Context, id int) (*entity.Agency, error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetAgencyByID")
+	defer span.End()
+
+	return u.agencyService.GetByID(ctx, id)
+}
+
+func (u *usecase) CreateAgency(ctx context.Context, agency *entity.Agency) (id int64, err error) {
+	ctx, span := tracer.Start(ctx, "usecase.CreateAgency")
+	defer span.End()
+
+	return u.agencyService.Create(ctx, agency)
+}
+
+func (u *usecase) ResolveAgency(ctx context.Context, slug string) (int, error) {
+	ctx, span := tracer.Start(ctx, "usecase.ResolveAgency")
+	defer span.End()
+
+	return u.agencyService.Resolve(ctx, slug)
+}
+
+// ForEachAgency runs fn once for every agency with the agency set as the tenant of ctx,
+// which is how background jobs reach the storage that is scoped to a single agency.
+func (u *usecase) ForEachAgency(ctx context.Context, fn func(ctx context.Context) error) error {
+	agencies, err := u.agencyService.All(ctx)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, agency := range agencies {
+		if ctx.Err() != nil {
+			break
+		}
+		if err = fn(reqctx.WithTenant(ctx, agency.ID)); err != nil {
+			errs = append(errs, fmt.Errorf("agency %d: %w", agency.ID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (u *usecase) GetAllOffices(ctx context.Context, page int, pageSize int) ([]*entity.Office, error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetAllOffices")
+	defer span.End()
+
+	return u.officeService.GetAll(ctx, page, pageSize)
+}
+
+func (u *usecase) GetOfficeByID(ctx context.Context, id int) (*entity.Office, error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetOfficeByID")
+	defer span.End()
+
+	return u.officeService.GetByID(ctx, id)
+}
+
+func (u *usecase) CreateOffice(ctx context.Context, office *entity.Office) (id int64, err error) {
+	ctx, span := tracer.Start(ctx, "usecase.CreateOffice")
+	defer span.End()
+
+	return u.officeService.Create(ctx, office)
+}
+
+func (u *usecase) DeleteOffice(ctx context.Context, id int) error {
+	ctx, span := tracer.Start(ctx, "usecase.DeleteOffice")
+	defer span.End()
+
+	return u.officeService.Delete(ctx, id)
+}
+
+func (u *usecase) GetAllTeams(ctx context.Context, filter entity.TeamFilter, page int, pageSize int) ([]*entity.Team, error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetAllTeams")
+	defer span.End()
+
+	return u.teamService.GetAll(ctx, filter, page, pageSize)
+}
+
+func (u *usecase) GetTeamByID(ctx context.Context, id int) (*entity.Team, error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetTeamByID")
+	defer span.End()
+
+	return u.teamService.GetByID(ctx, id)
+}
+
+func (u *usecase) CreateTeam(ctx context.Context, team *entity.Team) (id int64, err error) {
+	ctx, span := tracer.Start(ctx, "usecase.CreateTeam")
+	defer span.End()
+
+	return u.teamService.Create(ctx, team)
+}
+
+func (u *usecase) UpdateTeam(ctx context.Context, id int, team *entity.Team) (updated *entity.Team, err error) {
+	ctx, span := tracer.Start(ctx, "usecase.UpdateTeam")
+	defer span.End()
+
+	return u.teamService.Update(ctx, id, team)
+}
+
+func (u *usecase) DeleteTeam(ctx context.Context, id int) error {
+	ctx, span := tracer.Start(ctx, "usecase.DeleteTeam")
+	defer span.End()
+
+	return u.teamService.Delete(ctx, id)
+}
+
+func (u *usecase) GetTeamMembers(ctx context.Context, teamID int) ([]*entity.Realtor, error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetTeamMembers")
+	defer span.End()
+
+	return u.teamService.Members(ctx, teamID)
+}
+
+func (u *usecase) AddTeamMember(ctx context.Context, teamID int, realtorID int) error {
+	ctx, span := tracer.Start(ctx, "usecase.AddTeamMember")
+	defer span.End()
+
+	return u.teamService.AddMember(ctx, teamID, realtorID)
+}
+
+func (u *usecase) RemoveTeamMember(ctx context.Context, teamID int, realtorID int) error {
+	ctx, span := tracer.Start(ctx, "usecase.RemoveTeamMember")
+	defer span.End()
+
+	return u.teamService.RemoveMember(ctx, teamID, realtorID)
+}
+
+func (u *usecase) GetTeamListings(ctx context.Context, teamID int, filter entity.ApartmentFilter, page int, pageSize int) ([]*entity.Apartment, error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetTeamListings")
+	defer span.End()
+
+	return u.teamService.Listings(ctx, teamID, filter, page, pageSize)
+}
+
+func (u *usecase) GetTeamStats(ctx context.Context, teamID int) (*entity.TeamStats, error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetTeamStats")
+	defer span.End()
+
+	return u.teamService.Stats(ctx, teamID)
 }
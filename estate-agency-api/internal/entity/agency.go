@@ -0,0 +1,20 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrAgencySlugExists = errors.New("agency with this slug already exists")
+	ErrTenantRequired   = errors.New("agency of the request is not set")
+)
+
+// Agency is a tenant. Realtors, apartments, clients and everything attached to them belong to exactly one agency,
+// which is served on the subdomain named by Slug.
+type Agency struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name" binding:"required,max=100"`
+	Slug      string    `json:"slug" binding:"required,max=63,hostname_rfc1123,excludesall=."`
+	CreatedAt time.Time `json:"created_at"`
+}
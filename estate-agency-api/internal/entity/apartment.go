@@ -1,14 +1,189 @@
 package entity
 
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrVersionConflict = errors.New("version conflict")
+	ErrValidation      = errors.New("validation failed")
+)
+
+// AnyVersion matches whatever version an entity has, as If-Match: * does.
+const AnyVersion = -1
+
+const (
+	ApartmentStatusDraft     = "draft"
+	ApartmentStatusPublished = "published"
+	ApartmentStatusArchived  = "archived"
+)
+
+const (
+	ListingTypeSale = "sale"
+	ListingTypeRent = "rent"
+)
+
 type Apartment struct {
-	ID         int    `form:"id" json:"id"`
-	Title      string `form:"title" json:"title" binding:"max=100"`
-	Price      int    `form:"price" json:"price"`
-	City       string `form:"city" json:"city" binding:"max=20"`
-	Rooms      int    `form:"rooms" json:"rooms"`
-	Address    string `form:"address" json:"address" binding:"max=100"`
-	Square     int    `form:"square" json:"square"`
-	IDRealtor  int    `form:"id_realtor" json:"id_realtor"`
-	UpdateTime string `json:"update_time"`
-	CreateTime string `json:"create_time"`
+	ID         int        `form:"id" json:"id"`
+	Title      string     `form:"title" json:"title" binding:"max=100"`
+	Price      int        `form:"price" json:"price" binding:"gte=0"`
+	City       string     `form:"city" json:"city" binding:"max=20"`
+	Rooms      int        `form:"rooms" json:"rooms" binding:"gte=0"`
+	Address    string     `form:"address" json:"address" binding:"max=100"`
+	Square     int        `form:"square" json:"square" binding:"gte=0"`
+	IDRealtor  int        `form:"id_realtor" json:"id_realtor"`
+	Status     string     `form:"status" json:"status" binding:"omitempty,oneof=draft published archived"`
+	Version    int        `form:"-" json:"version"`
+	UpdateTime string     `json:"update_time"`
+	CreateTime string     `json:"create_time"`
+	DeletedAt  *time.Time `form:"-" json:"deleted_at,omitempty"`
+
+	Lat *float64 `form:"lat" json:"lat,omitempty" binding:"omitempty,gte=-90,lte=90"`
+	Lng *float64 `form:"lng" json:"lng,omitempty" binding:"omitempty,gte=-180,lte=180"`
+	// NormalizedAddress is derived from Address and City whenever they are stored.
+	NormalizedAddress Address `form:"-" json:"normalized_address"`
+	// DistanceKM is only set on apartments listed near a point, see ApartmentFilter.Near.
+	DistanceKM *float64 `form:"-" json:"distance_km,omitempty"`
+
+	ListingType       string `form:"listing_type" json:"listing_type" binding:"omitempty,oneof=sale rent"`
+	MonthlyRent       int    `form:"monthly_rent" json:"monthly_rent" binding:"gte=0"`
+	Deposit           int    `form:"deposit" json:"deposit" binding:"gte=0"`
+	MinTermMonths     int    `form:"min_term_months" json:"min_term_months" binding:"gte=0"`
+	UtilitiesIncluded bool   `form:"utilities_included" json:"utilities_included"`
+	PetsAllowed       bool   `form:"pets_allowed" json:"pets_allowed"`
+}
+
+// ValidateListing checks that the rental fields agree with the listing type.
+func (a *Apartment) ValidateListing() error {
+	if a.ListingType == ListingTypeRent {
+		if a.MonthlyRent == 0 {
+			return fmt.Errorf("%w: monthly_rent is required for rent listings", ErrValidation)
+		}
+		return nil
+	}
+	if a.MonthlyRent != 0 || a.Deposit != 0 || a.MinTermMonths != 0 || a.UtilitiesIncluded || a.PetsAllowed {
+		return fmt.Errorf("%w: rental fields are only allowed for rent listings", ErrValidation)
+	}
+	return nil
+}
+
+// ValidateLocation checks that the coordinates are either both set or both missing.
+func (a *Apartment) ValidateLocation() error {
+	if (a.Lat == nil) != (a.Lng == nil) {
+		return fmt.Errorf("%w: lat and lng must be set together", ErrValidation)
+	}
+	return nil
+}
+
+// Location returns the coordinates of the apartment, if it has them.
+func (a *Apartment) Location() (GeoPoint, bool) {
+	if a.Lat == nil || a.Lng == nil {
+		return GeoPoint{}, false
+	}
+	return GeoPoint{Lat: *a.Lat, Lng: *a.Lng}, true
+}
+
+type ApartmentFilter struct {
+	City      string `form:"city" binding:"max=20"`
+	MinPrice  int    `form:"min_price" binding:"gte=0"`
+	MaxPrice  int    `form:"max_price" binding:"gte=0"`
+	Rooms     int    `form:"rooms" binding:"gte=0"`
+	MinSquare int    `form:"min_square" binding:"gte=0"`
+	MaxSquare int    `form:"max_square" binding:"gte=0"`
+	IDRealtor int    `form:"id_realtor" binding:"gte=0"`
+	Status    string `form:"status" binding:"omitempty,oneof=draft published archived"`
+
+	ListingType string `form:"listing_type" binding:"omitempty,oneof=sale rent"`
+
+	// IDTeam limits the apartments to those of the team's members. It is set by the team listings only,
+	// since Matches cannot check it.
+	IDTeam int `form:"-"`
+
+	// Near ("lat,lng") with RadiusKM and BBox ("min_lat,min_lng,max_lat,max_lng") are the geo conditions,
+	// parsed into Center and Box by ParseGeo. Apartments without coordinates never match them.
+	Near     string    `form:"near"`
+	RadiusKM float64   `form:"radius_km" binding:"gte=0"`
+	BBox     string    `form:"bbox"`
+	Sort     string    `form:"sort" binding:"omitempty,oneof=distance"`
+	Center   *GeoPoint `form:"-"`
+	Box      *GeoBox   `form:"-"`
+}
+
+// ParseGeo parses Near and BBox of a bound filter. RadiusKM and sorting by distance need Near.
+func (f *ApartmentFilter) ParseGeo() error {
+	f.Center, f.Box = nil, nil
+
+	if len(f.Near) != 0 {
+		center, err := ParseGeoPoint(f.Near)
+		if err != nil {
+			return err
+		}
+		f.Center = &center
+	}
+	if len(f.BBox) != 0 {
+		box, err := ParseGeoBox(f.BBox)
+		if err != nil {
+			return err
+		}
+		f.Box = &box
+	}
+
+	if f.Center == nil && f.RadiusKM != 0 {
+		return fmt.Errorf("%w: radius_km requires near", ErrValidation)
+	}
+	if f.Center == nil && f.Sort == SortDistance {
+		return fmt.Errorf("%w: sorting by distance requires near", ErrValidation)
+	}
+	return nil
+}
+
+// HasGeo reports whether the filter has geo conditions.
+func (f ApartmentFilter) HasGeo() bool {
+	return f.Center != nil || f.Box != nil
+}
+
+// Matches reports whether the apartment passes the filter, mirroring the conditions of the list query.
+func (f ApartmentFilter) Matches(apartment *Apartment) bool {
+	switch {
+	case len(f.City) != 0 && apartment.City != f.City:
+		return false
+	case f.MinPrice != 0 && apartment.Price < f.MinPrice:
+		return false
+	case f.MaxPrice != 0 && apartment.Price > f.MaxPrice:
+		return false
+	case f.Rooms != 0 && apartment.Rooms != f.Rooms:
+		return false
+	case f.MinSquare != 0 && apartment.Square < f.MinSquare:
+		return false
+	case f.MaxSquare != 0 && apartment.Square > f.MaxSquare:
+		return false
+	case f.IDRealtor != 0 && apartment.IDRealtor != f.IDRealtor:
+		return false
+	case len(f.Status) != 0 && apartment.Status != f.Status:
+		return false
+	case len(f.ListingType) != 0 && apartment.ListingType != f.ListingType:
+		return false
+	case f.HasGeo() && !f.matchesLocation(apartment):
+		return false
+	}
+	return true
+}
+
+func (f ApartmentFilter) matchesLocation(apartment *Apartment) bool {
+	location, ok := apartment.Location()
+	if !ok {
+		return false
+	}
+	if f.Box != nil && !f.Box.Contains(location) {
+		return false
+	}
+	return f.Center == nil || f.RadiusKM == 0 || DistanceKM(*f.Center, location) <= f.RadiusKM
+}
+
+type ListingCount struct {
+	City        string `json:"city"`
+	ListingType string `json:"listing_type"`
+	Count       int    `json:"count"`
 }
@@ -0,0 +1,64 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrAPIKeyInvalid = errors.New("api key is invalid, revoked or expired")
+
+const (
+	ScopeApartmentsRead  = "apartments:read"
+	ScopeApartmentsWrite = "apartments:write"
+	ScopeRealtorsRead    = "realtors:read"
+	ScopeRealtorsWrite   = "realtors:write"
+	ScopeFeedsRead       = "feeds:read"
+	ScopeTeamsRead       = "teams:read"
+	ScopeTeamsWrite      = "teams:write"
+)
+
+// APIKeyScopes lists every scope an API key can be granted.
+var APIKeyScopes = []string{ScopeApartmentsRead, ScopeApartmentsWrite, ScopeRealtorsRead, ScopeRealtorsWrite, ScopeFeedsRead, ScopeTeamsRead, ScopeTeamsWrite}
+
+// APIKey is a machine credential of an agency. Only the hash of the key is stored; Prefix is kept in clear so
+// admins can tell keys apart. Token is set only on the key returned by its creation.
+// A key issued to a realtor acts on their behalf and may only manage the listings the realtor may manage.
+type APIKey struct {
+	ID         int        `json:"id"`
+	AgencyID   int        `json:"agency_id"`
+	IDRealtor  *int       `json:"id_realtor,omitempty" binding:"omitempty,gt=0"`
+	Name       string     `json:"name" binding:"required,max=100"`
+	Prefix     string     `json:"prefix"`
+	Hash       string     `json:"-"`
+	Token      string     `json:"token,omitempty"`
+	Scopes     []string   `json:"scopes" binding:"required,min=1,dive,oneof=apartments:read apartments:write realtors:read realtors:write feeds:read teams:read teams:write"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedBy  string     `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// Active reports whether the key is neither revoked nor expired at the given time.
+func (k *APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// AgencyScope reports whether the scope manages the agency itself, its realtors and teams. Such scopes are not
+// granted to keys issued to a realtor.
+func AgencyScope(scope string) bool {
+	return scope == ScopeRealtorsWrite || scope == ScopeTeamsWrite
+}
+
+// HasScope reports whether the key is granted the scope; a key issued to a realtor never has an agency scope.
+func (k *APIKey) HasScope(scope string) bool {
+	if k.IDRealtor != nil && AgencyScope(scope) {
+		return false
+	}
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+package entity
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+)
+
+const (
+	AuditEntityApartment   = "apartment"
+	AuditEntityRealtor     = "realtor"
+	AuditEntityLease       = "lease"
+	AuditEntityRentPayment = "rent_payment"
+	AuditEntityAPIKey      = "api_key"
+	AuditEntityAgency      = "agency"
+	AuditEntityOffice      = "office"
+	AuditEntityTeam        = "team"
+	AuditEntityWebhook     = "webhook"
+	AuditEntityClient      = "client"
+	AuditEntitySavedSearch = "saved_search"
+	AuditEntityFavorite    = "favorite"
+)
+
+const (
+	AuditActionCreate    = "create"
+	AuditActionUpdate    = "update"
+	AuditActionDelete    = "delete"
+	AuditActionRestore   = "restore"
+	AuditActionRenew     = "renew"
+	AuditActionTerminate = "terminate"
+	AuditActionRevoke    = "revoke"
+)
+
+// AuditEntry records a single mutation. Diff maps every changed field to its before and after values.
+type AuditEntry struct {
+	ID         int64           `json:"id"`
+	Actor      string          `json:"actor"`
+	EntityType string          `json:"entity"`
+	EntityID   int             `json:"entity_id"`
+	Action     string          `json:"action"`
+	Diff       json.RawMessage `json:"diff"`
+	RequestID  string          `json:"request_id"`
+	CreatedAt  time.Time       `json:"created_at"`
+
+	// Before and After are the states of the entity the diff is taken from; either is nil for creations and
+	// deletions. The storage takes the diff when it writes the entry, once a created entity has its ID.
+	Before any `json:"-"`
+	After  any `json:"-"`
+}
+
+type auditChange struct {
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+}
+
+// TakeDiff compares the JSON representations of Before and After field by field and stores the changes in Diff.
+func (e *AuditEntry) TakeDiff() error {
+	beforeFields, err := auditFields(e.Before)
+	if err != nil {
+		return err
+	}
+	afterFields, err := auditFields(e.After)
+	if err != nil {
+		return err
+	}
+
+	changes := make(map[string]auditChange)
+	for key, b := range beforeFields {
+		if a, ok := afterFields[key]; !ok || !bytes.Equal(b, a) {
+			changes[key] = auditChange{Before: b, After: nullable(afterFields[key])}
+		}
+	}
+	for key, a := range afterFields {
+		if _, ok := beforeFields[key]; !ok {
+			changes[key] = auditChange{Before: nullable(nil), After: a}
+		}
+	}
+
+	e.Diff, err = json.Marshal(changes)
+	return err
+}
+
+func nullable(v json.RawMessage) json.RawMessage {
+	if v == nil {
+		return json.RawMessage("null")
+	}
+	return v
+}
+
+func auditFields(v any) (map[string]json.RawMessage, error) {
+	fields := make(map[string]json.RawMessage)
+	if v == nil {
+		return fields, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
+
+type AuditFilter struct {
+	EntityType string    `form:"entity" binding:"omitempty,oneof=apartment realtor lease rent_payment api_key agency office team webhook client saved_search favorite"`
+	EntityID   int       `form:"id" binding:"gte=0"`
+	Actor      string    `form:"actor"`
+	Action     string    `form:"action" binding:"omitempty,oneof=create update delete restore renew terminate revoke"`
+	From       time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To         time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+}
@@ -0,0 +1,17 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrClientEmailExists = errors.New("client with this email already exists")
+
+type Client struct {
+	ID        int       `form:"id" json:"id"`
+	FirstName string    `form:"first_name" json:"first_name" binding:"required,min=2,max=20"`
+	LastName  string    `form:"last_name" json:"last_name" binding:"max=20"`
+	Phone     string    `form:"phone" json:"phone" binding:"omitempty,e164"`
+	Email     string    `form:"email" json:"email" binding:"required,email,max=255"`
+	CreatedAt time.Time `form:"-" json:"created_at"`
+}
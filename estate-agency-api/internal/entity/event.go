@@ -0,0 +1,109 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	EventApartmentCreated       = "ApartmentCreated"
+	EventApartmentUpdated       = "ApartmentUpdated"
+	EventApartmentPriceChanged  = "PriceChanged"
+	EventApartmentStatusChanged = "ApartmentStatusChanged"
+	EventApartmentDeleted       = "ApartmentDeleted"
+	EventApartmentRestored      = "ApartmentRestored"
+
+	EventRealtorCreated  = "RealtorCreated"
+	EventRealtorUpdated  = "RealtorUpdated"
+	EventRealtorDeleted  = "RealtorDeleted"
+	EventRealtorRestored = "RealtorRestored"
+)
+
+const (
+	AggregateApartment = "apartment"
+	AggregateRealtor   = "realtor"
+)
+
+// Event is a domain event stored in the outbox. Data is encoded into Payload when the event is written,
+// so events about created aggregates carry the ID assigned by the storage.
+type Event struct {
+	ID            int64           `json:"id"`
+	Type          string          `json:"type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   int             `json:"aggregate_id"`
+	Payload       json.RawMessage `json:"payload"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+
+	Data any `json:"-"`
+	// Deliveries is the progress of the event through each subscriber that was tried, by subscriber name.
+	Deliveries map[string]*OutboxDelivery `json:"-"`
+}
+
+const (
+	OutboxDeliveryPending   = "pending"
+	OutboxDeliveryDelivered = "delivered"
+	OutboxDeliveryDead      = "dead"
+)
+
+// OutboxDelivery tracks the attempts to deliver an outbox event to one subscriber. A delivery that keeps failing
+// is marked dead, so that the subscriber moves on to the next events.
+type OutboxDelivery struct {
+	EventID    int64
+	Subscriber string
+	Status     string
+	Attempts   int
+	LastError  string
+}
+
+// Delivery returns the delivery of the event to the subscriber, a pending one if it was never tried.
+func (e *Event) Delivery(subscriber string) *OutboxDelivery {
+	if delivery, ok := e.Deliveries[subscriber]; ok {
+		return delivery
+	}
+	return &OutboxDelivery{EventID: e.ID, Subscriber: subscriber, Status: OutboxDeliveryPending}
+}
+
+func NewEvent(eventType string, aggregateType string, aggregateID int, data any) *Event {
+	return &Event{Type: eventType, AggregateType: aggregateType, AggregateID: aggregateID, OccurredAt: time.Now().UTC(), Data: data}
+}
+
+type PriceChange struct {
+	ID       int `json:"id"`
+	OldPrice int `json:"old_price"`
+	NewPrice int `json:"new_price"`
+}
+
+type StatusChange struct {
+	ID        int        `json:"id"`
+	OldStatus string     `json:"old_status"`
+	NewStatus string     `json:"new_status"`
+	Apartment *Apartment `json:"apartment"`
+}
+
+// ApartmentChange is the payload of apartment updates and deletions. Apartment is the state
+// after an update or right before a deletion, so consumers can filter events without a lookup.
+type ApartmentChange struct {
+	ID        int            `json:"id"`
+	Version   int            `json:"version"`
+	Changes   map[string]any `json:"changes,omitempty"`
+	Apartment *Apartment     `json:"apartment"`
+}
+
+type FieldsChange struct {
+	ID      int            `json:"id"`
+	Changes map[string]any `json:"changes"`
+}
+
+type AggregateRef struct {
+	ID int `json:"id"`
+}
+
+// Subscription is a live feed of events. Replay holds buffered events missed since the requested
+// event, and Resync is set when those events are no longer buffered and the client must reload its state.
+// Events is closed when the subscriber falls too far behind.
+type Subscription struct {
+	Replay []*Event
+	Events <-chan *Event
+	Resync bool
+	Cancel func()
+}
@@ -0,0 +1,28 @@
+package entity
+
+import "time"
+
+type Favorite struct {
+	IDClient        int       `json:"id_client"`
+	IDApartment     int       `json:"id_apartment" binding:"required,gt=0"`
+	Note            string    `json:"note" binding:"max=500"`
+	PriceAtFavorite int       `json:"price_at_favorite"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// FavoriteView is a favorite with the current state of the apartment.
+type FavoriteView struct {
+	Favorite
+	Title        string `json:"title"`
+	City         string `json:"city"`
+	Price        int    `json:"price"`
+	Status       string `json:"status"`
+	Deleted      bool   `json:"deleted"`
+	PriceDropped bool   `json:"price_dropped"`
+}
+
+type FavoriteCount struct {
+	IDApartment int    `json:"id_apartment"`
+	Title       string `json:"title"`
+	Favorites   int    `json:"favorites"`
+}
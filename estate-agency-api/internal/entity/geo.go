@@ -0,0 +1,125 @@
+package entity
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// earthRadiusKM is the radius ST_Distance_Sphere of MySQL uses, so that distances computed in memory
+// agree with those of the database.
+const earthRadiusKM = 6370.986
+
+// SortDistance orders apartments by their distance from the point of ApartmentFilter.Near.
+const SortDistance = "distance"
+
+// GeoPoint is a WGS 84 coordinate in degrees.
+type GeoPoint struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+}
+
+// Address is the free-text address of an apartment broken into its components, as normalized by the geocoder.
+type Address struct {
+	Street   string `json:"street"`
+	House    string `json:"house"`
+	Building string `json:"building"`
+	City     string `json:"city"`
+}
+
+// GeoBox is the area between two parallels and two meridians. Boxes crossing the antimeridian are not supported.
+type GeoBox struct {
+	Min GeoPoint `json:"min"`
+	Max GeoPoint `json:"max"`
+}
+
+func (b GeoBox) Contains(p GeoPoint) bool {
+	return p.Lat >= b.Min.Lat && p.Lat <= b.Max.Lat && p.Lng >= b.Min.Lng && p.Lng <= b.Max.Lng
+}
+
+// BoxAround returns the box enclosing the circle of radiusKM around the point. It reports false when the circle
+// reaches over a pole or the antimeridian, since no GeoBox encloses it then.
+func BoxAround(p GeoPoint, radiusKM float64) (GeoBox, bool) {
+	r := radiusKM / earthRadiusKM
+	dLat := r * 180 / math.Pi
+	if p.Lat-dLat < -90 || p.Lat+dLat > 90 {
+		return GeoBox{}, false
+	}
+
+	// The circle is widest poleward of its center, where it touches the meridians at asin(sin r / cos lat).
+	dLng := math.Asin(math.Sin(r)/math.Cos(p.Lat*math.Pi/180)) * 180 / math.Pi
+	if p.Lng-dLng < -180 || p.Lng+dLng > 180 {
+		return GeoBox{}, false
+	}
+
+	return GeoBox{Min: GeoPoint{Lat: p.Lat - dLat, Lng: p.Lng - dLng}, Max: GeoPoint{Lat: p.Lat + dLat, Lng: p.Lng + dLng}}, true
+}
+
+// DistanceKM is the great-circle distance between two points.
+func DistanceKM(a, b GeoPoint) float64 {
+	lat1, lat2 := a.Lat*math.Pi/180, b.Lat*math.Pi/180
+	dLat := lat2 - lat1
+	dLng := (b.Lng - a.Lng) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKM * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// ParseGeoPoint parses "lat,lng".
+func ParseGeoPoint(s string) (GeoPoint, error) {
+	v, err := parseCoordinates(s, 2)
+	if err != nil {
+		return GeoPoint{}, err
+	}
+
+	p := GeoPoint{Lat: v[0], Lng: v[1]}
+	if err = p.validate(); err != nil {
+		return GeoPoint{}, err
+	}
+	return p, nil
+}
+
+// ParseGeoBox parses "min_lat,min_lng,max_lat,max_lng", the south-west corner followed by the north-east one.
+func ParseGeoBox(s string) (GeoBox, error) {
+	v, err := parseCoordinates(s, 4)
+	if err != nil {
+		return GeoBox{}, err
+	}
+
+	b := GeoBox{Min: GeoPoint{Lat: v[0], Lng: v[1]}, Max: GeoPoint{Lat: v[2], Lng: v[3]}}
+	if err = b.Min.validate(); err != nil {
+		return GeoBox{}, err
+	}
+	if err = b.Max.validate(); err != nil {
+		return GeoBox{}, err
+	}
+	if b.Min.Lat > b.Max.Lat || b.Min.Lng > b.Max.Lng {
+		return GeoBox{}, fmt.Errorf("%w: the first corner of a box must be south-west of the second", ErrValidation)
+	}
+	return b, nil
+}
+
+func parseCoordinates(s string, n int) ([]float64, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != n {
+		return nil, fmt.Errorf("%w: %q must be %d comma separated coordinates", ErrValidation, s, n)
+	}
+
+	v := make([]float64, n)
+	for i, part := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, fmt.Errorf("%w: %q is not a coordinate", ErrValidation, part)
+		}
+		v[i] = f
+	}
+	return v, nil
+}
+
+func (p GeoPoint) validate() error {
+	if p.Lat < -90 || p.Lat > 90 || p.Lng < -180 || p.Lng > 180 {
+		return fmt.Errorf("%w: latitude must be within ±90 and longitude within ±180", ErrValidation)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package entity
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDistanceKM(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b GeoPoint
+		want float64
+	}{
+		{name: "same point", a: GeoPoint{Lat: 55.7558, Lng: 37.6173}, b: GeoPoint{Lat: 55.7558, Lng: 37.6173}, want: 0},
+		{name: "degree of the equator", a: GeoPoint{Lat: 0, Lng: 0}, b: GeoPoint{Lat: 0, Lng: 1}, want: 111.195},
+		{name: "degree of a meridian", a: GeoPoint{Lat: 10, Lng: 20}, b: GeoPoint{Lat: 11, Lng: 20}, want: 111.195},
+		{name: "moscow to saint petersburg", a: GeoPoint{Lat: 55.7558, Lng: 37.6173}, b: GeoPoint{Lat: 59.9343, Lng: 30.3351}, want: 633.0},
+		{name: "across the antimeridian", a: GeoPoint{Lat: 0, Lng: 179.5}, b: GeoPoint{Lat: 0, Lng: -179.5}, want: 111.195},
+		{name: "antipodes", a: GeoPoint{Lat: 0, Lng: 0}, b: GeoPoint{Lat: 0, Lng: 180}, want: math.Pi * earthRadiusKM},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DistanceKM(tt.a, tt.b); math.Abs(got-tt.want) > 0.5 {
+				t.Errorf("DistanceKM() = %v, want %v", got, tt.want)
+			}
+			if got, back := DistanceKM(tt.a, tt.b), DistanceKM(tt.b, tt.a); math.Abs(got-back) > 1e-9 {
+				t.Errorf("DistanceKM() is not symmetric: %v and %v", got, back)
+			}
+		})
+	}
+}
+
+func TestBoxAround(t *testing.T) {
+	tests := []struct {
+		name     string
+		center   GeoPoint
+		radiusKM float64
+		wantOK   bool
+	}{
+		{name: "equator", center: GeoPoint{Lat: 0, Lng: 0}, radiusKM: 10, wantOK: true},
+		{name: "moscow", center: GeoPoint{Lat: 55.7558, Lng: 37.6173}, radiusKM: 25, wantOK: true},
+		{name: "southern hemisphere", center: GeoPoint{Lat: -33.8688, Lng: 151.2093}, radiusKM: 100, wantOK: true},
+		{name: "over the north pole", center: GeoPoint{Lat: 89.95, Lng: 0}, radiusKM: 10, wantOK: false},
+		{name: "over the south pole", center: GeoPoint{Lat: -89.95, Lng: 0}, radiusKM: 10, wantOK: false},
+		{name: "over the antimeridian", center: GeoPoint{Lat: 0, Lng: 179.95}, radiusKM: 10, wantOK: false},
+		{name: "over the antimeridian westwards", center: GeoPoint{Lat: 0, Lng: -179.95}, radiusKM: 10, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			box, ok := BoxAround(tt.center, tt.radiusKM)
+			if ok != tt.wantOK {
+				t.Fatalf("BoxAround() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+
+			if !box.Contains(tt.center) {
+				t.Errorf("box %+v does not contain its center", box)
+			}
+			// Every point of the circle lies in the box, and the northernmost and southernmost ones on its edges.
+			// The bearings are close enough together to reach the points touching the meridians of the box.
+			for i := 0; i < 36000; i++ {
+				bearing := float64(i) / 100
+				p := destination(tt.center, bearing, tt.radiusKM*(1-1e-9))
+				if !box.Contains(p) {
+					t.Fatalf("box %+v does not contain %+v at bearing %v", box, p, bearing)
+				}
+			}
+			if d := DistanceKM(tt.center, GeoPoint{Lat: box.Max.Lat, Lng: tt.center.Lng}); math.Abs(d-tt.radiusKM) > 1e-6 {
+				t.Errorf("northern edge is %v km away, want %v", d, tt.radiusKM)
+			}
+			if d := DistanceKM(tt.center, GeoPoint{Lat: box.Min.Lat, Lng: tt.center.Lng}); math.Abs(d-tt.radiusKM) > 1e-6 {
+				t.Errorf("southern edge is %v km away, want %v", d, tt.radiusKM)
+			}
+		})
+	}
+}
+
+// destination is the point distanceKM away from p in the direction of bearing, in degrees clockwise from north.
+func destination(p GeoPoint, bearing float64, distanceKM float64) GeoPoint {
+	lat, lng := p.Lat*math.Pi/180, p.Lng*math.Pi/180
+	theta, r := bearing*math.Pi/180, distanceKM/earthRadiusKM
+
+	lat2 := math.Asin(math.Sin(lat)*math.Cos(r) + math.Cos(lat)*math.Sin(r)*math.Cos(theta))
+	lng2 := lng + math.Atan2(math.Sin(theta)*math.Sin(r)*math.Cos(lat), math.Cos(r)-math.Sin(lat)*math.Sin(lat2))
+	return GeoPoint{Lat: lat2 * 180 / math.Pi, Lng: lng2 * 180 / math.Pi}
+}
@@ -0,0 +1,23 @@
+package entity
+
+import "time"
+
+const (
+	HealthUp      = "up"
+	HealthDown    = "down"
+	HealthUnknown = "unknown"
+)
+
+// DependencyHealth is the result of the last check of one dependency.
+type DependencyHealth struct {
+	Status    string    `json:"status"`
+	LatencyMs float64   `json:"latency_ms"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// HealthReport is ready only when every dependency was up on its last check.
+type HealthReport struct {
+	Ready        bool                         `json:"ready"`
+	Dependencies map[string]*DependencyHealth `json:"dependencies"`
+}
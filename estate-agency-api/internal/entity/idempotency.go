@@ -0,0 +1,22 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrIdempotencyKeyExists   = errors.New("idempotency key already exists")
+	ErrIdempotencyKeyMismatch = errors.New("idempotency key reused with a different request")
+	ErrIdempotencyInProgress  = errors.New("request with this idempotency key is in progress")
+)
+
+type IdempotencyRecord struct {
+	Key         string
+	RequestHash string
+	StatusCode  int
+	ContentType string
+	Response    []byte
+	Completed   bool
+	ExpiresAt   time.Time
+}
@@ -0,0 +1,78 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrLeaseOverlap   = errors.New("apartment is already leased for these dates")
+	ErrLeaseNotActive = errors.New("lease is not active")
+)
+
+const (
+	LeaseStatusActive     = "active"
+	LeaseStatusTerminated = "terminated"
+)
+
+// Lease is a rental agreement between the agency and a tenant, who is a client.
+type Lease struct {
+	ID                int        `json:"id"`
+	IDApartment       int        `json:"id_apartment" binding:"required,gt=0"`
+	IDClient          int        `json:"id_client" binding:"required,gt=0"`
+	StartDate         Date       `json:"start_date"`
+	EndDate           Date       `json:"end_date"`
+	MonthlyRent       int        `json:"monthly_rent" binding:"gte=0"`
+	Deposit           int        `json:"deposit" binding:"gte=0"`
+	PaymentDay        int        `json:"payment_day" binding:"required,min=1,max=28"`
+	Status            string     `json:"status"`
+	TerminatedAt      *time.Time `json:"terminated_at,omitempty"`
+	TerminationReason string     `json:"termination_reason,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// Charges returns the monthly payment schedule of the lease. Each period is charged the full monthly rent,
+// due on the first payment day on or after the start of the period.
+func (l *Lease) Charges() []*RentCharge {
+	var charges []*RentCharge
+	for i := 0; ; i++ {
+		start := l.StartDate.AddMonths(i)
+		if !start.Before(l.EndDate.Time) {
+			break
+		}
+
+		due := NewDate(time.Date(start.Year(), start.Month(), l.PaymentDay, 0, 0, 0, 0, time.UTC))
+		if due.Before(start.Time) {
+			due = due.AddMonths(1)
+		}
+
+		charges = append(charges, &RentCharge{
+			IDLease:     l.ID,
+			PeriodStart: start,
+			DueDate:     due,
+			Amount:      l.MonthlyRent,
+			Status:      RentChargePending,
+		})
+	}
+
+	return charges
+}
+
+// LeaseRenewal extends an active lease. A zero rent keeps the current one.
+type LeaseRenewal struct {
+	EndDate     Date `json:"end_date"`
+	MonthlyRent int  `json:"monthly_rent" binding:"gte=0"`
+}
+
+// LeaseTermination ends an active lease early. A zero date means today.
+type LeaseTermination struct {
+	Date   Date   `json:"date"`
+	Reason string `json:"reason" binding:"max=255"`
+}
+
+type LeaseFilter struct {
+	IDApartment int    `form:"id_apartment" binding:"gte=0"`
+	IDClient    int    `form:"id_client" binding:"gte=0"`
+	Status      string `form:"status" binding:"omitempty,oneof=active terminated"`
+}
@@ -0,0 +1,32 @@
+package entity
+
+import "time"
+
+const (
+	NotificationPending = "pending"
+	NotificationSent    = "sent"
+	NotificationFailed  = "failed"
+)
+
+const (
+	NotificationSearchMatch  = "search_match"
+	NotificationRentReminder = "rent_reminder"
+)
+
+// Notification is a message queued for a client. DedupKey makes queueing idempotent
+// when the triggering event is processed more than once.
+type Notification struct {
+	ID            int64      `json:"id"`
+	IDClient      int        `json:"id_client"`
+	Kind          string     `json:"kind"`
+	Recipient     string     `json:"recipient"`
+	Subject       string     `json:"subject"`
+	Body          string     `json:"body"`
+	DedupKey      string     `json:"-"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	LastError     string     `json:"last_error"`
+	CreatedAt     time.Time  `json:"created_at"`
+	SentAt        *time.Time `json:"sent_at,omitempty"`
+}
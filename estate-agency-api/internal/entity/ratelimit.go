@@ -0,0 +1,40 @@
+package entity
+
+import (
+	"math"
+	"time"
+)
+
+// RateLimit is a token bucket holding up to Burst requests and refilled at Rate requests per second.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+type RateLimitResult struct {
+	Allowed    bool
+	Remaining  int
+	RetryAfter time.Duration // until the next request is allowed, zero when Allowed
+	ResetAfter time.Duration // until the bucket is full again
+}
+
+// Result describes the bucket left with the given number of tokens after a request was taken or refused.
+func (l RateLimit) Result(tokens float64, allowed bool) RateLimitResult {
+	result := RateLimitResult{
+		Allowed:    allowed,
+		Remaining:  int(math.Floor(tokens)),
+		ResetAfter: l.refill(float64(l.Burst) - tokens),
+	}
+	if !allowed {
+		result.RetryAfter = l.refill(1 - tokens)
+	}
+	return result
+}
+
+// refill returns the time it takes to add the given number of tokens.
+func (l RateLimit) refill(tokens float64) time.Duration {
+	if tokens <= 0 {
+		return 0
+	}
+	return time.Duration(tokens / l.Rate * float64(time.Second))
+}
@@ -0,0 +1,45 @@
+package entity
+
+import "time"
+
+const (
+	RentChargePending = "pending"
+	RentChargePaid    = "paid"
+	RentChargeOverdue = "overdue"
+)
+
+// RentCharge is one month of a lease's payment schedule. Paid grows as payments are allocated to it.
+type RentCharge struct {
+	ID          int        `json:"id"`
+	IDLease     int        `json:"id_lease"`
+	PeriodStart Date       `json:"period_start"`
+	DueDate     Date       `json:"due_date"`
+	Amount      int        `json:"amount"`
+	Paid        int        `json:"paid"`
+	Status      string     `json:"status"`
+	Reminders   int        `json:"reminders"`
+	RemindedAt  *time.Time `json:"reminded_at,omitempty"`
+}
+
+type RentPayment struct {
+	ID        int       `json:"id"`
+	IDLease   int       `json:"id_lease"`
+	Amount    int       `json:"amount" binding:"required,gt=0"`
+	PaidOn    Date      `json:"paid_on"`
+	Note      string    `json:"note" binding:"max=255"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// LeaseBalance sums up what a tenant owes. Charged counts charges due up to today, so a negative
+// Balance is a prepayment. OverdueDays counts from the oldest unpaid due date.
+type LeaseBalance struct {
+	IDLease       int   `json:"id_lease"`
+	IDClient      int   `json:"id_client"`
+	IDApartment   int   `json:"id_apartment"`
+	Charged       int   `json:"charged"`
+	Paid          int   `json:"paid"`
+	Balance       int   `json:"balance"`
+	OverdueAmount int   `json:"overdue_amount"`
+	OverdueDays   int   `json:"overdue_days"`
+	OldestDue     *Date `json:"oldest_due,omitempty"`
+}
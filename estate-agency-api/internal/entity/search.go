@@ -0,0 +1,30 @@
+package entity
+
+import "time"
+
+// SavedSearch is a filter set a client wants to be notified about. Zero bounds are not applied.
+type SavedSearch struct {
+	ID        int       `json:"id"`
+	IDClient  int       `json:"id_client"`
+	Name      string    `json:"name" binding:"max=100"`
+	City      string    `json:"city" binding:"max=20"`
+	MinPrice  int       `json:"min_price" binding:"gte=0"`
+	MaxPrice  int       `json:"max_price" binding:"gte=0"`
+	Rooms     int       `json:"rooms" binding:"gte=0"`
+	MinSquare int       `json:"min_square" binding:"gte=0"`
+	MaxSquare int       `json:"max_square" binding:"gte=0"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Filter returns the search as a listing filter limited to published apartments.
+func (s *SavedSearch) Filter() ApartmentFilter {
+	return ApartmentFilter{
+		City:      s.City,
+		MinPrice:  s.MinPrice,
+		MaxPrice:  s.MaxPrice,
+		Rooms:     s.Rooms,
+		MinSquare: s.MinSquare,
+		MaxSquare: s.MaxSquare,
+		Status:    ApartmentStatusPublished,
+	}
+}
@@ -0,0 +1,65 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrOfficeNotEmpty = errors.New("office still has teams")
+	ErrForbidden      = errors.New("not allowed to manage listings of this realtor")
+)
+
+// Office is a branch of an agency; its realtors work in teams.
+type Office struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name" binding:"required,max=100"`
+	Address   string    `json:"address" binding:"max=255"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Team groups realtors of an office. The lead manages the listings of the members.
+type Team struct {
+	ID        int       `json:"id"`
+	IDOffice  int       `json:"id_office" binding:"required,gt=0"`
+	Name      string    `json:"name" binding:"required,max=100"`
+	IDLead    *int      `json:"id_lead,omitempty" binding:"omitempty,gt=0"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type TeamFilter struct {
+	IDOffice int `form:"id_office" binding:"gte=0"`
+}
+
+// ListingStats counts listings by status and the deals closed on them, which are the leases signed
+// for the apartments. MonthlyRent is the sum of the rent of the active leases.
+type ListingStats struct {
+	Listings     int `json:"listings"`
+	Published    int `json:"published"`
+	Drafts       int `json:"drafts"`
+	Archived     int `json:"archived"`
+	Deals        int `json:"deals"`
+	ActiveLeases int `json:"active_leases"`
+	MonthlyRent  int `json:"monthly_rent"`
+}
+
+func (s *ListingStats) Add(other ListingStats) {
+	s.Listings += other.Listings
+	s.Published += other.Published
+	s.Drafts += other.Drafts
+	s.Archived += other.Archived
+	s.Deals += other.Deals
+	s.ActiveLeases += other.ActiveLeases
+	s.MonthlyRent += other.MonthlyRent
+}
+
+type MemberStats struct {
+	IDRealtor int `json:"id_realtor"`
+	ListingStats
+}
+
+type TeamStats struct {
+	IDTeam  int            `json:"id_team"`
+	Total   ListingStats   `json:"total"`
+	Members []*MemberStats `json:"members"`
+}
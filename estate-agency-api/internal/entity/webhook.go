@@ -0,0 +1,59 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryDead      = "dead"
+)
+
+// WebhookAllEvents subscribes a webhook to every event type.
+const WebhookAllEvents = "*"
+
+// EventTypes lists every domain event a webhook can subscribe to.
+var EventTypes = []string{
+	EventApartmentCreated, EventApartmentUpdated, EventApartmentPriceChanged, EventApartmentStatusChanged, EventApartmentDeleted, EventApartmentRestored,
+	EventRealtorCreated, EventRealtorUpdated, EventRealtorDeleted, EventRealtorRestored,
+}
+
+type Webhook struct {
+	ID         int       `json:"id"`
+	URL        string    `json:"url" binding:"required,url,max=2048"`
+	EventTypes []string  `json:"event_types" binding:"required,min=1"`
+	Secret     string    `json:"secret,omitempty" binding:"max=255"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Accepts reports whether the webhook is subscribed to the event type.
+func (w *Webhook) Accepts(eventType string) bool {
+	for _, t := range w.EventTypes {
+		if t == WebhookAllEvents || t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+type WebhookDelivery struct {
+	ID             int64           `json:"id"`
+	WebhookID      int             `json:"webhook_id"`
+	EventID        int64           `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	LastStatusCode int             `json:"last_status_code"`
+	LastError      string          `json:"last_error"`
+	CreatedAt      time.Time       `json:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+}
+
+type WebhookDeliveryFilter struct {
+	Status string `form:"status" binding:"omitempty,oneof=pending delivered dead"`
+}
@@ -0,0 +1,110 @@
+package health
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"gilab.com/estate-agency-api/internal/entity"
+)
+
+// Check probes one dependency and returns nil when it is usable.
+type Check func(ctx context.Context) error
+
+type dependency struct {
+	name  string
+	check Check
+}
+
+// Monitor probes the dependencies in the background and keeps the last result, so readiness
+// probes are answered from memory and queries no longer ping the database before running.
+type Monitor struct {
+	timeout      time.Duration
+	dependencies []dependency
+
+	mu     sync.RWMutex
+	report map[string]*entity.DependencyHealth
+
+	logger *slog.Logger
+}
+
+func New(timeout time.Duration, logger *slog.Logger) *Monitor {
+	return &Monitor{timeout: timeout, report: make(map[string]*entity.DependencyHealth), logger: logger.With(slog.String("component", "health"))}
+}
+
+// Add registers a dependency. It is reported as unknown, and the service as not ready, until it is checked.
+func (m *Monitor) Add(name string, check Check) {
+	m.dependencies = append(m.dependencies, dependency{name: name, check: check})
+
+	m.mu.Lock()
+	m.report[name] = &entity.DependencyHealth{Status: entity.HealthUnknown}
+	m.mu.Unlock()
+}
+
+// Run checks all dependencies concurrently and stores the results. It is meant to be run by a worker.
+func (m *Monitor) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for _, d := range m.dependencies {
+		wg.Add(1)
+		go func(d dependency) {
+			defer wg.Done()
+			m.set(d.name, m.probe(ctx, d.check))
+		}(d)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+func (m *Monitor) probe(ctx context.Context, check Check) *entity.DependencyHealth {
+	ctx, cancel := context.WithTimeout(ctx, m.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check(ctx)
+	result := &entity.DependencyHealth{
+		Status:    entity.HealthUp,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+		CheckedAt: start,
+	}
+	if err != nil {
+		result.Status = entity.HealthDown
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+func (m *Monitor) set(name string, result *entity.DependencyHealth) {
+	m.mu.Lock()
+	previous := m.report[name]
+	m.report[name] = result
+	m.mu.Unlock()
+
+	if previous.Status == result.Status {
+		return
+	}
+	if result.Status == entity.HealthDown {
+		m.logger.Error("dependency is down", slog.String("dependency", name), "err", result.Error)
+	} else {
+		m.logger.Info("dependency is up", slog.String("dependency", name))
+	}
+}
+
+// Report returns a copy of the last results.
+func (m *Monitor) Report() entity.HealthReport {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	report := entity.HealthReport{Ready: true, Dependencies: make(map[string]*entity.DependencyHealth, len(m.report))}
+	for name, result := range m.report {
+		r := *result
+		report.Dependencies[name] = &r
+		if r.Status != entity.HealthUp {
+			report.Ready = false
+		}
+	}
+
+	return report
+}
@@ -7,16 +7,24 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
-func New() *cache.Cache {
-	ring := redis.NewRing(&redis.RingOptions{
-		Addrs: map[string]string{
-			"server1": "3306",
-			"server2": "3306",
-		},
+// CacheConfig describes the Redis server. Redis is optional: an empty address disables it.
+type CacheConfig struct {
+	Addr     string `yaml:"address" env:"REDIS_ADDRESS"`
+	Password string `yaml:"password" env:"REDIS_PASSWORD"`
+	DB       int    `yaml:"db" env:"REDIS_DB" env-default:"0"`
+}
+
+func NewClient(cacheCfg *CacheConfig) *redis.Client {
+	return redis.NewClient(&redis.Options{
+		Addr:     cacheCfg.Addr,
+		Password: cacheCfg.Password,
+		DB:       cacheCfg.DB,
 	})
+}
 
+func New(client redis.UniversalClient) *cache.Cache {
 	cacheClient := cache.New(&cache.Options{
-		Redis:      ring,
+		Redis:      client,
 		LocalCache: cache.NewTinyLFU(1000, time.Minute),
 	})
 
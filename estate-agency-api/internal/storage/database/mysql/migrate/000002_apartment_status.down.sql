@@ -0,0 +1 @@
+ALTER TABLE `agency`.`Apartments` DROP COLUMN `status`;
@@ -0,0 +1,2 @@
+ALTER TABLE `agency`.`Apartments`
+  ADD COLUMN `status` VARCHAR(20) NOT NULL DEFAULT 'published' AFTER `id_realtor`;
@@ -0,0 +1 @@
+DROP TABLE `agency`.`idempotency_keys`;
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS `agency`.`idempotency_keys` (
+  `idem_key` VARCHAR(255) NOT NULL,
+  `request_hash` CHAR(64) NOT NULL,
+  `status_code` INT NOT NULL DEFAULT 0,
+  `content_type` VARCHAR(100) NOT NULL DEFAULT '',
+  `response` MEDIUMBLOB NULL,
+  `completed` TINYINT(1) NOT NULL DEFAULT 0,
+  `expires_at` DATETIME NOT NULL,
+  PRIMARY KEY (`idem_key`),
+  INDEX `expires_at_idx` (`expires_at` ASC) VISIBLE)
+ENGINE = InnoDB;
@@ -0,0 +1,2 @@
+ALTER TABLE `agency`.`Apartments`
+  ADD COLUMN `version` INT NOT NULL DEFAULT 1 AFTER `status`;
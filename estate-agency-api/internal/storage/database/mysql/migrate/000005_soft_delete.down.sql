@@ -0,0 +1,2 @@
+ALTER TABLE `agency`.`Apartments` DROP INDEX `deleted_at_idx`, DROP COLUMN `deleted_at`;
+ALTER TABLE `agency`.`Realtors` DROP INDEX `deleted_at_idx`, DROP COLUMN `deleted_at`;
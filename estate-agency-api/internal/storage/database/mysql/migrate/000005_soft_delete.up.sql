@@ -0,0 +1,7 @@
+ALTER TABLE `agency`.`Apartments`
+  ADD COLUMN `deleted_at` DATETIME NULL DEFAULT NULL,
+  ADD INDEX `deleted_at_idx` (`deleted_at` ASC) VISIBLE;
+
+ALTER TABLE `agency`.`Realtors`
+  ADD COLUMN `deleted_at` DATETIME NULL DEFAULT NULL,
+  ADD INDEX `deleted_at_idx` (`deleted_at` ASC) VISIBLE;
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS `agency`.`audit_log_no_delete`;
+DROP TRIGGER IF EXISTS `agency`.`audit_log_no_update`;
+DROP TABLE IF EXISTS `agency`.`audit_log`;
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS `agency`.`audit_log` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT,
+  `actor` VARCHAR(255) NOT NULL,
+  `entity_type` VARCHAR(32) NOT NULL,
+  `entity_id` INT NOT NULL,
+  `action` VARCHAR(16) NOT NULL,
+  `diff` JSON NOT NULL,
+  `request_id` VARCHAR(64) NOT NULL DEFAULT '',
+  `created_at` DATETIME(6) NOT NULL,
+  PRIMARY KEY (`id`),
+  INDEX `entity_idx` (`entity_type` ASC, `entity_id` ASC) VISIBLE,
+  INDEX `created_at_idx` (`created_at` ASC) VISIBLE)
+ENGINE = InnoDB;
+
+CREATE TRIGGER `agency`.`audit_log_no_update` BEFORE UPDATE ON `agency`.`audit_log`
+  FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit_log is append-only';
+
+CREATE TRIGGER `agency`.`audit_log_no_delete` BEFORE DELETE ON `agency`.`audit_log`
+  FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit_log is append-only';
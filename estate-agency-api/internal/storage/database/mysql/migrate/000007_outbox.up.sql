@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS `agency`.`outbox_events` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT,
+  `event_type` VARCHAR(64) NOT NULL,
+  `aggregate_type` VARCHAR(32) NOT NULL,
+  `aggregate_id` INT NOT NULL,
+  `payload` JSON NOT NULL,
+  `occurred_at` DATETIME(6) NOT NULL,
+  `published_at` DATETIME(6) NULL DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  INDEX `published_at_idx` (`published_at` ASC, `id` ASC) VISIBLE)
+ENGINE = InnoDB;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS `agency`.`webhook_deliveries`;
+DROP TABLE IF EXISTS `agency`.`webhooks`;
@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS `agency`.`webhooks` (
+  `id` INT NOT NULL AUTO_INCREMENT,
+  `url` VARCHAR(2048) NOT NULL,
+  `event_types` JSON NOT NULL,
+  `secret` VARCHAR(255) NOT NULL,
+  `active` TINYINT(1) NOT NULL DEFAULT 1,
+  `created_at` DATETIME NOT NULL,
+  PRIMARY KEY (`id`))
+ENGINE = InnoDB;
+
+CREATE TABLE IF NOT EXISTS `agency`.`webhook_deliveries` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT,
+  `webhook_id` INT NOT NULL,
+  `event_id` BIGINT NOT NULL,
+  `event_type` VARCHAR(64) NOT NULL,
+  `payload` JSON NOT NULL,
+  `status` VARCHAR(16) NOT NULL DEFAULT 'pending',
+  `attempts` INT NOT NULL DEFAULT 0,
+  `next_attempt_at` DATETIME(6) NOT NULL,
+  `last_status_code` INT NOT NULL DEFAULT 0,
+  `last_error` TEXT NOT NULL,
+  `created_at` DATETIME(6) NOT NULL,
+  `delivered_at` DATETIME(6) NULL DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `webhook_event_UNIQUE` (`webhook_id` ASC, `event_id` ASC) VISIBLE,
+  INDEX `due_idx` (`status` ASC, `next_attempt_at` ASC) VISIBLE,
+  CONSTRAINT `fk_delivery_webhook`
+    FOREIGN KEY (`webhook_id`)
+    REFERENCES `agency`.`webhooks` (`id`)
+    ON DELETE CASCADE
+    ON UPDATE NO ACTION)
+ENGINE = InnoDB;
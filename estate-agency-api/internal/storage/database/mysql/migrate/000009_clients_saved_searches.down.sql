@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS `agency`.`notifications`;
+DROP TABLE IF EXISTS `agency`.`saved_searches`;
+DROP TABLE IF EXISTS `agency`.`clients`;
@@ -0,0 +1,51 @@
+CREATE TABLE IF NOT EXISTS `agency`.`clients` (
+  `id` INT NOT NULL AUTO_INCREMENT,
+  `first_name` VARCHAR(20) NOT NULL,
+  `last_name` VARCHAR(20) NOT NULL,
+  `phone` VARCHAR(20) NOT NULL,
+  `email` VARCHAR(255) NOT NULL,
+  `created_at` DATETIME NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `email_UNIQUE` (`email` ASC) VISIBLE)
+ENGINE = InnoDB;
+
+CREATE TABLE IF NOT EXISTS `agency`.`saved_searches` (
+  `id` INT NOT NULL AUTO_INCREMENT,
+  `id_client` INT NOT NULL,
+  `name` VARCHAR(100) NOT NULL,
+  `city` VARCHAR(20) NOT NULL,
+  `min_price` INT NOT NULL DEFAULT 0,
+  `max_price` INT NOT NULL DEFAULT 0,
+  `rooms` INT NOT NULL DEFAULT 0,
+  `min_square` INT NOT NULL DEFAULT 0,
+  `max_square` INT NOT NULL DEFAULT 0,
+  `created_at` DATETIME NOT NULL,
+  PRIMARY KEY (`id`),
+  INDEX `id_client_idx` (`id_client` ASC) VISIBLE,
+  INDEX `city_idx` (`city` ASC) VISIBLE,
+  CONSTRAINT `fk_search_client`
+    FOREIGN KEY (`id_client`)
+    REFERENCES `agency`.`clients` (`id`)
+    ON DELETE CASCADE
+    ON UPDATE NO ACTION)
+ENGINE = InnoDB;
+
+CREATE TABLE IF NOT EXISTS `agency`.`notifications` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT,
+  `id_client` INT NOT NULL,
+  `kind` VARCHAR(32) NOT NULL,
+  `recipient` VARCHAR(255) NOT NULL,
+  `subject` VARCHAR(255) NOT NULL,
+  `body` TEXT NOT NULL,
+  `dedup_key` VARCHAR(255) NOT NULL,
+  `status` VARCHAR(16) NOT NULL DEFAULT 'pending',
+  `attempts` INT NOT NULL DEFAULT 0,
+  `next_attempt_at` DATETIME(6) NOT NULL,
+  `last_error` TEXT NOT NULL,
+  `created_at` DATETIME(6) NOT NULL,
+  `sent_at` DATETIME(6) NULL DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `dedup_key_UNIQUE` (`dedup_key` ASC) VISIBLE,
+  INDEX `due_idx` (`status` ASC, `next_attempt_at` ASC) VISIBLE,
+  INDEX `id_client_idx` (`id_client` ASC) VISIBLE)
+ENGINE = InnoDB;
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS `agency`.`favorites` (
+  `id_client` INT NOT NULL,
+  `id_apartment` INT NOT NULL,
+  `note` VARCHAR(500) NOT NULL DEFAULT '',
+  `price_at_favorite` INT NOT NULL,
+  `created_at` DATETIME NOT NULL,
+  PRIMARY KEY (`id_client`, `id_apartment`),
+  INDEX `id_apartment_idx` (`id_apartment` ASC) VISIBLE,
+  CONSTRAINT `fk_favorite_client`
+    FOREIGN KEY (`id_client`)
+    REFERENCES `agency`.`clients` (`id`)
+    ON DELETE CASCADE
+    ON UPDATE NO ACTION,
+  CONSTRAINT `fk_favorite_apartment`
+    FOREIGN KEY (`id_apartment`)
+    REFERENCES `agency`.`Apartments` (`id`)
+    ON DELETE CASCADE
+    ON UPDATE NO ACTION)
+ENGINE = InnoDB;
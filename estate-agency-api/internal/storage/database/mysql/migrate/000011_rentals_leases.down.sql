@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS `agency`.`leases`;
+ALTER TABLE `agency`.`Apartments`
+  DROP INDEX `listing_type_idx`,
+  DROP COLUMN `pets_allowed`,
+  DROP COLUMN `utilities_included`,
+  DROP COLUMN `min_term_months`,
+  DROP COLUMN `deposit`,
+  DROP COLUMN `monthly_rent`,
+  DROP COLUMN `listing_type`;
@@ -0,0 +1,38 @@
+ALTER TABLE `agency`.`Apartments`
+  ADD COLUMN `listing_type` VARCHAR(10) NOT NULL DEFAULT 'sale',
+  ADD COLUMN `monthly_rent` INT NOT NULL DEFAULT 0,
+  ADD COLUMN `deposit` INT NOT NULL DEFAULT 0,
+  ADD COLUMN `min_term_months` INT NOT NULL DEFAULT 0,
+  ADD COLUMN `utilities_included` TINYINT(1) NOT NULL DEFAULT 0,
+  ADD COLUMN `pets_allowed` TINYINT(1) NOT NULL DEFAULT 0,
+  ADD INDEX `listing_type_idx` (`listing_type` ASC) VISIBLE;
+
+CREATE TABLE IF NOT EXISTS `agency`.`leases` (
+  `id` INT NOT NULL AUTO_INCREMENT,
+  `id_apartment` INT NOT NULL,
+  `id_client` INT NOT NULL,
+  `start_date` DATE NOT NULL,
+  `end_date` DATE NOT NULL,
+  `monthly_rent` INT NOT NULL,
+  `deposit` INT NOT NULL DEFAULT 0,
+  `payment_day` TINYINT NOT NULL,
+  `status` VARCHAR(20) NOT NULL,
+  `terminated_at` DATETIME NULL DEFAULT NULL,
+  `termination_reason` VARCHAR(255) NOT NULL DEFAULT '',
+  `created_at` DATETIME NOT NULL,
+  `updated_at` DATETIME NOT NULL,
+  PRIMARY KEY (`id`),
+  INDEX `apartment_idx` (`id_apartment` ASC, `status` ASC) VISIBLE,
+  INDEX `client_idx` (`id_client` ASC) VISIBLE,
+  INDEX `end_date_idx` (`status` ASC, `end_date` ASC) VISIBLE,
+  CONSTRAINT `fk_lease_apartment`
+    FOREIGN KEY (`id_apartment`)
+    REFERENCES `agency`.`Apartments` (`id`)
+    ON DELETE RESTRICT
+    ON UPDATE NO ACTION,
+  CONSTRAINT `fk_lease_client`
+    FOREIGN KEY (`id_client`)
+    REFERENCES `agency`.`clients` (`id`)
+    ON DELETE RESTRICT
+    ON UPDATE NO ACTION)
+ENGINE = InnoDB;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS `agency`.`rent_payments`;
+DROP TABLE IF EXISTS `agency`.`rent_charges`;
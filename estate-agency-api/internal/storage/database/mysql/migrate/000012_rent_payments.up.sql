@@ -0,0 +1,35 @@
+CREATE TABLE IF NOT EXISTS `agency`.`rent_charges` (
+  `id` INT NOT NULL AUTO_INCREMENT,
+  `id_lease` INT NOT NULL,
+  `period_start` DATE NOT NULL,
+  `due_date` DATE NOT NULL,
+  `amount` INT NOT NULL,
+  `paid` INT NOT NULL DEFAULT 0,
+  `status` VARCHAR(20) NOT NULL,
+  `reminders` INT NOT NULL DEFAULT 0,
+  `reminded_at` DATETIME NULL DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `lease_period_idx` (`id_lease` ASC, `period_start` ASC) VISIBLE,
+  INDEX `status_due_idx` (`status` ASC, `due_date` ASC) VISIBLE,
+  CONSTRAINT `fk_charge_lease`
+    FOREIGN KEY (`id_lease`)
+    REFERENCES `agency`.`leases` (`id`)
+    ON DELETE CASCADE
+    ON UPDATE NO ACTION)
+ENGINE = InnoDB;
+
+CREATE TABLE IF NOT EXISTS `agency`.`rent_payments` (
+  `id` INT NOT NULL AUTO_INCREMENT,
+  `id_lease` INT NOT NULL,
+  `amount` INT NOT NULL,
+  `paid_on` DATE NOT NULL,
+  `note` VARCHAR(255) NOT NULL DEFAULT '',
+  `created_at` DATETIME NOT NULL,
+  PRIMARY KEY (`id`),
+  INDEX `lease_idx` (`id_lease` ASC) VISIBLE,
+  CONSTRAINT `fk_payment_lease`
+    FOREIGN KEY (`id_lease`)
+    REFERENCES `agency`.`leases` (`id`)
+    ON DELETE CASCADE
+    ON UPDATE NO ACTION)
+ENGINE = InnoDB;
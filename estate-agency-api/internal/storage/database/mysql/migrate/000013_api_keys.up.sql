@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS `agency`.`api_keys` (
+  `id` INT NOT NULL AUTO_INCREMENT,
+  `name` VARCHAR(100) NOT NULL,
+  `prefix` VARCHAR(16) NOT NULL,
+  `key_hash` CHAR(64) NOT NULL,
+  `scopes` JSON NOT NULL,
+  `expires_at` DATETIME NULL DEFAULT NULL,
+  `last_used_at` DATETIME NULL DEFAULT NULL,
+  `revoked_at` DATETIME NULL DEFAULT NULL,
+  `created_by` VARCHAR(255) NOT NULL,
+  `created_at` DATETIME NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `key_hash_UNIQUE` (`key_hash` ASC) VISIBLE)
+ENGINE = InnoDB;
@@ -0,0 +1,26 @@
+ALTER TABLE `agency`.`outbox_events`
+  DROP INDEX `published_at_idx`,
+  ADD INDEX `published_at_idx` (`published_at` ASC, `id` ASC) VISIBLE,
+  DROP COLUMN `agency_id`;
+
+ALTER TABLE `agency`.`audit_log` DROP INDEX `agency_idx`, DROP COLUMN `agency_id`;
+
+ALTER TABLE `agency`.`webhooks` DROP FOREIGN KEY `fk_webhook_agency`;
+ALTER TABLE `agency`.`webhooks` DROP INDEX `agency_idx`, DROP COLUMN `agency_id`;
+
+ALTER TABLE `agency`.`api_keys` DROP FOREIGN KEY `fk_api_key_agency`;
+ALTER TABLE `agency`.`api_keys` DROP INDEX `agency_idx`, DROP COLUMN `agency_id`;
+
+ALTER TABLE `agency`.`clients` DROP FOREIGN KEY `fk_client_agency`;
+ALTER TABLE `agency`.`clients`
+  DROP INDEX `agency_email_UNIQUE`,
+  ADD UNIQUE INDEX `email_UNIQUE` (`email` ASC) VISIBLE,
+  DROP COLUMN `agency_id`;
+
+ALTER TABLE `agency`.`Apartments` DROP FOREIGN KEY `fk_apartment_agency`;
+ALTER TABLE `agency`.`Apartments` DROP INDEX `agency_idx`, DROP COLUMN `agency_id`;
+
+ALTER TABLE `agency`.`Realtors` DROP FOREIGN KEY `fk_realtor_agency`;
+ALTER TABLE `agency`.`Realtors` DROP INDEX `agency_idx`, DROP COLUMN `agency_id`;
+
+DROP TABLE IF EXISTS `agency`.`agencies`;
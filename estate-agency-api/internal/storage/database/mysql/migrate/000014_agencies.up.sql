@@ -0,0 +1,67 @@
+CREATE TABLE IF NOT EXISTS `agency`.`agencies` (
+  `id` INT NOT NULL AUTO_INCREMENT,
+  `name` VARCHAR(100) NOT NULL,
+  `slug` VARCHAR(63) NOT NULL,
+  `created_at` DATETIME NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `slug_UNIQUE` (`slug` ASC) VISIBLE)
+ENGINE = InnoDB;
+
+-- Existing data becomes the default agency.
+INSERT INTO `agency`.`agencies` (`id`, `name`, `slug`, `created_at`) VALUES (1, 'Default', 'default', NOW());
+
+ALTER TABLE `agency`.`Realtors`
+  ADD COLUMN `agency_id` INT NOT NULL DEFAULT 1,
+  ADD INDEX `agency_idx` (`agency_id` ASC) VISIBLE,
+  ADD CONSTRAINT `fk_realtor_agency`
+    FOREIGN KEY (`agency_id`)
+    REFERENCES `agency`.`agencies` (`id`)
+    ON DELETE RESTRICT
+    ON UPDATE NO ACTION;
+
+ALTER TABLE `agency`.`Apartments`
+  ADD COLUMN `agency_id` INT NOT NULL DEFAULT 1,
+  ADD INDEX `agency_idx` (`agency_id` ASC) VISIBLE,
+  ADD CONSTRAINT `fk_apartment_agency`
+    FOREIGN KEY (`agency_id`)
+    REFERENCES `agency`.`agencies` (`id`)
+    ON DELETE RESTRICT
+    ON UPDATE NO ACTION;
+
+-- Client emails are unique within an agency only.
+ALTER TABLE `agency`.`clients`
+  ADD COLUMN `agency_id` INT NOT NULL DEFAULT 1,
+  DROP INDEX `email_UNIQUE`,
+  ADD UNIQUE INDEX `agency_email_UNIQUE` (`agency_id` ASC, `email` ASC) VISIBLE,
+  ADD CONSTRAINT `fk_client_agency`
+    FOREIGN KEY (`agency_id`)
+    REFERENCES `agency`.`agencies` (`id`)
+    ON DELETE RESTRICT
+    ON UPDATE NO ACTION;
+
+ALTER TABLE `agency`.`api_keys`
+  ADD COLUMN `agency_id` INT NOT NULL DEFAULT 1,
+  ADD INDEX `agency_idx` (`agency_id` ASC) VISIBLE,
+  ADD CONSTRAINT `fk_api_key_agency`
+    FOREIGN KEY (`agency_id`)
+    REFERENCES `agency`.`agencies` (`id`)
+    ON DELETE RESTRICT
+    ON UPDATE NO ACTION;
+
+ALTER TABLE `agency`.`webhooks`
+  ADD COLUMN `agency_id` INT NOT NULL DEFAULT 1,
+  ADD INDEX `agency_idx` (`agency_id` ASC) VISIBLE,
+  ADD CONSTRAINT `fk_webhook_agency`
+    FOREIGN KEY (`agency_id`)
+    REFERENCES `agency`.`agencies` (`id`)
+    ON DELETE RESTRICT
+    ON UPDATE NO ACTION;
+
+ALTER TABLE `agency`.`audit_log`
+  ADD COLUMN `agency_id` INT NOT NULL DEFAULT 1,
+  ADD INDEX `agency_idx` (`agency_id` ASC, `id` ASC) VISIBLE;
+
+ALTER TABLE `agency`.`outbox_events`
+  ADD COLUMN `agency_id` INT NOT NULL DEFAULT 1,
+  DROP INDEX `published_at_idx`,
+  ADD INDEX `published_at_idx` (`agency_id` ASC, `published_at` ASC, `id` ASC) VISIBLE;
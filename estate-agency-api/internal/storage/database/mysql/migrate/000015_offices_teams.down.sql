@@ -0,0 +1,8 @@
+ALTER TABLE `agency`.`api_keys` DROP FOREIGN KEY `fk_api_key_realtor`;
+ALTER TABLE `agency`.`api_keys` DROP INDEX `realtor_idx`, DROP COLUMN `id_realtor`;
+
+ALTER TABLE `agency`.`Realtors` DROP FOREIGN KEY `fk_realtor_team`;
+ALTER TABLE `agency`.`Realtors` DROP INDEX `team_idx`, DROP COLUMN `id_team`;
+
+DROP TABLE IF EXISTS `agency`.`teams`;
+DROP TABLE IF EXISTS `agency`.`offices`;
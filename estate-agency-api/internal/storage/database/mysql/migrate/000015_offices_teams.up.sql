@@ -0,0 +1,62 @@
+CREATE TABLE IF NOT EXISTS `agency`.`offices` (
+  `id` INT NOT NULL AUTO_INCREMENT,
+  `agency_id` INT NOT NULL,
+  `name` VARCHAR(100) NOT NULL,
+  `address` VARCHAR(255) NOT NULL DEFAULT '',
+  `created_at` DATETIME NOT NULL,
+  PRIMARY KEY (`id`),
+  INDEX `agency_idx` (`agency_id` ASC) VISIBLE,
+  CONSTRAINT `fk_office_agency`
+    FOREIGN KEY (`agency_id`)
+    REFERENCES `agency`.`agencies` (`id`)
+    ON DELETE RESTRICT
+    ON UPDATE NO ACTION)
+ENGINE = InnoDB;
+
+CREATE TABLE IF NOT EXISTS `agency`.`teams` (
+  `id` INT NOT NULL AUTO_INCREMENT,
+  `agency_id` INT NOT NULL,
+  `id_office` INT NOT NULL,
+  `name` VARCHAR(100) NOT NULL,
+  `id_lead` INT NULL DEFAULT NULL,
+  `created_at` DATETIME NOT NULL,
+  PRIMARY KEY (`id`),
+  INDEX `agency_idx` (`agency_id` ASC) VISIBLE,
+  INDEX `office_idx` (`id_office` ASC) VISIBLE,
+  INDEX `lead_idx` (`id_lead` ASC) VISIBLE,
+  CONSTRAINT `fk_team_agency`
+    FOREIGN KEY (`agency_id`)
+    REFERENCES `agency`.`agencies` (`id`)
+    ON DELETE RESTRICT
+    ON UPDATE NO ACTION,
+  CONSTRAINT `fk_team_office`
+    FOREIGN KEY (`id_office`)
+    REFERENCES `agency`.`offices` (`id`)
+    ON DELETE RESTRICT
+    ON UPDATE NO ACTION,
+  CONSTRAINT `fk_team_lead`
+    FOREIGN KEY (`id_lead`)
+    REFERENCES `agency`.`Realtors` (`id`)
+    ON DELETE SET NULL
+    ON UPDATE NO ACTION)
+ENGINE = InnoDB;
+
+-- A realtor is a member of at most one team.
+ALTER TABLE `agency`.`Realtors`
+  ADD COLUMN `id_team` INT NULL DEFAULT NULL,
+  ADD INDEX `team_idx` (`id_team` ASC) VISIBLE,
+  ADD CONSTRAINT `fk_realtor_team`
+    FOREIGN KEY (`id_team`)
+    REFERENCES `agency`.`teams` (`id`)
+    ON DELETE SET NULL
+    ON UPDATE NO ACTION;
+
+-- A key issued to a realtor acts on their behalf and is limited to the listings they may manage.
+ALTER TABLE `agency`.`api_keys`
+  ADD COLUMN `id_realtor` INT NULL DEFAULT NULL,
+  ADD INDEX `realtor_idx` (`id_realtor` ASC) VISIBLE,
+  ADD CONSTRAINT `fk_api_key_realtor`
+    FOREIGN KEY (`id_realtor`)
+    REFERENCES `agency`.`Realtors` (`id`)
+    ON DELETE CASCADE
+    ON UPDATE NO ACTION;
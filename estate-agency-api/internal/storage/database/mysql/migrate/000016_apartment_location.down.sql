@@ -0,0 +1,5 @@
+ALTER TABLE `agency`.`Apartments`
+  DROP INDEX `location_idx`,
+  DROP COLUMN `location`,
+  DROP COLUMN `lng`,
+  DROP COLUMN `lat`;
@@ -0,0 +1,7 @@
+-- location mirrors lat and lng for the spatial index. MySQL reads SRID 4326 points as (lat, lng), and a spatial
+-- index needs a NOT NULL column, so apartments without coordinates get POINT(0, 0) and are told apart by lat IS NULL.
+ALTER TABLE `agency`.`Apartments`
+  ADD COLUMN `lat` DOUBLE NULL DEFAULT NULL,
+  ADD COLUMN `lng` DOUBLE NULL DEFAULT NULL,
+  ADD COLUMN `location` POINT SRID 4326 GENERATED ALWAYS AS (ST_SRID(POINT(COALESCE(`lat`, 0), COALESCE(`lng`, 0)), 4326)) STORED NOT NULL,
+  ADD SPATIAL INDEX `location_idx` (`location`) VISIBLE;
@@ -0,0 +1,6 @@
+ALTER TABLE `agency`.`Apartments`
+  DROP INDEX `address_idx`,
+  DROP COLUMN `address_city`,
+  DROP COLUMN `address_building`,
+  DROP COLUMN `address_house`,
+  DROP COLUMN `address_street`;
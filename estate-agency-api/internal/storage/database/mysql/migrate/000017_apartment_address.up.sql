@@ -0,0 +1,6 @@
+ALTER TABLE `agency`.`Apartments`
+  ADD COLUMN `address_street` VARCHAR(100) NOT NULL DEFAULT '',
+  ADD COLUMN `address_house` VARCHAR(20) NOT NULL DEFAULT '',
+  ADD COLUMN `address_building` VARCHAR(20) NOT NULL DEFAULT '',
+  ADD COLUMN `address_city` VARCHAR(100) NOT NULL DEFAULT '',
+  ADD INDEX `address_idx` (`agency_id` ASC, `address_city` ASC, `address_street` ASC, `address_house` ASC) VISIBLE;
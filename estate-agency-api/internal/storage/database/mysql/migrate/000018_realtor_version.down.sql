@@ -0,0 +1 @@
+ALTER TABLE `agency`.`Realtors` DROP COLUMN `version`;
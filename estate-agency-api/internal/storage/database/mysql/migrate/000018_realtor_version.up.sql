@@ -0,0 +1,2 @@
+ALTER TABLE `agency`.`Realtors`
+  ADD COLUMN `version` INT NOT NULL DEFAULT 1 AFTER `experience`;
@@ -0,0 +1,17 @@
+-- The progress of every outbox event through each subscriber, so that a failing subscriber is retried alone
+-- and is given up on after a number of attempts.
+CREATE TABLE IF NOT EXISTS `agency`.`outbox_deliveries` (
+  `event_id` BIGINT NOT NULL,
+  `subscriber` VARCHAR(64) NOT NULL,
+  `status` VARCHAR(16) NOT NULL DEFAULT 'pending',
+  `attempts` INT NOT NULL DEFAULT 0,
+  `last_error` TEXT NOT NULL,
+  `updated_at` DATETIME(6) NOT NULL,
+  PRIMARY KEY (`event_id`, `subscriber`),
+  INDEX `status_idx` (`status` ASC) VISIBLE,
+  CONSTRAINT `fk_outbox_delivery_event`
+    FOREIGN KEY (`event_id`)
+    REFERENCES `agency`.`outbox_events` (`id`)
+    ON DELETE CASCADE
+    ON UPDATE NO ACTION)
+ENGINE = InnoDB;
@@ -1,11 +1,16 @@
 package mysql
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
 	"time"
 
+	"github.com/XSAM/otelsql"
 	_ "github.com/go-sql-driver/mysql"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type StorageConfig struct {
@@ -27,6 +32,18 @@ type ConfigDSN struct {
 
 const driverName = "mysql"
 
+// tracedOptions makes queries children of the caller's span. Queries without a span, such as the ones
+// run by background workers, are not traced so they don't flood the exporter.
+var tracedOptions = []otelsql.Option{
+	otelsql.WithAttributes(semconv.DBSystemMySQL),
+	otelsql.WithSpanOptions(otelsql.SpanOptions{
+		OmitConnResetSession: true,
+		SpanFilter: func(ctx context.Context, method otelsql.Method, query string, args []driver.NamedValue) bool {
+			return trace.SpanContextFromContext(ctx).IsValid()
+		},
+	}),
+}
+
 func New(storageCfg *StorageConfig) (*sql.DB, error) {
 	const op = "storage.mysql.New"
 
@@ -35,10 +52,10 @@ func New(storageCfg *StorageConfig) (*sql.DB, error) {
 		err error
 	)
 	if storageCfg.StoragePath == "" {
-		path := fmt.Sprintf("%s:%s@%s(%s:%s)/%s", storageCfg.ConfigDSN.User, storageCfg.ConfigDSN.Password, storageCfg.ConfigDSN.Protocol, storageCfg.ConfigDSN.Host, storageCfg.ConfigDSN.Port, storageCfg.ConfigDSN.NameDB)
-		DB, err = sql.Open(driverName, path)
+		path := fmt.Sprintf("%s:%s@%s(%s:%s)/%s?parseTime=true", storageCfg.ConfigDSN.User, storageCfg.ConfigDSN.Password, storageCfg.ConfigDSN.Protocol, storageCfg.ConfigDSN.Host, storageCfg.ConfigDSN.Port, storageCfg.ConfigDSN.NameDB)
+		DB, err = otelsql.Open(driverName, path, tracedOptions...)
 	} else {
-		DB, err = sql.Open(driverName, storageCfg.StoragePath)
+		DB, err = otelsql.Open(driverName, storageCfg.StoragePath, tracedOptions...)
 	}
 
 	if err != nil {
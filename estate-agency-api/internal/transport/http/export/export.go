@@ -0,0 +1,202 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/xuri/excelize/v2"
+)
+
+type Format string
+
+const (
+	FormatCSV   Format = "csv"
+	FormatJSONL Format = "jsonl"
+	FormatXLSX  Format = "xlsx"
+)
+
+var ErrUnknownFormat = errors.New("unknown export format")
+
+const xlsxSheet = "Sheet1"
+
+// Writer encodes records one by one, so callers can stream rows straight from storage.
+type Writer interface {
+	Write(record []any) error
+	Close() error
+}
+
+func ParseFormat(format string) (Format, error) {
+	switch f := Format(format); f {
+	case FormatCSV, FormatJSONL, FormatXLSX:
+		return f, nil
+	}
+
+	return "", fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+}
+
+func (f Format) ContentType() string {
+	switch f {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatJSONL:
+		return "application/x-ndjson"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+
+	return "application/octet-stream"
+}
+
+func (f Format) ContentDisposition(name string) string {
+	return fmt.Sprintf("attachment; filename=%q", name+"."+string(f))
+}
+
+func NewWriter(format Format, w io.Writer, columns []string) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, columns)
+	case FormatJSONL:
+		return newJSONLWriter(w, columns)
+	case FormatXLSX:
+		return newXLSXWriter(w, columns)
+	}
+
+	return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+}
+
+type csvWriter struct {
+	writer *csv.Writer
+	record []string
+}
+
+func newCSVWriter(w io.Writer, columns []string) (*csvWriter, error) {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(columns); err != nil {
+		return nil, err
+	}
+
+	return &csvWriter{writer: writer, record: make([]string, len(columns))}, nil
+}
+
+func (w *csvWriter) Write(record []any) error {
+	for i, value := range record {
+		switch v := value.(type) {
+		case string:
+			w.record[i] = v
+		case int:
+			w.record[i] = strconv.Itoa(v)
+		default:
+			w.record[i] = fmt.Sprint(v)
+		}
+	}
+
+	return w.writer.Write(w.record)
+}
+
+func (w *csvWriter) Close() error {
+	w.writer.Flush()
+
+	return w.writer.Error()
+}
+
+type jsonlWriter struct {
+	writer  io.Writer
+	columns [][]byte
+	buf     []byte
+}
+
+func newJSONLWriter(w io.Writer, columns []string) (*jsonlWriter, error) {
+	keys := make([][]byte, len(columns))
+	for i, column := range columns {
+		key, err := json.Marshal(column)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = key
+	}
+
+	return &jsonlWriter{writer: w, columns: keys}, nil
+}
+
+// Write keeps the column order of the export instead of the sorted order of a marshalled map.
+func (w *jsonlWriter) Write(record []any) error {
+	w.buf = append(w.buf[:0], '{')
+	for i, value := range record {
+		if i > 0 {
+			w.buf = append(w.buf, ',')
+		}
+		v, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		w.buf = append(w.buf, w.columns[i]...)
+		w.buf = append(w.buf, ':')
+		w.buf = append(w.buf, v...)
+	}
+	w.buf = append(w.buf, '}', '\n')
+
+	_, err := w.writer.Write(w.buf)
+
+	return err
+}
+
+func (w *jsonlWriter) Close() error {
+	return nil
+}
+
+// xlsxWriter relies on the excelize stream writer, which spills rows to a temporary file
+// instead of keeping the whole sheet in memory. The archive itself is written on Close.
+type xlsxWriter struct {
+	writer io.Writer
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	row    int
+}
+
+func newXLSXWriter(w io.Writer, columns []string) (*xlsxWriter, error) {
+	file := excelize.NewFile()
+
+	stream, err := file.NewStreamWriter(xlsxSheet)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	xw := &xlsxWriter{writer: w, file: file, stream: stream}
+
+	header := make([]any, len(columns))
+	for i, column := range columns {
+		header[i] = column
+	}
+	if err = xw.Write(header); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return xw, nil
+}
+
+func (w *xlsxWriter) Write(record []any) error {
+	w.row++
+
+	cell, err := excelize.CoordinatesToCellName(1, w.row)
+	if err != nil {
+		return err
+	}
+
+	return w.stream.SetRow(cell, record)
+}
+
+func (w *xlsxWriter) Close() error {
+	defer w.file.Close()
+
+	if err := w.stream.Flush(); err != nil {
+		return err
+	}
+
+	return w.file.Write(w.writer)
+}
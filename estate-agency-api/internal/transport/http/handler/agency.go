@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"gilab.com/estate-agency-api/internal/entity"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	agenciesURL = "/admin/agencies"
+	agencyURL   = "/admin/agencies/:agency_id"
+)
+
+type agencyHandler struct {
+	usecase AgencyUsecase
+	logger  *slog.Logger
+}
+
+func NewAgencyHandler(usecase AgencyUsecase, logger *slog.Logger) *agencyHandler {
+	return &agencyHandler{usecase: usecase, logger: logger}
+}
+
+func (h *agencyHandler) Register(router *gin.Engine) {
+	router.GET(agenciesURL, h.GetAgencies)
+	router.GET(agencyURL, h.GetAgency)
+	router.POST(agenciesURL, h.CreateAgency)
+}
+
+func (h *agencyHandler) GetAgencies(ctx *gin.Context) {
+	const op = "handler.GetAgencies"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	const page_size = 10
+	page, err := strconv.Atoi(ctx.DefaultQuery("page", "0"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error page"})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	agencies, err := h.usecase.GetAllAgencies(ct, page, page_size)
+	if errors.Is(err, entity.ErrForbidden) {
+		ctx.JSON(http.StatusForbidden, gin.H{"err": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Info("failed to get", "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to get"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, agencies)
+}
+
+func (h *agencyHandler) GetAgency(ctx *gin.Context) {
+	const op = "handler.GetAgency"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	id, err := strconv.Atoi(ctx.Param("agency_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error id"})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	agency, err := h.usecase.GetAgencyByID(ct, id)
+	if errors.Is(err, entity.ErrForbidden) {
+		ctx.JSON(http.StatusForbidden, gin.H{"err": err.Error()})
+		return
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"err": "not found"})
+		return
+	}
+	if err != nil {
+		log.Info("failed to get", slog.Int("id", id), "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to get"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, agency)
+}
+
+func (h *agencyHandler) CreateAgency(ctx *gin.Context) {
+	const op = "handler.CreateAgency"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	var agency entity.Agency
+	if err := ctx.ShouldBindJSON(&agency); err != nil {
+		log.Info("invalid request", "err", err.Error())
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": err.Error()})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	_, err := h.usecase.CreateAgency(ct, &agency)
+	if errors.Is(err, entity.ErrForbidden) {
+		ctx.JSON(http.StatusForbidden, gin.H{"err": err.Error()})
+		return
+	}
+	if errors.Is(err, entity.ErrValidation) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": err.Error()})
+		return
+	}
+	if errors.Is(err, entity.ErrAgencySlugExists) {
+		ctx.JSON(http.StatusConflict, gin.H{"err": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Info("failed to create", "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to create"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, agency)
+}
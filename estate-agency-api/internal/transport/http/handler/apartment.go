@@ -1,22 +1,29 @@
 package handler
 
 import (
-	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"log/slog"
+	"mime/multipart"
 	"net/http"
 	"strconv"
 	"strings"
 
 	"gilab.com/estate-agency-api/internal/entity"
 	httpModel "gilab.com/estate-agency-api/internal/transport/http/model"
+	"gilab.com/estate-agency-api/pkg/patch"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
 )
 
 const (
-	apartmentsURL = "/apartments"
-	apartmentURL  = "/apartments/:apartment_id"
+	apartmentsURL        = "/apartments"
+	apartmentURL         = "/apartments/:apartment_id"
+	apartmentExportURL   = "/apartments/export"
+	apartmentRestoreURL  = "/apartments/:apartment_id/restore"
+	deletedApartmentsURL = "/admin/apartments/deleted"
 )
 
 type apartmentHandler struct {
@@ -32,15 +39,26 @@ func NewApartmentHandler(usecase Usecase, logger *slog.Logger) *apartmentHandler
 func (h *apartmentHandler) Register(router *gin.Engine) {
 	router.GET(apartmentsURL, h.GetApartments)
 	router.GET(apartmentURL, h.GetApartment)
+	router.GET(apartmentExportURL, h.ExportApartments)
 	router.POST(apartmentsURL, h.CreateApartment)
 	router.PATCH(apartmentURL, h.UpdateApartment)
 	router.DELETE(apartmentURL, h.DeleteApartment)
+	router.POST(apartmentRestoreURL, h.RestoreApartment)
+	router.GET(deletedApartmentsURL, h.GetDeletedApartments)
+}
+
+// bindApartmentFilter binds the apartment filter of the query string, including its geo conditions.
+func bindApartmentFilter(ctx *gin.Context, filter *entity.ApartmentFilter) error {
+	if err := ctx.ShouldBindQuery(filter); err != nil {
+		return err
+	}
+	return filter.ParseGeo()
 }
 
 func (h *apartmentHandler) GetApartments(ctx *gin.Context) {
 	const op = "handler.GetApartments"
 
-	log := h.logger.With(slog.String("op", op))
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
 
 	const page_size = 10
 	page, err := strconv.Atoi(ctx.DefaultQuery("page", "0"))
@@ -50,8 +68,15 @@ func (h *apartmentHandler) GetApartments(ctx *gin.Context) {
 		return
 	}
 
-	ct := context.WithValue(context.Background(), "logger", h.logger)
-	apartments, err := h.usecase.GetAllApartment(ct, page, page_size)
+	var filter entity.ApartmentFilter
+	if err = bindApartmentFilter(ctx, &filter); err != nil {
+		log.Info("bad filter", "err", err.Error())
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "invalid filter"})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	apartments, err := h.usecase.GetAllApartment(ct, filter, page, page_size)
 
 	if err != nil {
 		log.Info("page not found", slog.Int("id", page))
@@ -64,10 +89,30 @@ func (h *apartmentHandler) GetApartments(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, apartments)
 }
 
+func (h *apartmentHandler) ExportApartments(ctx *gin.Context) {
+	const op = "handler.ExportApartments"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	var filter entity.ApartmentFilter
+	if err := bindApartmentFilter(ctx, &filter); err != nil {
+		log.Info("bad filter", "err", err.Error())
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "invalid filter"})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	writeExport(ctx, log, "apartments", apartmentColumns, func(write func(record []any) error) error {
+		return h.usecase.ExportApartments(ct, filter, func(apartment *entity.Apartment) error {
+			return write(apartmentRecord(apartment))
+		})
+	})
+}
+
 func (h *apartmentHandler) GetApartment(ctx *gin.Context) {
 	const op = "handler.GetApartment"
 
-	log := h.logger.With(slog.String("op", op))
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
 
 	id, err := strconv.Atoi(ctx.Param("apartment_id"))
 	if err != nil {
@@ -76,7 +121,7 @@ func (h *apartmentHandler) GetApartment(ctx *gin.Context) {
 		return
 	}
 
-	ct := context.WithValue(context.Background(), "logger", h.logger)
+	ct := requestContext(ctx, h.logger)
 	apartment, realtor, err := h.usecase.GetApartmentByID(ct, id)
 	if err != nil {
 		log.Info("not found", slog.Int("id", id))
@@ -84,6 +129,13 @@ func (h *apartmentHandler) GetApartment(ctx *gin.Context) {
 		return
 	}
 
+	tag := viewETag(apartment.Version, realtor.Version)
+	ctx.Header("ETag", tag)
+	if noneMatch(ctx.GetHeader("If-None-Match"), tag) {
+		ctx.Status(http.StatusNotModified)
+		return
+	}
+
 	realtorView := httpModel.RealtorView{
 		FirstName: realtor.FirstName,
 		LastName:  realtor.LastName,
@@ -106,7 +158,7 @@ func (h *apartmentHandler) GetApartment(ctx *gin.Context) {
 func (h *apartmentHandler) CreateApartment(ctx *gin.Context) {
 	const op = "handler.CreateApartment"
 
-	log := h.logger.With(slog.String("op", op))
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
 
 	var apartment entity.Apartment
 
@@ -136,9 +188,16 @@ func (h *apartmentHandler) CreateApartment(ctx *gin.Context) {
 		return
 	}
 
-	ct := context.WithValue(context.Background(), "logger", h.logger)
+	ct := requestContext(ctx, h.logger)
 	id, err := h.usecase.CreateApartment(ct, &apartment)
-
+	if errors.Is(err, entity.ErrValidation) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": err.Error()})
+		return
+	}
+	if errors.Is(err, entity.ErrForbidden) {
+		ctx.JSON(http.StatusForbidden, gin.H{"err": err.Error()})
+		return
+	}
 	if err != nil {
 		log.Info("failed to create", "err", err.Error())
 		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to create"})
@@ -153,9 +212,8 @@ func (h *apartmentHandler) CreateApartment(ctx *gin.Context) {
 func (h *apartmentHandler) UpdateApartment(ctx *gin.Context) {
 	const op = "handler.UpdateApartment"
 
-	log := h.logger.With("op", op)
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
 
-	var apartment entity.Apartment
 	id, err := strconv.Atoi(ctx.Param("apartment_id"))
 	if err != nil {
 		log.Info("invalid request")
@@ -163,50 +221,86 @@ func (h *apartmentHandler) UpdateApartment(ctx *gin.Context) {
 		return
 	}
 
-	if err = ctx.Bind(&apartment); err != nil {
-		log.Info("invalid request")
-		ctx.JSON(http.StatusBadRequest, gin.H{"err": "invalid request"})
-		return
-	}
-
-	file, err_file := ctx.FormFile("photo")
-	if err_file != nil && err_file.Error() != "http: no such file" {
-		ctx.JSON(http.StatusBadRequest, gin.H{"err": "invalid request"})
+	version, err := ifMatchVersion(ctx.GetHeader("If-Match"))
+	if err != nil {
+		log.Info("bad precondition", "err", err.Error())
+		ctx.JSON(preconditionStatus(err), gin.H{"err": err.Error()})
 		return
 	}
 
-	if err = h.validate.Struct(apartment); err != nil {
-		log.Info("bad validate")
+	doc, err := patchDocument(ctx, apartmentFormFields)
+	if err != nil {
+		log.Info("invalid request", "err", err.Error())
 		ctx.JSON(http.StatusBadRequest, gin.H{"err": err.Error()})
 		return
 	}
 
-	if err_file == nil {
-		file_format := strings.Split(file.Header["Content-Type"][0], "/")[1]
-		if file_format != "png" {
-			log.Info("not png")
-			ctx.JSON(http.StatusBadRequest, gin.H{"err": "file only png"})
+	// The photo is saved only once the update is allowed and applied.
+	var photo *multipart.FileHeader
+	if ctx.ContentType() == binding.MIMEMultipartPOSTForm {
+		file, err_file := ctx.FormFile("photo")
+		if err_file != nil && !errors.Is(err_file, http.ErrMissingFile) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"err": "invalid request"})
 			return
 		}
 
-		ctx.SaveUploadedFile(file, fmt.Sprintf("./../../internal/images/realtor/%d.%s", id, file_format))
+		if err_file == nil {
+			file_format := strings.Split(file.Header["Content-Type"][0], "/")[1]
+			if file_format != "png" {
+				log.Info("not png")
+				ctx.JSON(http.StatusBadRequest, gin.H{"err": "file only png"})
+				return
+			}
+			photo = file
+		}
 	}
 
-	ct := context.WithValue(context.Background(), "logger", h.logger)
-	aff, err := h.usecase.UpdateApartment(ct, id, &apartment)
+	ct := requestContext(ctx, h.logger)
+	apartment, err := h.usecase.UpdateApartment(ct, id, version, doc)
+	if errors.Is(err, entity.ErrVersionConflict) {
+		log.Info("stale update", slog.Int("id", id), slog.Int("version", version))
+		ctx.JSON(http.StatusPreconditionFailed, gin.H{"err": "apartment was modified"})
+		return
+	}
+	if errors.Is(err, entity.ErrValidation) || errors.Is(err, patch.ErrInvalidDocument) {
+		log.Info("bad patch", "err", err.Error())
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": err.Error()})
+		return
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		log.Info("not found", slog.Int("id", id))
+		ctx.JSON(http.StatusNotFound, gin.H{"err": "apartment not found"})
+		return
+	}
+	if errors.Is(err, entity.ErrForbidden) {
+		ctx.JSON(http.StatusForbidden, gin.H{"err": err.Error()})
+		return
+	}
 	if err != nil {
 		log.Info("failed to update", "err", err.Error())
 		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to update"})
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"affected": aff})
+	if photo != nil {
+		ctx.SaveUploadedFile(photo, fmt.Sprintf("./../../internal/images/apartment/%d.png", id))
+	}
+
+	// The tag must match the one of GetApartment, which embeds the realtor. Without the realtor the response
+	// goes untagged rather than failing an update that is already applied.
+	realtor, err := h.usecase.GetRealtorByID(ct, apartment.IDRealtor)
+	if err != nil {
+		log.Info("realtor not loaded", slog.Int("id_realtor", apartment.IDRealtor), "err", err.Error())
+	} else {
+		ctx.Header("ETag", viewETag(apartment.Version, realtor.Version))
+	}
+	ctx.JSON(http.StatusOK, apartment)
 }
 
 func (h *apartmentHandler) DeleteApartment(ctx *gin.Context) {
 	const op = "handler.DeleteApartment"
 
-	log := h.logger.With("op", op)
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
 
 	id, err := strconv.Atoi(ctx.Param("apartment_id"))
 	if err != nil {
@@ -215,8 +309,29 @@ func (h *apartmentHandler) DeleteApartment(ctx *gin.Context) {
 		return
 	}
 
-	ct := context.WithValue(context.Background(), "logger", h.logger)
-	err = h.usecase.DeleteApartment(ct, id)
+	version, err := ifMatchVersion(ctx.GetHeader("If-Match"))
+	if err != nil {
+		log.Info("bad precondition", "err", err.Error())
+		ctx.JSON(preconditionStatus(err), gin.H{"err": err.Error()})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	err = h.usecase.DeleteApartment(ct, id, version)
+	if errors.Is(err, entity.ErrVersionConflict) {
+		log.Info("stale delete", slog.Int("id", id), slog.Int("version", version))
+		ctx.JSON(http.StatusPreconditionFailed, gin.H{"err": "apartment was modified"})
+		return
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		log.Info("not found", slog.Int("id", id))
+		ctx.JSON(http.StatusNotFound, gin.H{"err": "apartment not found"})
+		return
+	}
+	if errors.Is(err, entity.ErrForbidden) {
+		ctx.JSON(http.StatusForbidden, gin.H{"err": err.Error()})
+		return
+	}
 	if err != nil {
 		log.Info("not deleted", slog.Int("id", id), "err", err.Error())
 		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "not deleted"})
@@ -225,3 +340,59 @@ func (h *apartmentHandler) DeleteApartment(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, gin.H{"msg": "deleted"})
 }
+
+func (h *apartmentHandler) RestoreApartment(ctx *gin.Context) {
+	const op = "handler.RestoreApartment"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	id, err := strconv.Atoi(ctx.Param("apartment_id"))
+	if err != nil {
+		log.Info("error id", slog.Int("id", id))
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error id"})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	err = h.usecase.RestoreApartment(ct, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		log.Info("not deleted", slog.Int("id", id))
+		ctx.JSON(http.StatusNotFound, gin.H{"err": "deleted apartment not found"})
+		return
+	}
+	if errors.Is(err, entity.ErrForbidden) {
+		ctx.JSON(http.StatusForbidden, gin.H{"err": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Info("not restored", slog.Int("id", id), "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "not restored"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"msg": "restored"})
+}
+
+func (h *apartmentHandler) GetDeletedApartments(ctx *gin.Context) {
+	const op = "handler.GetDeletedApartments"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	const page_size = 10
+	page, err := strconv.Atoi(ctx.DefaultQuery("page", "0"))
+	if err != nil {
+		log.Info("page id wrong", slog.Int("id", page))
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error page"})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	apartments, err := h.usecase.GetDeletedApartments(ct, page, page_size)
+	if err != nil {
+		log.Info("failed to get", "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to get"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, apartments)
+}
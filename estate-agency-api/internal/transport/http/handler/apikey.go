@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"gilab.com/estate-agency-api/internal/entity"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	apiKeysURL = "/admin/api-keys"
+	apiKeyURL  = "/admin/api-keys/:api_key_id"
+)
+
+type apiKeyHandler struct {
+	usecase APIKeyUsecase
+	logger  *slog.Logger
+}
+
+func NewAPIKeyHandler(usecase APIKeyUsecase, logger *slog.Logger) *apiKeyHandler {
+	return &apiKeyHandler{usecase: usecase, logger: logger}
+}
+
+func (h *apiKeyHandler) Register(router *gin.Engine) {
+	router.GET(apiKeysURL, h.GetAPIKeys)
+	router.GET(apiKeyURL, h.GetAPIKey)
+	router.POST(apiKeysURL, h.CreateAPIKey)
+	router.DELETE(apiKeyURL, h.RevokeAPIKey)
+}
+
+func (h *apiKeyHandler) GetAPIKeys(ctx *gin.Context) {
+	const op = "handler.GetAPIKeys"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	const page_size = 10
+	page, err := strconv.Atoi(ctx.DefaultQuery("page", "0"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error page"})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	keys, err := h.usecase.GetAllAPIKeys(ct, page, page_size)
+	if err != nil {
+		log.Info("failed to get", "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to get"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, keys)
+}
+
+func (h *apiKeyHandler) GetAPIKey(ctx *gin.Context) {
+	const op = "handler.GetAPIKey"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	id, err := strconv.Atoi(ctx.Param("api_key_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error id"})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	key, err := h.usecase.GetAPIKeyByID(ct, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"err": "not found"})
+		return
+	}
+	if err != nil {
+		log.Info("failed to get", slog.Int("id", id), "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to get"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, key)
+}
+
+// CreateAPIKey responds with the plain key, which is not stored and cannot be shown again.
+func (h *apiKeyHandler) CreateAPIKey(ctx *gin.Context) {
+	const op = "handler.CreateAPIKey"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	var key entity.APIKey
+	if err := ctx.ShouldBindJSON(&key); err != nil {
+		log.Info("invalid request", "err", err.Error())
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": err.Error()})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	_, err := h.usecase.CreateAPIKey(ct, &key)
+	if errors.Is(err, entity.ErrValidation) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Info("failed to create", "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to create"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, key)
+}
+
+func (h *apiKeyHandler) RevokeAPIKey(ctx *gin.Context) {
+	const op = "handler.RevokeAPIKey"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	id, err := strconv.Atoi(ctx.Param("api_key_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error id"})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	key, err := h.usecase.RevokeAPIKey(ct, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"err": "not found"})
+		return
+	}
+	if err != nil {
+		log.Info("failed to revoke", slog.Int("id", id), "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to revoke"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, key)
+}
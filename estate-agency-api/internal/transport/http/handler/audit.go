@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"gilab.com/estate-agency-api/internal/entity"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	auditURL = "/audit"
+)
+
+type auditHandler struct {
+	usecase AuditUsecase
+	logger  *slog.Logger
+}
+
+func NewAuditHandler(usecase AuditUsecase, logger *slog.Logger) *auditHandler {
+	return &auditHandler{usecase: usecase, logger: logger}
+}
+
+func (h *auditHandler) Register(router *gin.Engine) {
+	router.GET(auditURL, h.GetAuditLog)
+}
+
+func (h *auditHandler) GetAuditLog(ctx *gin.Context) {
+	const op = "handler.GetAuditLog"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	const page_size = 50
+	page, err := strconv.Atoi(ctx.DefaultQuery("page", "0"))
+	if err != nil {
+		log.Info("page id wrong", slog.Int("id", page))
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error page"})
+		return
+	}
+
+	var filter entity.AuditFilter
+	if err = ctx.ShouldBindQuery(&filter); err != nil {
+		log.Info("bad filter", "err", err.Error())
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "invalid filter"})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	entries, err := h.usecase.GetAuditLog(ct, filter, page, page_size)
+	if err != nil {
+		log.Info("failed to get", "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to get"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, entries)
+}
@@ -0,0 +1,279 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"gilab.com/estate-agency-api/internal/entity"
+	"gilab.com/estate-agency-api/pkg/patch"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	clientsURL        = "/clients"
+	clientURL         = "/clients/:client_id"
+	clientSearchesURL = "/clients/:client_id/searches"
+	clientSearchURL   = "/clients/:client_id/searches/:search_id"
+)
+
+// Form fields accepted by the client PATCH endpoint.
+var clientFormFields = map[string]formKind{
+	"first_name": formText, "last_name": formText, "phone": formText, "email": formText,
+}
+
+type clientHandler struct {
+	usecase ClientUsecase
+	logger  *slog.Logger
+}
+
+func NewClientHandler(usecase ClientUsecase, logger *slog.Logger) *clientHandler {
+	return &clientHandler{usecase: usecase, logger: logger}
+}
+
+func (h *clientHandler) Register(router *gin.Engine) {
+	router.GET(clientsURL, h.GetClients)
+	router.GET(clientURL, h.GetClient)
+	router.POST(clientsURL, h.CreateClient)
+	router.PATCH(clientURL, h.UpdateClient)
+	router.DELETE(clientURL, h.DeleteClient)
+	router.GET(clientSearchesURL, h.GetSavedSearches)
+	router.POST(clientSearchesURL, h.CreateSavedSearch)
+	router.DELETE(clientSearchURL, h.DeleteSavedSearch)
+}
+
+func (h *clientHandler) GetClients(ctx *gin.Context) {
+	const op = "handler.GetClients"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	const page_size = 10
+	page, err := strconv.Atoi(ctx.DefaultQuery("page", "0"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error page"})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	clients, err := h.usecase.GetAllClients(ct, page, page_size)
+	if err != nil {
+		log.Info("failed to get", "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to get"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, clients)
+}
+
+func (h *clientHandler) GetClient(ctx *gin.Context) {
+	const op = "handler.GetClient"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	id, err := strconv.Atoi(ctx.Param("client_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error id"})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	client, err := h.usecase.GetClientByID(ct, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"err": "not found"})
+		return
+	}
+	if err != nil {
+		log.Info("failed to get", slog.Int("id", id), "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to get"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, client)
+}
+
+func (h *clientHandler) CreateClient(ctx *gin.Context) {
+	const op = "handler.CreateClient"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	var client entity.Client
+	if err := ctx.ShouldBind(&client); err != nil {
+		log.Info("invalid request", "err", err.Error())
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": err.Error()})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	id, err := h.usecase.CreateClient(ct, &client)
+	if errors.Is(err, entity.ErrClientEmailExists) {
+		ctx.JSON(http.StatusConflict, gin.H{"err": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Info("failed to create", "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to create"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"client_id": id})
+}
+
+func (h *clientHandler) UpdateClient(ctx *gin.Context) {
+	const op = "handler.UpdateClient"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	id, err := strconv.Atoi(ctx.Param("client_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error id"})
+		return
+	}
+
+	doc, err := patchDocument(ctx, clientFormFields)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": err.Error()})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	client, err := h.usecase.UpdateClient(ct, id, doc)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"err": "not found"})
+		return
+	}
+	if errors.Is(err, entity.ErrClientEmailExists) {
+		ctx.JSON(http.StatusConflict, gin.H{"err": err.Error()})
+		return
+	}
+	if errors.Is(err, entity.ErrValidation) || errors.Is(err, patch.ErrInvalidDocument) {
+		log.Info("bad patch", "err", err.Error())
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Info("failed to update", "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to update"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, client)
+}
+
+func (h *clientHandler) DeleteClient(ctx *gin.Context) {
+	const op = "handler.DeleteClient"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	id, err := strconv.Atoi(ctx.Param("client_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error id"})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	err = h.usecase.DeleteClient(ct, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"err": "not found"})
+		return
+	}
+	if err != nil {
+		log.Info("failed to delete", slog.Int("id", id), "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to delete"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"msg": "deleted"})
+}
+
+func (h *clientHandler) GetSavedSearches(ctx *gin.Context) {
+	const op = "handler.GetSavedSearches"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	id, err := strconv.Atoi(ctx.Param("client_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error id"})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	searches, err := h.usecase.GetSavedSearches(ct, id)
+	if err != nil {
+		log.Info("failed to get", slog.Int("id", id), "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to get"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, searches)
+}
+
+func (h *clientHandler) CreateSavedSearch(ctx *gin.Context) {
+	const op = "handler.CreateSavedSearch"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	id, err := strconv.Atoi(ctx.Param("client_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error id"})
+		return
+	}
+
+	var search entity.SavedSearch
+	if err = ctx.ShouldBindJSON(&search); err != nil {
+		log.Info("invalid request", "err", err.Error())
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": err.Error()})
+		return
+	}
+	search.IDClient = id
+
+	ct := requestContext(ctx, h.logger)
+	searchID, err := h.usecase.CreateSavedSearch(ct, &search)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"err": "client not found"})
+		return
+	}
+	if errors.Is(err, entity.ErrValidation) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Info("failed to create", "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to create"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"search_id": searchID})
+}
+
+func (h *clientHandler) DeleteSavedSearch(ctx *gin.Context) {
+	const op = "handler.DeleteSavedSearch"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	id, err := strconv.Atoi(ctx.Param("client_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error id"})
+		return
+	}
+	searchID, err := strconv.Atoi(ctx.Param("search_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error id"})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	err = h.usecase.DeleteSavedSearch(ct, id, searchID)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"err": "not found"})
+		return
+	}
+	if err != nil {
+		log.Info("failed to delete", slog.Int("id", searchID), "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to delete"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"msg": "deleted"})
+}
@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"context"
+	"log/slog"
+
+	"gilab.com/estate-agency-api/pkg/logging"
+	"gilab.com/estate-agency-api/pkg/reqctx"
+	"github.com/gin-gonic/gin"
+)
+
+// requestLogger returns the logger the logging middleware stored for the request, falling back to the
+// handler's logger, with the authenticated user added once auth has run.
+func requestLogger(ctx *gin.Context, logger *slog.Logger) *slog.Logger {
+	log := logging.FromContext(ctx.Request.Context(), logger)
+	if user := ctx.GetString(gin.AuthUserKey); len(user) != 0 {
+		log = log.With(slog.String("user", user))
+	}
+	return log
+}
+
+// requestContext derives the context passed to the usecase layer from the request context, so the trace,
+// the request ID and the cancellation of the request reach the storage, and adds the logger and the authenticated user.
+func requestContext(ctx *gin.Context, logger *slog.Logger) context.Context {
+	ct := logging.WithLogger(ctx.Request.Context(), requestLogger(ctx, logger))
+	ct = reqctx.WithActor(ct, ctx.GetString(gin.AuthUserKey))
+
+	return ct
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"gilab.com/estate-agency-api/internal/entity"
+)
+
+var (
+	errPreconditionRequired = errors.New("If-Match header required")
+	errPreconditionFailed   = errors.New("If-Match header does not match")
+)
+
+// viewETag tags a view embedding another entity, such as an apartment with its realtor, so that it changes
+// with either of them. The version of the entity itself comes first, as ifMatchVersion expects.
+func viewETag(version int, embeddedVersion int) string {
+	return `"` + strconv.Itoa(version) + "." + strconv.Itoa(embeddedVersion) + `"`
+}
+
+// ifMatchVersion extracts the entity version from the If-Match header sent back by the client, ignoring the
+// versions of embedded entities. If-Match: * gives entity.AnyVersion.
+func ifMatchVersion(header string) (int, error) {
+	header = strings.TrimSpace(header)
+	if len(header) == 0 {
+		return 0, errPreconditionRequired
+	}
+	if header == "*" {
+		return entity.AnyVersion, nil
+	}
+
+	tag := strings.Trim(strings.TrimPrefix(header, "W/"), `"`)
+	if i := strings.IndexByte(tag, '.'); i >= 0 {
+		tag = tag[:i]
+	}
+	version, err := strconv.Atoi(tag)
+	if err != nil || version < 0 {
+		return 0, errPreconditionFailed
+	}
+
+	return version, nil
+}
+
+// noneMatch reports whether the If-None-Match header lists the current ETag.
+func noneMatch(header string, current string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == current {
+			return true
+		}
+	}
+
+	return false
+}
+
+func preconditionStatus(err error) int {
+	if errors.Is(err, errPreconditionRequired) {
+		return http.StatusPreconditionRequired
+	}
+
+	return http.StatusPreconditionFailed
+}
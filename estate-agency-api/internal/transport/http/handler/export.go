@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"gilab.com/estate-agency-api/internal/entity"
+	"gilab.com/estate-agency-api/internal/transport/http/export"
+	"github.com/gin-gonic/gin"
+)
+
+var apartmentColumns = []string{"id", "title", "price", "city", "rooms", "address", "square", "id_realtor", "status", "update_time", "create_time", "listing_type", "monthly_rent", "deposit", "min_term_months", "utilities_included", "pets_allowed"}
+
+func apartmentRecord(apartment *entity.Apartment) []any {
+	return []any{apartment.ID, apartment.Title, apartment.Price, apartment.City, apartment.Rooms, apartment.Address, apartment.Square, apartment.IDRealtor, apartment.Status, apartment.UpdateTime, apartment.CreateTime, apartment.ListingType, apartment.MonthlyRent, apartment.Deposit, apartment.MinTermMonths, apartment.UtilitiesIncluded, apartment.PetsAllowed}
+}
+
+var realtorColumns = []string{"id", "first_name", "last_name", "phone", "email", "rating", "experience"}
+
+func realtorRecord(realtor *entity.Realtor) []any {
+	return []any{realtor.ID, realtor.FirstName, realtor.LastName, realtor.Phone, realtor.Email, realtor.Rating, realtor.Experience}
+}
+
+// writeExport streams the records produced by each into the response in the requested format.
+func writeExport(ctx *gin.Context, log *slog.Logger, name string, columns []string, each func(write func(record []any) error) error) {
+	format, err := export.ParseFormat(ctx.DefaultQuery("format", string(export.FormatCSV)))
+	if err != nil {
+		log.Info("bad format", "err", err.Error())
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "format must be csv, jsonl or xlsx"})
+		return
+	}
+
+	// A large export outlives the server write timeout.
+	if err = http.NewResponseController(ctx.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		log.Info("write deadline not supported", "err", err.Error())
+	}
+
+	ctx.Header("Content-Type", format.ContentType())
+	ctx.Header("Content-Disposition", format.ContentDisposition(name))
+
+	writer, err := export.NewWriter(format, ctx.Writer, columns)
+	if err == nil {
+		err = each(writer.Write)
+		if closeErr := writer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+
+	if err != nil {
+		log.Info("failed to export", "err", err.Error())
+		if ctx.Writer.Written() {
+			ctx.Abort()
+			return
+		}
+		ctx.Writer.Header().Del("Content-Disposition")
+		ctx.Writer.Header().Del("Content-Type")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to export"})
+		return
+	}
+
+	log.Info("exported", slog.String("format", string(format)))
+}
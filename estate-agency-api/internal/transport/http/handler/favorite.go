@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"gilab.com/estate-agency-api/internal/entity"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	clientFavoritesURL   = "/clients/:client_id/favorites"
+	clientFavoriteURL    = "/clients/:client_id/favorites/:apartment_id"
+	realtorFavoritesURL  = "/realtors/:realtor_id/favorites"
+	realtorFavoritesSize = 10
+)
+
+type favoriteHandler struct {
+	usecase FavoriteUsecase
+	logger  *slog.Logger
+}
+
+func NewFavoriteHandler(usecase FavoriteUsecase, logger *slog.Logger) *favoriteHandler {
+	return &favoriteHandler{usecase: usecase, logger: logger}
+}
+
+func (h *favoriteHandler) Register(router *gin.Engine) {
+	router.GET(clientFavoritesURL, h.GetFavorites)
+	router.POST(clientFavoritesURL, h.AddFavorite)
+	router.DELETE(clientFavoriteURL, h.RemoveFavorite)
+	router.GET(realtorFavoritesURL, h.GetMostFavorited)
+}
+
+func (h *favoriteHandler) GetFavorites(ctx *gin.Context) {
+	const op = "handler.GetFavorites"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	id, err := strconv.Atoi(ctx.Param("client_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error id"})
+		return
+	}
+
+	const page_size = 10
+	page, err := strconv.Atoi(ctx.DefaultQuery("page", "0"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error page"})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	favorites, err := h.usecase.GetFavorites(ct, id, page, page_size)
+	if err != nil {
+		log.Info("failed to get", "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to get"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, favorites)
+}
+
+func (h *favoriteHandler) AddFavorite(ctx *gin.Context) {
+	const op = "handler.AddFavorite"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	id, err := strconv.Atoi(ctx.Param("client_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error id"})
+		return
+	}
+
+	var favorite entity.Favorite
+	if err = ctx.ShouldBindJSON(&favorite); err != nil {
+		log.Info("invalid request", "err", err.Error())
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": err.Error()})
+		return
+	}
+	favorite.IDClient = id
+
+	ct := requestContext(ctx, h.logger)
+	err = h.usecase.AddFavorite(ct, &favorite)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"err": "client or apartment not found"})
+		return
+	}
+	if err != nil {
+		log.Info("failed to add", "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to add"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, favorite)
+}
+
+func (h *favoriteHandler) RemoveFavorite(ctx *gin.Context) {
+	const op = "handler.RemoveFavorite"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	id, err := strconv.Atoi(ctx.Param("client_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error id"})
+		return
+	}
+	apartmentID, err := strconv.Atoi(ctx.Param("apartment_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error id"})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	err = h.usecase.RemoveFavorite(ct, id, apartmentID)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"err": "not found"})
+		return
+	}
+	if err != nil {
+		log.Info("failed to remove", slog.Int("id", apartmentID), "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to remove"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"msg": "removed"})
+}
+
+func (h *favoriteHandler) GetMostFavorited(ctx *gin.Context) {
+	const op = "handler.GetMostFavorited"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	id, err := strconv.Atoi(ctx.Param("realtor_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error id"})
+		return
+	}
+
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", strconv.Itoa(realtorFavoritesSize)))
+	if err != nil || limit <= 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error limit"})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	counts, err := h.usecase.GetMostFavorited(ct, id, limit)
+	if err != nil {
+		log.Info("failed to get", "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to get"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, counts)
+}
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	yrlFeedURL = "/feeds/yrl.xml"
+)
+
+type feedHandler struct {
+	usecase FeedUsecase
+	logger  *slog.Logger
+}
+
+func NewFeedHandler(usecase FeedUsecase, logger *slog.Logger) *feedHandler {
+	return &feedHandler{usecase: usecase, logger: logger}
+}
+
+func (h *feedHandler) Register(router *gin.Engine) {
+	router.GET(yrlFeedURL, h.GetYRLFeed)
+}
+
+func (h *feedHandler) GetYRLFeed(ctx *gin.Context) {
+	const op = "handler.GetYRLFeed"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	ct := requestContext(ctx, h.logger)
+	feed, err := h.usecase.GetYRLFeed(ct)
+	if err != nil {
+		log.Info("failed to generate feed", "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to generate feed"})
+		return
+	}
+
+	ctx.Data(http.StatusOK, "application/xml; charset=utf-8", feed)
+}
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	livenessURL  = "/healthz"
+	readinessURL = "/readyz"
+)
+
+type healthHandler struct {
+	monitor HealthMonitor
+	logger  *slog.Logger
+}
+
+func NewHealthHandler(monitor HealthMonitor, logger *slog.Logger) *healthHandler {
+	return &healthHandler{monitor: monitor, logger: logger}
+}
+
+// Register adds the probes. They must be registered before authentication so orchestrators need no credentials.
+func (h *healthHandler) Register(router *gin.Engine) {
+	router.GET(livenessURL, h.Liveness)
+	router.GET(readinessURL, h.Readiness)
+}
+
+// Liveness answers as long as the process serves HTTP; dependencies are not checked.
+func (h *healthHandler) Liveness(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readiness reports the last background check of every dependency and answers 503 while any of them is down.
+func (h *healthHandler) Readiness(ctx *gin.Context) {
+	report := h.monitor.Report()
+	if !report.Ready {
+		ctx.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
@@ -4,18 +4,116 @@ import (
 	"context"
 
 	"gilab.com/estate-agency-api/internal/entity"
+	"gilab.com/estate-agency-api/pkg/patch"
 )
 
 type Usecase interface {
-	GetAllApartment(ctx context.Context, page int, pageSize int) (apartments []*entity.Apartment, err error)
+	GetAllApartment(ctx context.Context, filter entity.ApartmentFilter, page int, pageSize int) (apartments []*entity.Apartment, err error)
+	ExportApartments(ctx context.Context, filter entity.ApartmentFilter, fn func(apartment *entity.Apartment) error) error
 	GetApartmentByID(ctx context.Context, id int) (apartment *entity.Apartment, realtor *entity.Realtor, err error)
 	CreateApartment(ctx context.Context, apartment *entity.Apartment) (id int64, err error)
-	UpdateApartment(ctx context.Context, id int, apartment *entity.Apartment) (aff int64, err error)
-	DeleteApartment(ctx context.Context, id int) error
+	UpdateApartment(ctx context.Context, id int, version int, doc patch.Document) (apartment *entity.Apartment, err error)
+	DeleteApartment(ctx context.Context, id int, version int) error
+	RestoreApartment(ctx context.Context, id int) error
+	GetDeletedApartments(ctx context.Context, page int, pageSize int) (apartments []*entity.Apartment, err error)
 
 	GetAllRealtor(ctx context.Context, page int, pageSize int) (realtors []*entity.Realtor, err error)
+	ExportRealtors(ctx context.Context, fn func(realtor *entity.Realtor) error) error
 	GetRealtorByID(ctx context.Context, id int) (realtor *entity.Realtor, err error)
 	CreateRealtor(ctx context.Context, realtor *entity.Realtor) (id int64, err error)
-	UpdateRealtor(ctx context.Context, id int, realtor *entity.Realtor) (aff int64, err error)
+	UpdateRealtor(ctx context.Context, id int, doc patch.Document) (realtor *entity.Realtor, err error)
 	DeleteRealtor(ctx context.Context, id int) error
+	RestoreRealtor(ctx context.Context, id int) error
+	GetDeletedRealtors(ctx context.Context, page int, pageSize int) (realtors []*entity.Realtor, err error)
+}
+
+type FeedUsecase interface {
+	GetYRLFeed(ctx context.Context) (feed []byte, err error)
+}
+
+type AuditUsecase interface {
+	GetAuditLog(ctx context.Context, filter entity.AuditFilter, page int, pageSize int) (entries []*entity.AuditEntry, err error)
+}
+
+type WebhookUsecase interface {
+	GetAllWebhooks(ctx context.Context, page int, pageSize int) (webhooks []*entity.Webhook, err error)
+	GetWebhookByID(ctx context.Context, id int) (webhook *entity.Webhook, err error)
+	CreateWebhook(ctx context.Context, webhook *entity.Webhook) (id int64, err error)
+	UpdateWebhook(ctx context.Context, id int, doc patch.Document) (webhook *entity.Webhook, err error)
+	DeleteWebhook(ctx context.Context, id int) error
+	GetWebhookDeliveries(ctx context.Context, webhookID int, filter entity.WebhookDeliveryFilter, page int, pageSize int) (deliveries []*entity.WebhookDelivery, err error)
+}
+
+type StreamUsecase interface {
+	StreamApartments(ctx context.Context, filter entity.ApartmentFilter, lastEventID int64) (subscription *entity.Subscription, err error)
+}
+
+type ClientUsecase interface {
+	GetAllClients(ctx context.Context, page int, pageSize int) (clients []*entity.Client, err error)
+	GetClientByID(ctx context.Context, id int) (client *entity.Client, err error)
+	CreateClient(ctx context.Context, client *entity.Client) (id int64, err error)
+	UpdateClient(ctx context.Context, id int, doc patch.Document) (client *entity.Client, err error)
+	DeleteClient(ctx context.Context, id int) error
+
+	GetSavedSearches(ctx context.Context, clientID int) (searches []*entity.SavedSearch, err error)
+	CreateSavedSearch(ctx context.Context, search *entity.SavedSearch) (id int64, err error)
+	DeleteSavedSearch(ctx context.Context, clientID int, id int) error
+}
+
+type FavoriteUsecase interface {
+	AddFavorite(ctx context.Context, favorite *entity.Favorite) error
+	RemoveFavorite(ctx context.Context, clientID int, apartmentID int) error
+	GetFavorites(ctx context.Context, clientID int, page int, pageSize int) (favorites []*entity.FavoriteView, err error)
+	GetMostFavorited(ctx context.Context, realtorID int, limit int) (counts []*entity.FavoriteCount, err error)
+}
+
+type LeaseUsecase interface {
+	GetAllLeases(ctx context.Context, filter entity.LeaseFilter, page int, pageSize int) (leases []*entity.Lease, err error)
+	GetLeaseByID(ctx context.Context, id int) (lease *entity.Lease, err error)
+	CreateLease(ctx context.Context, lease *entity.Lease) (id int64, err error)
+	RenewLease(ctx context.Context, id int, renewal entity.LeaseRenewal) (lease *entity.Lease, err error)
+	TerminateLease(ctx context.Context, id int, termination entity.LeaseTermination) (lease *entity.Lease, err error)
+	GetExpiringLeases(ctx context.Context, days int) (leases []*entity.Lease, err error)
+	GetRentSchedule(ctx context.Context, leaseID int) (charges []*entity.RentCharge, err error)
+	GetRentPayments(ctx context.Context, leaseID int) (payments []*entity.RentPayment, err error)
+	RecordRentPayment(ctx context.Context, payment *entity.RentPayment) (id int64, err error)
+	GetLeaseBalance(ctx context.Context, leaseID int) (balance *entity.LeaseBalance, err error)
+	GetOverdueLeases(ctx context.Context, page int, pageSize int) (balances []*entity.LeaseBalance, err error)
+}
+
+type APIKeyUsecase interface {
+	GetAllAPIKeys(ctx context.Context, page int, pageSize int) (keys []*entity.APIKey, err error)
+	GetAPIKeyByID(ctx context.Context, id int) (key *entity.APIKey, err error)
+	CreateAPIKey(ctx context.Context, key *entity.APIKey) (id int64, err error)
+	RevokeAPIKey(ctx context.Context, id int) (key *entity.APIKey, err error)
+}
+
+type AgencyUsecase interface {
+	GetAllAgencies(ctx context.Context, page int, pageSize int) (agencies []*entity.Agency, err error)
+	GetAgencyByID(ctx context.Context, id int) (agency *entity.Agency, err error)
+	CreateAgency(ctx context.Context, agency *entity.Agency) (id int64, err error)
+}
+
+type OfficeUsecase interface {
+	GetAllOffices(ctx context.Context, page int, pageSize int) (offices []*entity.Office, err error)
+	GetOfficeByID(ctx context.Context, id int) (office *entity.Office, err error)
+	CreateOffice(ctx context.Context, office *entity.Office) (id int64, err error)
+	DeleteOffice(ctx context.Context, id int) error
+}
+
+type TeamUsecase interface {
+	GetAllTeams(ctx context.Context, filter entity.TeamFilter, page int, pageSize int) (teams []*entity.Team, err error)
+	GetTeamByID(ctx context.Context, id int) (team *entity.Team, err error)
+	CreateTeam(ctx context.Context, team *entity.Team) (id int64, err error)
+	UpdateTeam(ctx context.Context, id int, team *entity.Team) (updated *entity.Team, err error)
+	DeleteTeam(ctx context.Context, id int) error
+	GetTeamMembers(ctx context.Context, teamID int) (realtors []*entity.Realtor, err error)
+	AddTeamMember(ctx context.Context, teamID int, realtorID int) error
+	RemoveTeamMember(ctx context.Context, teamID int, realtorID int) error
+	GetTeamListings(ctx context.Context, teamID int, filter entity.ApartmentFilter, page int, pageSize int) (apartments []*entity.Apartment, err error)
+	GetTeamStats(ctx context.Context, teamID int) (stats *entity.TeamStats, err error)
+}
+
+type HealthMonitor interface {
+	Report() entity.HealthReport
 }
@@ -0,0 +1,339 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"gilab.com/estate-agency-api/internal/entity"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	leasesURL         = "/leases"
+	leaseURL          = "/leases/:lease_id"
+	leaseRenewURL     = "/leases/:lease_id/renew"
+	leaseTerminateURL = "/leases/:lease_id/terminate"
+	leasesExpiringURL = "/leases/expiring"
+	leasesOverdueURL  = "/leases/overdue"
+	leaseScheduleURL  = "/leases/:lease_id/schedule"
+	leasePaymentsURL  = "/leases/:lease_id/payments"
+	leaseBalanceURL   = "/leases/:lease_id/balance"
+)
+
+const defaultExpiringDays = 30
+
+type leaseHandler struct {
+	usecase LeaseUsecase
+	logger  *slog.Logger
+}
+
+func NewLeaseHandler(usecase LeaseUsecase, logger *slog.Logger) *leaseHandler {
+	return &leaseHandler{usecase: usecase, logger: logger}
+}
+
+func (h *leaseHandler) Register(router *gin.Engine) {
+	router.GET(leasesURL, h.GetLeases)
+	router.GET(leasesExpiringURL, h.GetExpiringLeases)
+	router.GET(leaseURL, h.GetLease)
+	router.POST(leasesURL, h.CreateLease)
+	router.POST(leaseRenewURL, h.RenewLease)
+	router.POST(leaseTerminateURL, h.TerminateLease)
+	router.GET(leasesOverdueURL, h.GetOverdueLeases)
+	router.GET(leaseScheduleURL, h.GetRentSchedule)
+	router.GET(leasePaymentsURL, h.GetRentPayments)
+	router.POST(leasePaymentsURL, h.RecordRentPayment)
+	router.GET(leaseBalanceURL, h.GetLeaseBalance)
+}
+
+func (h *leaseHandler) GetLeases(ctx *gin.Context) {
+	const op = "handler.GetLeases"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	const page_size = 10
+	page, err := strconv.Atoi(ctx.DefaultQuery("page", "0"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error page"})
+		return
+	}
+
+	var filter entity.LeaseFilter
+	if err = ctx.ShouldBindQuery(&filter); err != nil {
+		log.Info("bad filter", "err", err.Error())
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "invalid filter"})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	leases, err := h.usecase.GetAllLeases(ct, filter, page, page_size)
+	if err != nil {
+		log.Info("failed to get", "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to get"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, leases)
+}
+
+func (h *leaseHandler) GetExpiringLeases(ctx *gin.Context) {
+	const op = "handler.GetExpiringLeases"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	days, err := strconv.Atoi(ctx.DefaultQuery("days", strconv.Itoa(defaultExpiringDays)))
+	if err != nil || days < 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error days"})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	leases, err := h.usecase.GetExpiringLeases(ct, days)
+	if err != nil {
+		log.Info("failed to get", "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to get"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, leases)
+}
+
+func (h *leaseHandler) GetLease(ctx *gin.Context) {
+	const op = "handler.GetLease"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	id, err := strconv.Atoi(ctx.Param("lease_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error id"})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	lease, err := h.usecase.GetLeaseByID(ct, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"err": "not found"})
+		return
+	}
+	if err != nil {
+		log.Info("failed to get", slog.Int("id", id), "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to get"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, lease)
+}
+
+func (h *leaseHandler) CreateLease(ctx *gin.Context) {
+	const op = "handler.CreateLease"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	var lease entity.Lease
+	if err := ctx.ShouldBindJSON(&lease); err != nil {
+		log.Info("invalid request", "err", err.Error())
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": err.Error()})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	id, err := h.usecase.CreateLease(ct, &lease)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"err": "apartment or client not found"})
+		return
+	}
+	if errors.Is(err, entity.ErrLeaseOverlap) {
+		ctx.JSON(http.StatusConflict, gin.H{"err": err.Error()})
+		return
+	}
+	if errors.Is(err, entity.ErrValidation) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Info("failed to create", "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to create"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"lease_id": id})
+}
+
+func (h *leaseHandler) RenewLease(ctx *gin.Context) {
+	const op = "handler.RenewLease"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	id, err := strconv.Atoi(ctx.Param("lease_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error id"})
+		return
+	}
+
+	var renewal entity.LeaseRenewal
+	if err = ctx.ShouldBindJSON(&renewal); err != nil {
+		log.Info("invalid request", "err", err.Error())
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": err.Error()})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	lease, err := h.usecase.RenewLease(ct, id, renewal)
+	if !h.writeError(ctx, log, id, err) {
+		ctx.JSON(http.StatusOK, lease)
+	}
+}
+
+func (h *leaseHandler) TerminateLease(ctx *gin.Context) {
+	const op = "handler.TerminateLease"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	id, err := strconv.Atoi(ctx.Param("lease_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error id"})
+		return
+	}
+
+	var termination entity.LeaseTermination
+	if err = ctx.ShouldBindJSON(&termination); err != nil {
+		log.Info("invalid request", "err", err.Error())
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": err.Error()})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	lease, err := h.usecase.TerminateLease(ct, id, termination)
+	if !h.writeError(ctx, log, id, err) {
+		ctx.JSON(http.StatusOK, lease)
+	}
+}
+
+// writeError answers a failed renew or terminate and reports whether err was not nil.
+func (h *leaseHandler) writeError(ctx *gin.Context, log *slog.Logger, id int, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, sql.ErrNoRows):
+		ctx.JSON(http.StatusNotFound, gin.H{"err": "not found"})
+	case errors.Is(err, entity.ErrLeaseNotActive), errors.Is(err, entity.ErrLeaseOverlap):
+		ctx.JSON(http.StatusConflict, gin.H{"err": err.Error()})
+	case errors.Is(err, entity.ErrValidation):
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": err.Error()})
+	default:
+		log.Info("failed to update", slog.Int("id", id), "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to update"})
+	}
+	return true
+}
+
+func (h *leaseHandler) GetOverdueLeases(ctx *gin.Context) {
+	const op = "handler.GetOverdueLeases"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	const page_size = 10
+	page, err := strconv.Atoi(ctx.DefaultQuery("page", "0"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error page"})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	balances, err := h.usecase.GetOverdueLeases(ct, page, page_size)
+	if err != nil {
+		log.Info("failed to get", "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to get"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, balances)
+}
+
+func (h *leaseHandler) GetRentSchedule(ctx *gin.Context) {
+	const op = "handler.GetRentSchedule"
+
+	h.getByLease(ctx, requestLogger(ctx, h.logger).With(slog.String("op", op)), func(ct context.Context, id int) (any, error) {
+		return h.usecase.GetRentSchedule(ct, id)
+	})
+}
+
+func (h *leaseHandler) GetRentPayments(ctx *gin.Context) {
+	const op = "handler.GetRentPayments"
+
+	h.getByLease(ctx, requestLogger(ctx, h.logger).With(slog.String("op", op)), func(ct context.Context, id int) (any, error) {
+		return h.usecase.GetRentPayments(ct, id)
+	})
+}
+
+func (h *leaseHandler) GetLeaseBalance(ctx *gin.Context) {
+	const op = "handler.GetLeaseBalance"
+
+	h.getByLease(ctx, requestLogger(ctx, h.logger).With(slog.String("op", op)), func(ct context.Context, id int) (any, error) {
+		return h.usecase.GetLeaseBalance(ct, id)
+	})
+}
+
+// getByLease answers a read of something that belongs to the lease in the path.
+func (h *leaseHandler) getByLease(ctx *gin.Context, log *slog.Logger, get func(ct context.Context, id int) (any, error)) {
+	id, err := strconv.Atoi(ctx.Param("lease_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error id"})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	result, err := get(ct, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"err": "not found"})
+		return
+	}
+	if err != nil {
+		log.Info("failed to get", slog.Int("id", id), "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to get"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (h *leaseHandler) RecordRentPayment(ctx *gin.Context) {
+	const op = "handler.RecordRentPayment"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	id, err := strconv.Atoi(ctx.Param("lease_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error id"})
+		return
+	}
+
+	var payment entity.RentPayment
+	if err = ctx.ShouldBindJSON(&payment); err != nil {
+		log.Info("invalid request", "err", err.Error())
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": err.Error()})
+		return
+	}
+	payment.IDLease = id
+
+	ct := requestContext(ctx, h.logger)
+	paymentID, err := h.usecase.RecordRentPayment(ct, &payment)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"err": "lease not found"})
+		return
+	}
+	if errors.Is(err, entity.ErrValidation) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Info("failed to record", "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to record"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"payment_id": paymentID})
+}
@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"gilab.com/estate-agency-api/internal/entity"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	officesURL = "/offices"
+	officeURL  = "/offices/:office_id"
+)
+
+type officeHandler struct {
+	usecase OfficeUsecase
+	logger  *slog.Logger
+}
+
+func NewOfficeHandler(usecase OfficeUsecase, logger *slog.Logger) *officeHandler {
+	return &officeHandler{usecase: usecase, logger: logger}
+}
+
+func (h *officeHandler) Register(router *gin.Engine) {
+	router.GET(officesURL, h.GetOffices)
+	router.GET(officeURL, h.GetOffice)
+	router.POST(officesURL, h.CreateOffice)
+	router.DELETE(officeURL, h.DeleteOffice)
+}
+
+func (h *officeHandler) GetOffices(ctx *gin.Context) {
+	const op = "handler.GetOffices"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	const page_size = 10
+	page, err := strconv.Atoi(ctx.DefaultQuery("page", "0"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error page"})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	offices, err := h.usecase.GetAllOffices(ct, page, page_size)
+	if err != nil {
+		log.Info("failed to get", "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to get"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, offices)
+}
+
+func (h *officeHandler) GetOffice(ctx *gin.Context) {
+	const op = "handler.GetOffice"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	id, err := strconv.Atoi(ctx.Param("office_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error id"})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	office, err := h.usecase.GetOfficeByID(ct, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"err": "not found"})
+		return
+	}
+	if err != nil {
+		log.Info("failed to get", slog.Int("id", id), "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to get"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, office)
+}
+
+func (h *officeHandler) CreateOffice(ctx *gin.Context) {
+	const op = "handler.CreateOffice"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	var office entity.Office
+	if err := ctx.ShouldBindJSON(&office); err != nil {
+		log.Info("invalid request", "err", err.Error())
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": err.Error()})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	_, err := h.usecase.CreateOffice(ct, &office)
+	if errors.Is(err, entity.ErrValidation) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": err.Error()})
+		return
+	}
+	if errors.Is(err, entity.ErrForbidden) {
+		ctx.JSON(http.StatusForbidden, gin.H{"err": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Info("failed to create", "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to create"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, office)
+}
+
+func (h *officeHandler) DeleteOffice(ctx *gin.Context) {
+	const op = "handler.DeleteOffice"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	id, err := strconv.Atoi(ctx.Param("office_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error id"})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	err = h.usecase.DeleteOffice(ct, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"err": "not found"})
+		return
+	}
+	if errors.Is(err, entity.ErrOfficeNotEmpty) {
+		ctx.JSON(http.StatusConflict, gin.H{"err": err.Error()})
+		return
+	}
+	if errors.Is(err, entity.ErrForbidden) {
+		ctx.JSON(http.StatusForbidden, gin.H{"err": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Info("failed to delete", slog.Int("id", id), "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to delete"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"msg": "deleted"})
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"gilab.com/estate-agency-api/pkg/patch"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+const maxFormMemory = 32 << 20
+
+// formKind tells how a form value is converted into the merge patch.
+type formKind int
+
+const (
+	formText formKind = iota
+	formNumber
+	formBool
+)
+
+// Form fields accepted by the PATCH endpoints.
+var (
+	apartmentFormFields = map[string]formKind{
+		"title": formText, "price": formNumber, "city": formText, "rooms": formNumber, "address": formText,
+		"square": formNumber, "id_realtor": formNumber, "status": formText,
+		"listing_type": formText, "monthly_rent": formNumber, "deposit": formNumber, "min_term_months": formNumber,
+		"utilities_included": formBool, "pets_allowed": formBool,
+	}
+	realtorFormFields = map[string]formKind{
+		"first_name": formText, "last_name": formText, "phone": formText, "email": formText, "rating": formNumber, "experience": formNumber,
+	}
+)
+
+// patchDocument reads a partial update from the request. JSON bodies are treated as a merge patch,
+// and form submissions become a merge patch of the fields present in the form.
+func patchDocument(ctx *gin.Context, formFields map[string]formKind) (patch.Document, error) {
+	switch contentType := ctx.ContentType(); contentType {
+	case patch.ContentTypeJSONPatch:
+		body, err := ctx.GetRawData()
+		return patch.NewJSONPatch(body), err
+	case patch.ContentTypeMergePatch, binding.MIMEJSON:
+		body, err := ctx.GetRawData()
+		return patch.NewMergePatch(body), err
+	case binding.MIMEMultipartPOSTForm, binding.MIMEPOSTForm:
+		err := ctx.Request.ParseMultipartForm(maxFormMemory)
+		if err != nil && !errors.Is(err, http.ErrNotMultipart) {
+			return patch.Document{}, err
+		}
+
+		doc := make(map[string]any)
+		for field, kind := range formFields {
+			values, ok := ctx.Request.PostForm[field]
+			if !ok {
+				continue
+			}
+			switch {
+			case kind == formText:
+				doc[field] = values[0]
+			case len(values[0]) == 0:
+				doc[field] = nil
+			case kind == formBool:
+				b, err := strconv.ParseBool(values[0])
+				if err != nil {
+					return patch.Document{}, fmt.Errorf("%w: %s must be a boolean", patch.ErrInvalidDocument, field)
+				}
+				doc[field] = b
+			default:
+				n, err := strconv.Atoi(values[0])
+				if err != nil {
+					return patch.Document{}, fmt.Errorf("%w: %s must be a number", patch.ErrInvalidDocument, field)
+				}
+				doc[field] = n
+			}
+		}
+
+		data, err := json.Marshal(doc)
+		return patch.NewMergePatch(data), err
+	default:
+		return patch.Document{}, fmt.Errorf("%w: unsupported content type %q", patch.ErrInvalidDocument, contentType)
+	}
+}
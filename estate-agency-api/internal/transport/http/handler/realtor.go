@@ -1,21 +1,28 @@
 package handler
 
 import (
-	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"log/slog"
+	"mime/multipart"
 	"net/http"
 	"strconv"
 	"strings"
 
 	"gilab.com/estate-agency-api/internal/entity"
+	"gilab.com/estate-agency-api/pkg/patch"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
 )
 
 const (
-	realtorsURL = "/realtors"
-	realtorURL  = "/realtors/:realtor_id"
+	realtorsURL        = "/realtors"
+	realtorURL         = "/realtors/:realtor_id"
+	realtorExportURL   = "/realtors/export"
+	realtorRestoreURL  = "/realtors/:realtor_id/restore"
+	deletedRealtorsURL = "/admin/realtors/deleted"
 )
 
 type realtorHandler struct {
@@ -32,9 +39,12 @@ func NewRealtorHandler(usecase Usecase, logger *slog.Logger) *realtorHandler {
 func (h *realtorHandler) Register(router *gin.Engine) {
 	router.GET(realtorsURL, h.GetRealtors)
 	router.GET(realtorURL, h.GetRealtor)
+	router.GET(realtorExportURL, h.ExportRealtors)
 	router.POST(realtorsURL, h.CreateRealtor)
 	router.PATCH(realtorURL, h.UpdateRealtor)
 	router.DELETE(realtorURL, h.DeleteRealtor)
+	router.POST(realtorRestoreURL, h.RestoreRealtor)
+	router.GET(deletedRealtorsURL, h.GetDeletedRealtors)
 }
 
 func (h *realtorHandler) GetRealtors(ctx *gin.Context) {
@@ -47,7 +57,7 @@ func (h *realtorHandler) GetRealtors(ctx *gin.Context) {
 		return
 	}
 
-	ct := context.WithValue(context.Background(), "logger", h.logger)
+	ct := requestContext(ctx, h.logger)
 	realtors, err := h.usecase.GetAllRealtor(ct, page, page_size)
 
 	if err != nil {
@@ -58,6 +68,19 @@ func (h *realtorHandler) GetRealtors(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, realtors)
 }
 
+func (h *realtorHandler) ExportRealtors(ctx *gin.Context) {
+	const op = "handler.ExportRealtors"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	ct := requestContext(ctx, h.logger)
+	writeExport(ctx, log, "realtors", realtorColumns, func(write func(record []any) error) error {
+		return h.usecase.ExportRealtors(ct, func(realtor *entity.Realtor) error {
+			return write(realtorRecord(realtor))
+		})
+	})
+}
+
 func (h *realtorHandler) GetRealtor(ctx *gin.Context) {
 	const op = "handler.GetRealtor"
 
@@ -68,7 +91,7 @@ func (h *realtorHandler) GetRealtor(ctx *gin.Context) {
 		return
 	}
 
-	ct := context.WithValue(context.Background(), "logger", h.logger)
+	ct := requestContext(ctx, h.logger)
 	realtor, err = h.usecase.GetRealtorByID(ct, id)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to get"})
@@ -107,9 +130,12 @@ func (h *realtorHandler) CreateRealtor(ctx *gin.Context) {
 		return
 	}
 
-	ct := context.WithValue(context.Background(), "logger", h.logger)
+	ct := requestContext(ctx, h.logger)
 	id, err := h.usecase.CreateRealtor(ct, &realtor)
-
+	if errors.Is(err, entity.ErrForbidden) {
+		ctx.JSON(http.StatusForbidden, gin.H{"err": err.Error()})
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to create"})
 		return
@@ -123,47 +149,64 @@ func (h *realtorHandler) CreateRealtor(ctx *gin.Context) {
 func (h *realtorHandler) UpdateRealtor(ctx *gin.Context) {
 	const op = "handler.UpdateRealtor"
 
-	var realtor entity.Realtor
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
 	id, err := strconv.Atoi(ctx.Param("realtor_id"))
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error id"})
 		return
 	}
 
-	if err = ctx.Bind(&realtor); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"err": "invalid request"})
-		return
-	}
-
-	if err = h.validate.Struct(realtor); err != nil {
+	doc, err := patchDocument(ctx, realtorFormFields)
+	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"err": err.Error()})
 		return
 	}
 
-	file, err := ctx.FormFile("photo")
-	if err != nil && err.Error() != "http: no such file" {
-		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error photo"})
-		return
-	}
-
-	if err == nil {
-		file_format := strings.Split(file.Header["Content-Type"][0], "/")[1]
-		if file_format != "png" {
-			ctx.JSON(http.StatusBadRequest, gin.H{"err": "file only png"})
+	var photo *multipart.FileHeader
+	if ctx.ContentType() == binding.MIMEMultipartPOSTForm {
+		file, err := ctx.FormFile("photo")
+		if err != nil && !errors.Is(err, http.ErrMissingFile) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"err": "error photo"})
 			return
 		}
 
-		ctx.SaveUploadedFile(file, fmt.Sprintf("./../../internal/images/realtor/%d.%s", id, file_format))
+		if err == nil {
+			file_format := strings.Split(file.Header["Content-Type"][0], "/")[1]
+			if file_format != "png" {
+				ctx.JSON(http.StatusBadRequest, gin.H{"err": "file only png"})
+				return
+			}
+			photo = file
+		}
 	}
 
-	ct := context.WithValue(context.Background(), "logger", h.logger)
-	aff, err := h.usecase.UpdateRealtor(ct, id, &realtor)
+	ct := requestContext(ctx, h.logger)
+	realtor, err := h.usecase.UpdateRealtor(ct, id, doc)
+	if errors.Is(err, entity.ErrValidation) || errors.Is(err, patch.ErrInvalidDocument) {
+		log.Info("bad patch", "err", err.Error())
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": err.Error()})
+		return
+	}
+	if errors.Is(err, entity.ErrVersionConflict) {
+		ctx.JSON(http.StatusConflict, gin.H{"err": "realtor was modified concurrently"})
+		return
+	}
+	if errors.Is(err, entity.ErrForbidden) {
+		ctx.JSON(http.StatusForbidden, gin.H{"err": err.Error()})
+		return
+	}
 	if err != nil {
+		log.Info("failed to update", "err", err.Error())
 		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to update"})
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"affected": aff})
+	if photo != nil {
+		ctx.SaveUploadedFile(photo, fmt.Sprintf("./../../internal/images/realtor/%d.png", id))
+	}
+
+	ctx.JSON(http.StatusOK, realtor)
 }
 
 func (h *realtorHandler) DeleteRealtor(ctx *gin.Context) {
@@ -175,8 +218,20 @@ func (h *realtorHandler) DeleteRealtor(ctx *gin.Context) {
 		return
 	}
 
-	ct := context.WithValue(context.Background(), "logger", h.logger)
+	ct := requestContext(ctx, h.logger)
 	err = h.usecase.DeleteRealtor(ct, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"err": "not found"})
+		return
+	}
+	if errors.Is(err, entity.ErrVersionConflict) {
+		ctx.JSON(http.StatusConflict, gin.H{"err": "realtor was modified concurrently"})
+		return
+	}
+	if errors.Is(err, entity.ErrForbidden) {
+		ctx.JSON(http.StatusForbidden, gin.H{"err": err.Error()})
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to delete"})
 		return
@@ -184,3 +239,57 @@ func (h *realtorHandler) DeleteRealtor(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, gin.H{"msg": "deleted"})
 }
+
+func (h *realtorHandler) RestoreRealtor(ctx *gin.Context) {
+	const op = "handler.RestoreRealtor"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	id, err := strconv.Atoi(ctx.Param("realtor_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error id"})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	err = h.usecase.RestoreRealtor(ct, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		log.Info("not deleted", slog.Int("id", id))
+		ctx.JSON(http.StatusNotFound, gin.H{"err": "deleted realtor not found"})
+		return
+	}
+	if errors.Is(err, entity.ErrForbidden) {
+		ctx.JSON(http.StatusForbidden, gin.H{"err": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Info("not restored", slog.Int("id", id), "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "not restored"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"msg": "restored"})
+}
+
+func (h *realtorHandler) GetDeletedRealtors(ctx *gin.Context) {
+	const op = "handler.GetDeletedRealtors"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	const page_size = 10
+	page, err := strconv.Atoi(ctx.DefaultQuery("page", "0"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error page"})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	realtors, err := h.usecase.GetDeletedRealtors(ct, page, page_size)
+	if err != nil {
+		log.Info("failed to get", "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to get"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, realtors)
+}
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gilab.com/estate-agency-api/internal/entity"
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	apartmentStreamURL = "/apartments/stream"
+)
+
+// resyncEvent tells a resuming client that some events were lost and the list must be reloaded.
+const resyncEvent = "resync"
+
+type streamHandler struct {
+	usecase   StreamUsecase
+	heartbeat time.Duration
+	logger    *slog.Logger
+}
+
+func NewStreamHandler(usecase StreamUsecase, heartbeat time.Duration, logger *slog.Logger) *streamHandler {
+	return &streamHandler{usecase: usecase, heartbeat: heartbeat, logger: logger}
+}
+
+func (h *streamHandler) Register(router *gin.Engine) {
+	router.GET(apartmentStreamURL, h.StreamApartments)
+}
+
+func (h *streamHandler) StreamApartments(ctx *gin.Context) {
+	const op = "handler.StreamApartments"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	var filter entity.ApartmentFilter
+	if err := bindApartmentFilter(ctx, &filter); err != nil {
+		log.Info("bad filter", "err", err.Error())
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "invalid filter"})
+		return
+	}
+
+	lastEventID := ctx.GetHeader("Last-Event-ID")
+	if len(lastEventID) == 0 {
+		lastEventID = ctx.Query("last_event_id")
+	}
+	var lastID int64
+	if len(lastEventID) != 0 {
+		var err error
+		if lastID, err = strconv.ParseInt(lastEventID, 10, 64); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"err": "invalid Last-Event-ID"})
+			return
+		}
+	}
+
+	ct := requestContext(ctx, h.logger)
+	subscription, err := h.usecase.StreamApartments(ct, filter, lastID)
+	if err != nil {
+		log.Info("failed to subscribe", "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to subscribe"})
+		return
+	}
+	defer subscription.Cancel()
+
+	// The stream outlives the server write timeout.
+	if err = http.NewResponseController(ctx.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		log.Info("write deadline not supported", "err", err.Error())
+	}
+
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+
+	if subscription.Resync {
+		ctx.Render(-1, sse.Event{Event: resyncEvent, Data: gin.H{"last_event_id": lastID}})
+	}
+	for _, event := range subscription.Replay {
+		ctx.Render(-1, sse.Event{Id: strconv.FormatInt(event.ID, 10), Event: event.Type, Data: event})
+	}
+	ctx.Writer.Flush()
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Request.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err = ctx.Writer.WriteString(": heartbeat\n\n"); err != nil {
+				return
+			}
+		case event, ok := <-subscription.Events:
+			if !ok {
+				log.Info("subscriber fell behind")
+				return
+			}
+			ctx.Render(-1, sse.Event{Id: strconv.FormatInt(event.ID, 10), Event: event.Type, Data: event})
+		}
+		ctx.Writer.Flush()
+	}
+}
@@ -0,0 +1,363 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"gilab.com/estate-agency-api/internal/entity"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	teamsURL        = "/teams"
+	teamURL         = "/teams/:team_id"
+	teamMembersURL  = "/teams/:team_id/members"
+	teamMemberURL   = "/teams/:team_id/members/:realtor_id"
+	teamListingsURL = "/teams/:team_id/listings"
+	teamStatsURL    = "/teams/:team_id/stats"
+)
+
+type teamHandler struct {
+	usecase TeamUsecase
+	logger  *slog.Logger
+}
+
+func NewTeamHandler(usecase TeamUsecase, logger *slog.Logger) *teamHandler {
+	return &teamHandler{usecase: usecase, logger: logger}
+}
+
+func (h *teamHandler) Register(router *gin.Engine) {
+	router.GET(teamsURL, h.GetTeams)
+	router.GET(teamURL, h.GetTeam)
+	router.POST(teamsURL, h.CreateTeam)
+	router.PUT(teamURL, h.UpdateTeam)
+	router.DELETE(teamURL, h.DeleteTeam)
+	router.GET(teamMembersURL, h.GetTeamMembers)
+	router.PUT(teamMemberURL, h.AddTeamMember)
+	router.DELETE(teamMemberURL, h.RemoveTeamMember)
+	router.GET(teamListingsURL, h.GetTeamListings)
+	router.GET(teamStatsURL, h.GetTeamStats)
+}
+
+func (h *teamHandler) GetTeams(ctx *gin.Context) {
+	const op = "handler.GetTeams"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	const page_size = 10
+	page, err := strconv.Atoi(ctx.DefaultQuery("page", "0"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error page"})
+		return
+	}
+
+	var filter entity.TeamFilter
+	if err = ctx.ShouldBindQuery(&filter); err != nil {
+		log.Info("bad filter", "err", err.Error())
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "invalid filter"})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	teams, err := h.usecase.GetAllTeams(ct, filter, page, page_size)
+	if err != nil {
+		log.Info("failed to get", "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to get"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, teams)
+}
+
+func (h *teamHandler) GetTeam(ctx *gin.Context) {
+	const op = "handler.GetTeam"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	id, err := strconv.Atoi(ctx.Param("team_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error id"})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	team, err := h.usecase.GetTeamByID(ct, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"err": "not found"})
+		return
+	}
+	if err != nil {
+		log.Info("failed to get", slog.Int("id", id), "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to get"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, team)
+}
+
+func (h *teamHandler) CreateTeam(ctx *gin.Context) {
+	const op = "handler.CreateTeam"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	var team entity.Team
+	if err := ctx.ShouldBindJSON(&team); err != nil {
+		log.Info("invalid request", "err", err.Error())
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": err.Error()})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	_, err := h.usecase.CreateTeam(ct, &team)
+	if errors.Is(err, entity.ErrValidation) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": err.Error()})
+		return
+	}
+	if errors.Is(err, entity.ErrForbidden) {
+		ctx.JSON(http.StatusForbidden, gin.H{"err": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Info("failed to create", "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to create"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, team)
+}
+
+func (h *teamHandler) UpdateTeam(ctx *gin.Context) {
+	const op = "handler.UpdateTeam"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	id, err := strconv.Atoi(ctx.Param("team_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error id"})
+		return
+	}
+
+	var team entity.Team
+	if err = ctx.ShouldBindJSON(&team); err != nil {
+		log.Info("invalid request", "err", err.Error())
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": err.Error()})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	updated, err := h.usecase.UpdateTeam(ct, id, &team)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"err": "not found"})
+		return
+	}
+	if errors.Is(err, entity.ErrValidation) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": err.Error()})
+		return
+	}
+	if errors.Is(err, entity.ErrForbidden) {
+		ctx.JSON(http.StatusForbidden, gin.H{"err": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Info("failed to update", slog.Int("id", id), "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to update"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, updated)
+}
+
+func (h *teamHandler) DeleteTeam(ctx *gin.Context) {
+	const op = "handler.DeleteTeam"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	id, err := strconv.Atoi(ctx.Param("team_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error id"})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	err = h.usecase.DeleteTeam(ct, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"err": "not found"})
+		return
+	}
+	if errors.Is(err, entity.ErrForbidden) {
+		ctx.JSON(http.StatusForbidden, gin.H{"err": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Info("failed to delete", slog.Int("id", id), "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to delete"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"msg": "deleted"})
+}
+
+func (h *teamHandler) GetTeamMembers(ctx *gin.Context) {
+	const op = "handler.GetTeamMembers"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	id, err := strconv.Atoi(ctx.Param("team_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error id"})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	members, err := h.usecase.GetTeamMembers(ct, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"err": "not found"})
+		return
+	}
+	if err != nil {
+		log.Info("failed to get", slog.Int("id", id), "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to get"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, members)
+}
+
+func (h *teamHandler) AddTeamMember(ctx *gin.Context) {
+	const op = "handler.AddTeamMember"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	id, err := strconv.Atoi(ctx.Param("team_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error id"})
+		return
+	}
+	realtorID, err := strconv.Atoi(ctx.Param("realtor_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error id"})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	err = h.usecase.AddTeamMember(ct, id, realtorID)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"err": "not found"})
+		return
+	}
+	if errors.Is(err, entity.ErrForbidden) {
+		ctx.JSON(http.StatusForbidden, gin.H{"err": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Info("failed to add", slog.Int("id", id), slog.Int("realtor_id", realtorID), "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to add"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"msg": "added"})
+}
+
+func (h *teamHandler) RemoveTeamMember(ctx *gin.Context) {
+	const op = "handler.RemoveTeamMember"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	id, err := strconv.Atoi(ctx.Param("team_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error id"})
+		return
+	}
+	realtorID, err := strconv.Atoi(ctx.Param("realtor_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error id"})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	err = h.usecase.RemoveTeamMember(ct, id, realtorID)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"err": "not found"})
+		return
+	}
+	if errors.Is(err, entity.ErrForbidden) {
+		ctx.JSON(http.StatusForbidden, gin.H{"err": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Info("failed to remove", slog.Int("id", id), slog.Int("realtor_id", realtorID), "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to remove"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"msg": "removed"})
+}
+
+func (h *teamHandler) GetTeamListings(ctx *gin.Context) {
+	const op = "handler.GetTeamListings"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	id, err := strconv.Atoi(ctx.Param("team_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error id"})
+		return
+	}
+
+	const page_size = 10
+	page, err := strconv.Atoi(ctx.DefaultQuery("page", "0"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error page"})
+		return
+	}
+
+	var filter entity.ApartmentFilter
+	if err = bindApartmentFilter(ctx, &filter); err != nil {
+		log.Info("bad filter", "err", err.Error())
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "invalid filter"})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	apartments, err := h.usecase.GetTeamListings(ct, id, filter, page, page_size)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"err": "not found"})
+		return
+	}
+	if err != nil {
+		log.Info("failed to get", slog.Int("id", id), "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to get"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, apartments)
+}
+
+func (h *teamHandler) GetTeamStats(ctx *gin.Context) {
+	const op = "handler.GetTeamStats"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	id, err := strconv.Atoi(ctx.Param("team_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error id"})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	stats, err := h.usecase.GetTeamStats(ct, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"err": "not found"})
+		return
+	}
+	if err != nil {
+		log.Info("failed to get", slog.Int("id", id), "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to get"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, stats)
+}
@@ -0,0 +1,222 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"gilab.com/estate-agency-api/internal/entity"
+	"gilab.com/estate-agency-api/pkg/patch"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	webhooksURL          = "/webhooks"
+	webhookURL           = "/webhooks/:webhook_id"
+	webhookDeliveriesURL = "/webhooks/:webhook_id/deliveries"
+)
+
+type webhookHandler struct {
+	usecase WebhookUsecase
+	logger  *slog.Logger
+}
+
+func NewWebhookHandler(usecase WebhookUsecase, logger *slog.Logger) *webhookHandler {
+	return &webhookHandler{usecase: usecase, logger: logger}
+}
+
+func (h *webhookHandler) Register(router *gin.Engine) {
+	router.GET(webhooksURL, h.GetWebhooks)
+	router.GET(webhookURL, h.GetWebhook)
+	router.POST(webhooksURL, h.CreateWebhook)
+	router.PATCH(webhookURL, h.UpdateWebhook)
+	router.DELETE(webhookURL, h.DeleteWebhook)
+	router.GET(webhookDeliveriesURL, h.GetWebhookDeliveries)
+}
+
+// redactSecret hides the signing secret, which is only returned when the webhook is created.
+func redactSecret(webhook *entity.Webhook) *entity.Webhook {
+	redacted := *webhook
+	redacted.Secret = ""
+	return &redacted
+}
+
+func (h *webhookHandler) GetWebhooks(ctx *gin.Context) {
+	const op = "handler.GetWebhooks"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	const page_size = 10
+	page, err := strconv.Atoi(ctx.DefaultQuery("page", "0"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error page"})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	webhooks, err := h.usecase.GetAllWebhooks(ct, page, page_size)
+	if err != nil {
+		log.Info("failed to get", "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to get"})
+		return
+	}
+
+	for i, webhook := range webhooks {
+		webhooks[i] = redactSecret(webhook)
+	}
+
+	ctx.JSON(http.StatusOK, webhooks)
+}
+
+func (h *webhookHandler) GetWebhook(ctx *gin.Context) {
+	const op = "handler.GetWebhook"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	id, err := strconv.Atoi(ctx.Param("webhook_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error id"})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	webhook, err := h.usecase.GetWebhookByID(ct, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"err": "not found"})
+		return
+	}
+	if err != nil {
+		log.Info("failed to get", slog.Int("id", id), "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to get"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, redactSecret(webhook))
+}
+
+func (h *webhookHandler) CreateWebhook(ctx *gin.Context) {
+	const op = "handler.CreateWebhook"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	webhook := entity.Webhook{Active: true}
+	if err := ctx.ShouldBindJSON(&webhook); err != nil {
+		log.Info("invalid request", "err", err.Error())
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": err.Error()})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	_, err := h.usecase.CreateWebhook(ct, &webhook)
+	if errors.Is(err, entity.ErrValidation) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Info("failed to create", "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to create"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, webhook)
+}
+
+func (h *webhookHandler) UpdateWebhook(ctx *gin.Context) {
+	const op = "handler.UpdateWebhook"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	id, err := strconv.Atoi(ctx.Param("webhook_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error id"})
+		return
+	}
+
+	doc, err := patchDocument(ctx, nil)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": err.Error()})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	webhook, err := h.usecase.UpdateWebhook(ct, id, doc)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"err": "not found"})
+		return
+	}
+	if errors.Is(err, entity.ErrValidation) || errors.Is(err, patch.ErrInvalidDocument) {
+		log.Info("bad patch", "err", err.Error())
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Info("failed to update", "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to update"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, redactSecret(webhook))
+}
+
+func (h *webhookHandler) DeleteWebhook(ctx *gin.Context) {
+	const op = "handler.DeleteWebhook"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	id, err := strconv.Atoi(ctx.Param("webhook_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error id"})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	err = h.usecase.DeleteWebhook(ct, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"err": "not found"})
+		return
+	}
+	if err != nil {
+		log.Info("failed to delete", slog.Int("id", id), "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to delete"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"msg": "deleted"})
+}
+
+func (h *webhookHandler) GetWebhookDeliveries(ctx *gin.Context) {
+	const op = "handler.GetWebhookDeliveries"
+
+	log := requestLogger(ctx, h.logger).With(slog.String("op", op))
+
+	id, err := strconv.Atoi(ctx.Param("webhook_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error id"})
+		return
+	}
+
+	const page_size = 50
+	page, err := strconv.Atoi(ctx.DefaultQuery("page", "0"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "error page"})
+		return
+	}
+
+	var filter entity.WebhookDeliveryFilter
+	if err = ctx.ShouldBindQuery(&filter); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"err": "invalid filter"})
+		return
+	}
+
+	ct := requestContext(ctx, h.logger)
+	deliveries, err := h.usecase.GetWebhookDeliveries(ct, id, filter, page, page_size)
+	if err != nil {
+		log.Info("failed to get", slog.Int("id", id), "err", err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"err": "failed to get"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, deliveries)
+}
@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"gilab.com/estate-agency-api/internal/entity"
+	"gilab.com/estate-agency-api/pkg/logging"
+	"gilab.com/estate-agency-api/pkg/reqctx"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	apiKeyScheme = "ApiKey "
+
+	// APIKeyKey holds the *entity.APIKey of a request authenticated by API key.
+	APIKeyKey = "api_key"
+)
+
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, token string) (key *entity.APIKey, err error)
+}
+
+// scopeRule grants the routes under prefix to keys with the read scope for GET and HEAD and the write scope otherwise.
+type scopeRule struct {
+	prefix string
+	read   string
+	write  string
+}
+
+// scopeRules lists everything API keys may reach; every other route, the admin ones included, needs a login.
+// Keys issued to a realtor don't have the write scopes of realtors and teams, see entity.AgencyScope.
+var scopeRules = []scopeRule{
+	{prefix: "/apartments", read: entity.ScopeApartmentsRead, write: entity.ScopeApartmentsWrite},
+	{prefix: "/realtors", read: entity.ScopeRealtorsRead, write: entity.ScopeRealtorsWrite},
+	{prefix: "/feeds", read: entity.ScopeFeedsRead},
+	{prefix: "/offices", read: entity.ScopeTeamsRead, write: entity.ScopeTeamsWrite},
+	{prefix: "/teams", read: entity.ScopeTeamsRead, write: entity.ScopeTeamsWrite},
+}
+
+func requiredScope(method string, route string) string {
+	for _, rule := range scopeRules {
+		if route != rule.prefix && !strings.HasPrefix(route, rule.prefix+"/") {
+			continue
+		}
+		if method == http.MethodGet || method == http.MethodHead {
+			return rule.read
+		}
+		return rule.write
+	}
+	return ""
+}
+
+// Auth accepts an "Authorization: ApiKey <key>" header, limited to the scopes of the key, and falls back to basic auth.
+// Requests made with a key act as "api_key:<id>" in logs and the audit log, and on behalf of the realtor the key is issued to.
+// The login admin is the administrator of the platform; no other login and no key may manage agencies.
+func Auth(accounts gin.Accounts, admin string, keys APIKeyAuthenticator, logger *slog.Logger) gin.HandlerFunc {
+	basic := BasicAuth(accounts)
+
+	return func(ctx *gin.Context) {
+		const op = "middleware.Auth"
+
+		token, ok := strings.CutPrefix(ctx.GetHeader("Authorization"), apiKeyScheme)
+		if !ok {
+			basic(ctx)
+			if !ctx.IsAborted() && ctx.GetString(gin.AuthUserKey) == admin {
+				ctx.Request = ctx.Request.WithContext(reqctx.WithPlatformAdmin(ctx.Request.Context()))
+			}
+			return
+		}
+
+		key, err := keys.AuthenticateAPIKey(ctx.Request.Context(), strings.TrimSpace(token))
+		if errors.Is(err, entity.ErrAPIKeyInvalid) {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"err": "invalid api key"})
+			return
+		}
+		if err != nil {
+			logging.FromContext(ctx.Request.Context(), logger).Error("failed to authenticate api key", slog.String("op", op), "err", err.Error())
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"err": "failed to authenticate"})
+			return
+		}
+
+		scope := requiredScope(ctx.Request.Method, ctx.FullPath())
+		if len(scope) == 0 || !key.HasScope(scope) {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"err": "api key is not allowed to access this route"})
+			return
+		}
+
+		ctx.Set(APIKeyKey, key)
+		ctx.Set(gin.AuthUserKey, "api_key:"+strconv.Itoa(key.ID))
+		if key.IDRealtor != nil {
+			ctx.Request = ctx.Request.WithContext(reqctx.WithRealtor(ctx.Request.Context(), *key.IDRealtor))
+		}
+
+		ctx.Next()
+	}
+}
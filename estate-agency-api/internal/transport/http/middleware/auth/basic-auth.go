@@ -2,8 +2,7 @@ package auth
 
 import "github.com/gin-gonic/gin"
 
-func BasicAuth(user string, password string) gin.HandlerFunc {
-	return gin.BasicAuth(gin.Accounts{
-		user: password,
-	})
+// BasicAuth accepts the logins of accounts, which maps users to their passwords.
+func BasicAuth(accounts gin.Accounts) gin.HandlerFunc {
+	return gin.BasicAuth(accounts)
 }
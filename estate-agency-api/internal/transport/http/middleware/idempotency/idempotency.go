@@ -0,0 +1,132 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"gilab.com/estate-agency-api/internal/entity"
+	"gilab.com/estate-agency-api/pkg/logging"
+	"gilab.com/estate-agency-api/pkg/reqctx"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	HeaderKey      = "Idempotency-Key"
+	HeaderReplayed = "Idempotent-Replayed"
+
+	maxKeyLength = 255
+)
+
+type Service interface {
+	Begin(ctx context.Context, key string, requestHash string) (record *entity.IdempotencyRecord, err error)
+	Complete(ctx context.Context, key string, statusCode int, contentType string, response []byte) error
+	Release(ctx context.Context, key string) error
+}
+
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency replays the stored response of a POST request retried with the same
+// Idempotency-Key header and rejects a key reused with a different body.
+// Keys are scoped by agency, user and route, so clients cannot collide with each other.
+func Idempotency(service Service, logger *slog.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		const op = "middleware.Idempotency"
+
+		key := ctx.GetHeader(HeaderKey)
+		if ctx.Request.Method != http.MethodPost || len(key) == 0 {
+			ctx.Next()
+			return
+		}
+
+		log := logging.FromContext(ctx.Request.Context(), logger).With(slog.String("op", op))
+
+		if len(key) > maxKeyLength {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"err": "idempotency key too long"})
+			return
+		}
+
+		body, err := io.ReadAll(ctx.Request.Body)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"err": "invalid request"})
+			return
+		}
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		agencyID, _ := reqctx.Tenant(ctx.Request.Context())
+		scopedKey := hash([]byte(strconv.Itoa(agencyID) + "\n" + ctx.GetString(gin.AuthUserKey) + "\n" + ctx.FullPath() + "\n" + key))
+		requestHash := hash(body)
+
+		// The key must be completed or released even when the client goes away, so the context keeps
+		// the trace, the request ID and the logger of the request but not its cancellation.
+		ct := context.WithoutCancel(ctx.Request.Context())
+		record, err := service.Begin(ct, scopedKey, requestHash)
+		switch {
+		case errors.Is(err, entity.ErrIdempotencyKeyMismatch), errors.Is(err, entity.ErrIdempotencyInProgress):
+			log.Info("idempotency conflict", "err", err.Error())
+			ctx.AbortWithStatusJSON(http.StatusConflict, gin.H{"err": err.Error()})
+			return
+		case err != nil:
+			log.Error("failed to check idempotency key", "err", err.Error())
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"err": "internal error"})
+			return
+		case record != nil:
+			log.Info("replay response")
+			ctx.Header(HeaderReplayed, "true")
+			ctx.Data(record.StatusCode, record.ContentType, record.Response)
+			ctx.Abort()
+			return
+		}
+
+		completed := false
+		defer func() {
+			if completed {
+				return
+			}
+			if err := service.Release(ct, scopedKey); err != nil {
+				log.Error("failed to release idempotency key", "err", err.Error())
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: ctx.Writer}
+		ctx.Writer = recorder
+
+		ctx.Next()
+
+		// Server errors are not stored so that a retry gets another chance to succeed.
+		if recorder.Status() >= http.StatusInternalServerError {
+			return
+		}
+
+		err = service.Complete(ct, scopedKey, recorder.Status(), recorder.Header().Get("Content-Type"), recorder.body.Bytes())
+		if err != nil {
+			log.Error("failed to store idempotent response", "err", err.Error())
+			return
+		}
+		completed = true
+	}
+}
+
+func hash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,73 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"gilab.com/estate-agency-api/pkg/logging"
+	"gilab.com/estate-agency-api/pkg/reqctx"
+	"github.com/gin-gonic/gin"
+)
+
+// unmatchedRoute is logged for requests that matched no route.
+const unmatchedRoute = "unmatched"
+
+// Logging stores a logger carrying the request ID, method and route in the request context and writes
+// one access log line per request with the status, latency and authenticated user.
+// It must run after the request ID middleware.
+func Logging(logger *slog.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+
+		route := ctx.FullPath()
+		if len(route) == 0 {
+			route = unmatchedRoute
+		}
+		log := logger.With(
+			slog.String("request_id", reqctx.RequestID(ctx.Request.Context())),
+			slog.String("method", ctx.Request.Method),
+			slog.String("route", route),
+		)
+		ctx.Request = ctx.Request.WithContext(logging.WithLogger(ctx.Request.Context(), log))
+
+		ctx.Next()
+
+		size := ctx.Writer.Size()
+		if size < 0 {
+			size = 0
+		}
+		attrs := []any{
+			slog.Int("status", ctx.Writer.Status()),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.Int("size", size),
+			slog.String("client_ip", ctx.ClientIP()),
+		}
+		if user := ctx.GetString(gin.AuthUserKey); len(user) != 0 {
+			attrs = append(attrs, slog.String("user", user))
+		}
+		if len(ctx.Errors) != 0 {
+			attrs = append(attrs, slog.String("err", ctx.Errors.String()))
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case ctx.Writer.Status() >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case ctx.Writer.Status() >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+		log.Log(ctx.Request.Context(), level, "request", attrs...)
+	}
+}
+
+// Recovery answers 500 on a panic and logs it with the request logger instead of gin's own format.
+func Recovery(logger *slog.Logger) gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(ctx *gin.Context, err any) {
+		logging.FromContext(ctx.Request.Context(), logger).Error("panic recovered", "err", fmt.Sprint(err), "stack", string(debug.Stack()))
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+	})
+}
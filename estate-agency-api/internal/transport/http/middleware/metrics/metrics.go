@@ -0,0 +1,29 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// unmatchedRoute labels requests that matched no route, so scanners can't blow up the label set.
+const unmatchedRoute = "unmatched"
+
+type Observer interface {
+	ObserveHTTP(method string, route string, status int, duration time.Duration)
+}
+
+// Metrics reports every request by its route template rather than its path.
+func Metrics(observer Observer) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+
+		ctx.Next()
+
+		route := ctx.FullPath()
+		if len(route) == 0 {
+			route = unmatchedRoute
+		}
+		observer.ObserveHTTP(ctx.Request.Method, route, ctx.Writer.Status(), time.Since(start))
+	}
+}
@@ -0,0 +1,147 @@
+package ratelimit
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"gilab.com/estate-agency-api/internal/entity"
+	"gilab.com/estate-agency-api/internal/transport/http/middleware/auth"
+	"gilab.com/estate-agency-api/pkg/logging"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	HeaderLimit      = "RateLimit-Limit"
+	HeaderRemaining  = "RateLimit-Remaining"
+	HeaderReset      = "RateLimit-Reset"
+	HeaderRetryAfter = "Retry-After"
+)
+
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit entity.RateLimit) (entity.RateLimitResult, error)
+}
+
+// Rule limits the requests to the routes it matches. Path is a route template such as
+// /apartments/:apartment_id, or a prefix of templates when it ends with *. An empty Method matches any method.
+type Rule struct {
+	Method string
+	Path   string
+	Limit  entity.RateLimit
+}
+
+func (r *Rule) matches(method string, route string) bool {
+	if len(r.Method) != 0 && r.Method != method {
+		return false
+	}
+	if prefix, ok := strings.CutSuffix(r.Path, "*"); ok {
+		return strings.HasPrefix(route, prefix)
+	}
+	return r.Path == route
+}
+
+type policy struct {
+	enabled bool
+	ip      entity.RateLimit
+	def     entity.RateLimit
+	rules   []Rule
+}
+
+// RateLimiter limits every IP before authentication, then applies the first rule matching the route, or the
+// default limit, to every client separately. The rules can be replaced while serving.
+type RateLimiter struct {
+	limiter Limiter
+	policy  atomic.Pointer[policy]
+	logger  *slog.Logger
+}
+
+func New(limiter Limiter, logger *slog.Logger) *RateLimiter {
+	rl := &RateLimiter{limiter: limiter, logger: logger}
+	rl.policy.Store(&policy{})
+	return rl
+}
+
+func (rl *RateLimiter) SetRules(enabled bool, ip entity.RateLimit, def entity.RateLimit, rules []Rule) {
+	rl.policy.Store(&policy{enabled: enabled, ip: ip, def: def, rules: rules})
+}
+
+// IPMiddleware limits the requests of every IP. It runs before authentication, so that floods and rejected
+// credentials are limited as well: the credentials of a request are not verified yet and can't be trusted to name it.
+func (rl *RateLimiter) IPMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		p := rl.policy.Load()
+		if !p.enabled || len(ctx.FullPath()) == 0 {
+			ctx.Next()
+			return
+		}
+
+		rl.limit(ctx, "ip:"+ctx.ClientIP(), p.ip)
+	}
+}
+
+// Middleware limits the requests of every client, the API key or the login authenticating them, so that the
+// clients sharing an IP don't share a bucket. It runs after authentication.
+func (rl *RateLimiter) Middleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		p := rl.policy.Load()
+		if !p.enabled || len(ctx.FullPath()) == 0 {
+			ctx.Next()
+			return
+		}
+
+		name, limit := "default", p.def
+		for i := range p.rules {
+			if p.rules[i].matches(ctx.Request.Method, ctx.FullPath()) {
+				name, limit = p.rules[i].Method+" "+p.rules[i].Path, p.rules[i].Limit
+				break
+			}
+		}
+
+		rl.limit(ctx, name+"|"+client(ctx), limit)
+	}
+}
+
+// client names the principal of an authenticated request, falling back to its IP.
+func client(ctx *gin.Context) string {
+	if key, ok := ctx.Get(auth.APIKeyKey); ok {
+		return "api_key:" + strconv.Itoa(key.(*entity.APIKey).ID)
+	}
+	if user := ctx.GetString(gin.AuthUserKey); len(user) != 0 {
+		return "user:" + user
+	}
+	return "ip:" + ctx.ClientIP()
+}
+
+// limit takes a token from the bucket of key, answering 429 with Retry-After once it is empty, and reports the
+// bucket in RateLimit-* headers. When the backend fails the request is let through.
+func (rl *RateLimiter) limit(ctx *gin.Context, key string, limit entity.RateLimit) {
+	const op = "middleware.RateLimit"
+
+	result, err := rl.limiter.Allow(ctx.Request.Context(), key, limit)
+	if err != nil {
+		logging.FromContext(ctx.Request.Context(), rl.logger).Warn("rate limiter failed", slog.String("op", op), "err", err.Error())
+		ctx.Next()
+		return
+	}
+
+	ctx.Header(HeaderLimit, strconv.Itoa(limit.Burst))
+	ctx.Header(HeaderRemaining, strconv.Itoa(result.Remaining))
+	ctx.Header(HeaderReset, seconds(result.ResetAfter))
+	if !result.Allowed {
+		ctx.Header(HeaderRetryAfter, seconds(result.RetryAfter))
+		ctx.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"err": "rate limit exceeded"})
+		return
+	}
+
+	ctx.Next()
+}
+
+// seconds rounds up, so a client waiting that long is never refused again.
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
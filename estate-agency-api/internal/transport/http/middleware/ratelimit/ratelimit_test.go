@@ -0,0 +1,133 @@
+package ratelimit
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"gilab.com/estate-agency-api/internal/entity"
+	"gilab.com/estate-agency-api/internal/transport/http/middleware/auth"
+	"github.com/gin-gonic/gin"
+)
+
+// countingLimiter allows burst requests per key and never refills.
+type countingLimiter struct {
+	mu    sync.Mutex
+	taken map[string]int
+}
+
+func (l *countingLimiter) Allow(ctx context.Context, key string, limit entity.RateLimit) (entity.RateLimitResult, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.taken[key]++
+	remaining := limit.Burst - l.taken[key]
+	if remaining < 0 {
+		return entity.RateLimitResult{}, nil
+	}
+	return entity.RateLimitResult{Allowed: true, Remaining: remaining}, nil
+}
+
+// request is sent from ip, authenticated by an API key when key is not zero, by user otherwise.
+type request struct {
+	ip   string
+	key  int
+	user string
+	path string
+}
+
+func TestRateLimiter(t *testing.T) {
+	tests := []struct {
+		name     string
+		requests []request
+		// want is the status of the last request.
+		want int
+	}{
+		{
+			name:     "API keys behind one IP",
+			requests: []request{{ip: "10.0.0.1", key: 1}, {ip: "10.0.0.1", key: 1}, {ip: "10.0.0.1", key: 2}},
+			want:     http.StatusOK,
+		},
+		{
+			name:     "API key from several IPs",
+			requests: []request{{ip: "10.0.0.1", key: 1}, {ip: "10.0.0.2", key: 1}, {ip: "10.0.0.3", key: 1}},
+			want:     http.StatusTooManyRequests,
+		},
+		{
+			name:     "logins behind one IP",
+			requests: []request{{ip: "10.0.0.1", user: "north"}, {ip: "10.0.0.1", user: "north"}, {ip: "10.0.0.1", user: "south"}},
+			want:     http.StatusOK,
+		},
+		{
+			name:     "login and API key of the same id",
+			requests: []request{{ip: "10.0.0.1", key: 1}, {ip: "10.0.0.1", key: 1}, {ip: "10.0.0.1", user: "1"}},
+			want:     http.StatusOK,
+		},
+		{
+			name:     "route rule",
+			requests: []request{{ip: "10.0.0.1", key: 1, path: "/exports"}, {ip: "10.0.0.1", key: 1, path: "/exports"}},
+			want:     http.StatusTooManyRequests,
+		},
+		{
+			name: "IP flooding",
+			requests: []request{
+				{ip: "10.0.0.1", key: 1}, {ip: "10.0.0.1", key: 2}, {ip: "10.0.0.1", key: 3},
+				{ip: "10.0.0.1", key: 4}, {ip: "10.0.0.1", key: 5},
+			},
+			want: http.StatusTooManyRequests,
+		},
+		{
+			name:     "unauthenticated",
+			requests: []request{{ip: "10.0.0.1"}, {ip: "10.0.0.1"}, {ip: "10.0.0.1"}},
+			want:     http.StatusTooManyRequests,
+		},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rl := New(&countingLimiter{taken: map[string]int{}}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+			rl.SetRules(true, entity.RateLimit{Burst: 4}, entity.RateLimit{Burst: 2}, []Rule{{Path: "/exports", Limit: entity.RateLimit{Burst: 1}}})
+
+			router := gin.New()
+			router.Use(rl.IPMiddleware())
+			// The test stands in for auth.Auth, naming the principal in headers.
+			router.Use(func(ctx *gin.Context) {
+				if id, err := strconv.Atoi(ctx.GetHeader("X-Key")); err == nil {
+					ctx.Set(auth.APIKeyKey, &entity.APIKey{ID: id})
+				} else if user := ctx.GetHeader("X-User"); len(user) != 0 {
+					ctx.Set(gin.AuthUserKey, user)
+				}
+			})
+			router.Use(rl.Middleware())
+			router.GET("/apartments", func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+			router.GET("/exports", func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+
+			var got int
+			for _, r := range tt.requests {
+				path := r.path
+				if len(path) == 0 {
+					path = "/apartments"
+				}
+				req := httptest.NewRequest(http.MethodGet, path, nil)
+				req.RemoteAddr = r.ip + ":1234"
+				if r.key != 0 {
+					req.Header.Set("X-Key", strconv.Itoa(r.key))
+				}
+				req.Header.Set("X-User", r.user)
+
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				got = w.Code
+			}
+			if got != tt.want {
+				t.Errorf("status = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package requestid
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"gilab.com/estate-agency-api/pkg/reqctx"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	Header = "X-Request-ID"
+
+	maxLength = 64
+)
+
+// RequestID keeps the caller's request ID when it is a short token and generates one otherwise.
+// The ID is echoed in the response and stored in the request context for logs and the audit log.
+func RequestID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id := ctx.GetHeader(Header)
+		if !valid(id) {
+			id = generate()
+		}
+
+		ctx.Header(Header, id)
+		ctx.Request = ctx.Request.WithContext(reqctx.WithRequestID(ctx.Request.Context(), id))
+
+		ctx.Next()
+	}
+}
+
+func valid(id string) bool {
+	if len(id) == 0 || len(id) > maxLength {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+func generate() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,89 @@
+package tenant
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+
+	"gilab.com/estate-agency-api/internal/entity"
+	"gilab.com/estate-agency-api/internal/transport/http/middleware/auth"
+	"gilab.com/estate-agency-api/pkg/logging"
+	"gilab.com/estate-agency-api/pkg/reqctx"
+	"github.com/gin-gonic/gin"
+)
+
+type Resolver interface {
+	ResolveAgency(ctx context.Context, slug string) (id int, err error)
+}
+
+// Tenant sets the agency of the request in its context, every storage query is then scoped to it.
+// A request belongs to the agency its principal is bound to: a request authenticated with an API key to the
+// agency of the key, a login to the agency whose slug logins maps it to, or to the fallback agency when
+// logins does not name one. A subdomain of domain naming another agency is rejected. It must run after
+// authentication.
+func Tenant(resolver Resolver, domain string, fallback string, logins map[string]string, logger *slog.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		const op = "middleware.Tenant"
+
+		log := logging.FromContext(ctx.Request.Context(), logger).With(slog.String("op", op))
+
+		var agencyID int
+		if value, ok := ctx.Get(auth.APIKeyKey); ok {
+			agencyID = value.(*entity.APIKey).AgencyID
+		} else {
+			agency, ok := logins[ctx.GetString(gin.AuthUserKey)]
+			if !ok {
+				agency = fallback
+			}
+			id, err := resolver.ResolveAgency(ctx.Request.Context(), agency)
+			if errors.Is(err, sql.ErrNoRows) {
+				ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"err": "unknown agency"})
+				return
+			}
+			if err != nil {
+				log.Error("failed to resolve agency", slog.String("slug", agency), "err", err.Error())
+				ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"err": "internal error"})
+				return
+			}
+			agencyID = id
+		}
+
+		if slug := subdomain(ctx.Request.Host, domain); len(slug) != 0 {
+			id, err := resolver.ResolveAgency(ctx.Request.Context(), slug)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				log.Error("failed to resolve agency", slog.String("slug", slug), "err", err.Error())
+				ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"err": "internal error"})
+				return
+			}
+			if id != agencyID {
+				ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"err": "credentials belong to another agency"})
+				return
+			}
+		}
+
+		ct := reqctx.WithTenant(ctx.Request.Context(), agencyID)
+		ct = logging.WithLogger(ct, logging.FromContext(ct, logger).With(slog.Int("agency_id", agencyID)))
+		ctx.Request = ctx.Request.WithContext(ct)
+
+		ctx.Next()
+	}
+}
+
+// subdomain returns the first label of host when host is a direct subdomain of domain.
+func subdomain(host string, domain string) string {
+	if len(domain) == 0 {
+		return ""
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	label, ok := strings.CutSuffix(strings.ToLower(host), "."+strings.ToLower(domain))
+	if !ok || len(label) == 0 || strings.Contains(label, ".") {
+		return ""
+	}
+	return label
+}
@@ -0,0 +1,61 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Job is a unit of background work run on every tick of a worker.
+type Job func(ctx context.Context) error
+
+type worker struct {
+	name     string
+	interval time.Duration
+	job      Job
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	logger *slog.Logger
+}
+
+func New(name string, interval time.Duration, job Job, logger *slog.Logger) *worker {
+	return &worker{name: name, interval: interval, job: job, logger: logger.With(slog.String("worker", name))}
+}
+
+// Start runs the job immediately and then once per interval until Stop is called.
+func (w *worker) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for {
+			if err := w.job(ctx); err != nil {
+				w.logger.Error("job failed", "err", err.Error())
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop cancels the running job and waits for the worker to exit.
+func (w *worker) Stop() {
+	if w.cancel == nil {
+		return
+	}
+	w.cancel()
+	w.wg.Wait()
+}
@@ -0,0 +1,237 @@
+// Package address parses free-text Russian addresses written in Cyrillic or in Latin transliteration,
+// such as "г. Москва, ул. Ленина, д. 5, корп. 2" or "Lenina st, 5k2, Moscow".
+package address
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Street types, the canonical values of Components.StreetType.
+const (
+	TypeStreet     = "street"
+	TypeAvenue     = "avenue"
+	TypeLane       = "lane"
+	TypeHighway    = "highway"
+	TypeBoulevard  = "boulevard"
+	TypeEmbankment = "embankment"
+	TypeSquare     = "square"
+	TypePassage    = "passage"
+	TypeDeadEnd    = "dead_end"
+	TypeAlley      = "alley"
+)
+
+// Components are the parts of an address. Missing parts are empty.
+type Components struct {
+	// Street is the street as written, with its type word; StreetName is the street without it.
+	Street     string
+	StreetName string
+	StreetType string
+	House      string
+	Building   string
+	City       string
+}
+
+var streetTypes = keyed(map[string][]string{
+	TypeStreet:     {"ул", "улица", "ulitsa", "ul", "st", "street"},
+	TypeAvenue:     {"пр", "пр-т", "просп", "проспект", "prospekt", "pr-t", "prosp", "ave", "av", "avenue"},
+	TypeLane:       {"пер", "переулок", "pereulok", "per", "lane", "ln"},
+	TypeHighway:    {"ш", "шоссе", "shosse", "sh", "highway", "hwy"},
+	TypeBoulevard:  {"б-р", "бульвар", "bulvar", "bul", "blvd", "boulevard"},
+	TypeEmbankment: {"наб", "набережная", "naberezhnaya", "nab", "embankment", "emb"},
+	TypeSquare:     {"пл", "площадь", "ploshchad", "pl", "square", "sq"},
+	TypePassage:    {"проезд", "proezd", "passage"},
+	TypeDeadEnd:    {"туп", "тупик", "tupik"},
+	TypeAlley:      {"аллея", "alleya", "alley"},
+})
+
+var (
+	cityMarkers     = keySet("г", "гор", "город", "gorod", "g", "city", "пгт", "пос", "поселок", "посёлок", "pos", "дер", "деревня", "village", "town")
+	houseMarkers    = keySet("д", "дом", "dom", "d", "house", "h", "no", "nr")
+	buildingMarkers = keySet("к", "корп", "корпус", "korp", "korpus", "k", "стр", "строение", "str", "stroenie", "с", "building", "bldg", "bld", "block")
+	regionMarkers   = keySet("обл", "область", "oblast", "region", "край", "krai", "р-н", "район", "raion", "district", "респ", "республика", "republic")
+	countries       = keySet("россия", "russia", "рф", "russian federation")
+)
+
+var (
+	// houseRe matches house numbers like 5, 5а, 5/1 or 5-7, optionally followed by a building as in 5к2 or 5с1.
+	houseRe    = regexp.MustCompile(`^(\d+\pL?(?:[/-]\d+\pL?)?)(?:(?:к|корп|k|korp|стр|с|str|s)\.?(\d+\pL?))?$`)
+	buildingRe = regexp.MustCompile(`^(?:\d+\pL?|\pL)$`)
+	postcodeRe = regexp.MustCompile(`^\d{6}$`)
+	// splitRe separates the words glued by a dot or a number sign, as in "ул.Ленина", "д.5" or "№5".
+	splitRe = regexp.MustCompile(`([.№])(\S)`)
+)
+
+// Parse breaks a free-text address into its components. Parts of the address are separated by commas; within a
+// part the street type, house and building may come before or after the street name. A part without any of
+// those markers is the street if none was found otherwise, and the city after that. Postcodes, regions and the
+// country are skipped.
+func Parse(s string) Components {
+	var (
+		c          Components
+		candidates []string
+	)
+
+	for _, part := range strings.Split(splitRe.ReplaceAllString(s, "$1 $2"), ",") {
+		tokens := strings.Fields(strings.ReplaceAll(part, "№", " "))
+		if len(tokens) == 0 || countries[Key(strings.Join(tokens, " "))] {
+			continue
+		}
+
+		var (
+			words, street, city []string
+			streetType          string
+			inCity, region      bool
+			house               bool
+		)
+		for i := 0; i < len(tokens); i++ {
+			token, key := tokens[i], Key(tokens[i])
+			lower := strings.ToLower(strings.TrimSuffix(token, "."))
+			next := ""
+			if i+1 < len(tokens) {
+				next = strings.ToLower(strings.TrimSuffix(tokens[i+1], "."))
+			}
+
+			switch {
+			case regionMarkers[key]:
+				region = true
+			// The city marker comes before the city as in "г. Москва" or after it as in "Москва г.".
+			case cityMarkers[key] && len(tokens) > 1:
+				if len(words) != 0 && len(streetType) == 0 {
+					city, words, street = words, nil, nil
+				} else {
+					inCity = true
+				}
+			case houseMarkers[key] && houseRe.MatchString(next):
+				c.House, c.Building = parseHouse(next, c.Building)
+				house, inCity = true, false
+				i++
+			case buildingMarkers[key] && buildingRe.MatchString(next):
+				c.Building = next
+				i++
+			case len(streetType) == 0 && len(streetTypes[key]) != 0:
+				streetType = streetTypes[key]
+				street = append(street, token)
+				inCity = false
+			case postcodeRe.MatchString(lower):
+			// A number right after the street type and followed by a word belongs to the name, as in "ул. 8 Марта".
+			case houseRe.MatchString(lower) && len(c.House) == 0 && !(len(streetType) != 0 && len(words) == 0 && isWord(next)):
+				c.House, c.Building = parseHouse(lower, c.Building)
+				house, inCity = true, false
+			case inCity:
+				city = append(city, token)
+			default:
+				words = append(words, token)
+				street = append(street, token)
+			}
+		}
+
+		if len(city) != 0 {
+			c.City = strings.Join(city, " ")
+		}
+
+		name := strings.Join(words, " ")
+		switch {
+		case region || len(words) == 0:
+		case len(c.StreetName) == 0 && (len(streetType) != 0 || house):
+			c.Street, c.StreetName, c.StreetType = strings.Join(street, " "), name, streetType
+		default:
+			candidates = append(candidates, name)
+		}
+	}
+
+	if len(c.StreetName) == 0 && len(candidates) != 0 {
+		c.Street, c.StreetName = candidates[0], candidates[0]
+		candidates = candidates[1:]
+	}
+	if len(c.City) == 0 && len(candidates) != 0 {
+		c.City = candidates[len(candidates)-1]
+	}
+
+	return c
+}
+
+// CityName strips the markers like "г." from a city name.
+func CityName(s string) string {
+	var words []string
+	for _, token := range strings.Fields(splitRe.ReplaceAllString(s, "$1 $2")) {
+		if !cityMarkers[Key(token)] {
+			words = append(words, token)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// parseHouse splits a house number with an attached building. A building given separately is kept.
+func parseHouse(s string, building string) (string, string) {
+	m := houseRe.FindStringSubmatch(s)
+	if len(m[2]) != 0 {
+		building = m[2]
+	}
+	return m[1], building
+}
+
+func isWord(s string) bool {
+	for _, r := range s {
+		if !unicode.IsLetter(r) {
+			return false
+		}
+	}
+	return len(s) != 0 && !houseMarkers[Key(s)] && !buildingMarkers[Key(s)]
+}
+
+var translit = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh", 'з': "z", 'и': "i",
+	'й': "i", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t",
+	'у': "u", 'ф': "f", 'х': "h", 'ц': "c", 'ч': "ch", 'ш': "sh", 'щ': "sh", 'ъ': "", 'ы': "i", 'ь': "",
+	'э': "e", 'ю': "iu", 'я': "ia",
+}
+
+// latin folds the spellings transliteration schemes disagree on.
+var latin = strings.NewReplacer(
+	"shch", "sh", "sch", "sh", "kh", "h", "ts", "c", "tz", "c", "yo", "e", "jo", "e", "ye", "e",
+	"ph", "f", "w", "v", "x", "ks", "q", "k", "j", "i", "y", "i",
+)
+
+// Key folds a name for matching: case, punctuation and spaces are dropped, Cyrillic is transliterated, common
+// variants of transliteration are folded and doubled letters are collapsed. "Ленинский", "Leninskiy" and
+// "Leninsky" share a key.
+func Key(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if t, ok := translit[r]; ok {
+			b.WriteString(t)
+		} else if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(r)
+		}
+	}
+
+	folded := latin.Replace(b.String())
+	key := make([]byte, 0, len(folded))
+	for i := 0; i < len(folded); i++ {
+		if i > 0 && folded[i] == folded[i-1] && !unicode.IsDigit(rune(folded[i])) {
+			continue
+		}
+		key = append(key, folded[i])
+	}
+	return string(key)
+}
+
+func keySet(words ...string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, word := range words {
+		set[Key(word)] = true
+	}
+	return set
+}
+
+func keyed(types map[string][]string) map[string]string {
+	m := make(map[string]string)
+	for t, words := range types {
+		for _, word := range words {
+			m[Key(word)] = t
+		}
+	}
+	return m
+}
@@ -0,0 +1,127 @@
+package address
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want Components
+	}{
+		{
+			name: "cyrillic with markers",
+			in:   "г. Москва, ул. Ленина, д. 5, корп. 2",
+			want: Components{Street: "ул. Ленина", StreetName: "Ленина", StreetType: TypeStreet, House: "5", Building: "2", City: "Москва"},
+		},
+		{
+			name: "latin with glued building",
+			in:   "Lenina st, 5k2, Moscow",
+			want: Components{Street: "Lenina st", StreetName: "Lenina", StreetType: TypeStreet, House: "5", Building: "2", City: "Moscow"},
+		},
+		{
+			name: "markers glued by dots",
+			in:   "г.Москва, ул.Ленина, д.5",
+			want: Components{Street: "ул. Ленина", StreetName: "Ленина", StreetType: TypeStreet, House: "5", City: "Москва"},
+		},
+		{
+			name: "city marker after the city",
+			in:   "Москва г., проспект Мира, 10",
+			want: Components{Street: "проспект Мира", StreetName: "Мира", StreetType: TypeAvenue, House: "10", City: "Москва"},
+		},
+		{
+			name: "house in the street part",
+			in:   "Тверская ул., 7с1, Москва",
+			want: Components{Street: "Тверская ул.", StreetName: "Тверская", StreetType: TypeStreet, House: "7", Building: "1", City: "Москва"},
+		},
+		{
+			name: "number in the street name",
+			in:   "ул. 8 Марта, д. 3",
+			want: Components{Street: "ул. 8 Марта", StreetName: "8 Марта", StreetType: TypeStreet, House: "3"},
+		},
+		{
+			name: "postcode, region and country are skipped",
+			in:   "123456, Россия, Московская обл., г. Химки, ул. Победы, д. 2",
+			want: Components{Street: "ул. Победы", StreetName: "Победы", StreetType: TypeStreet, House: "2", City: "Химки"},
+		},
+		{
+			name: "house with a slash",
+			in:   "Невский пр-т, 28/2, Санкт-Петербург",
+			want: Components{Street: "Невский пр-т", StreetName: "Невский", StreetType: TypeAvenue, House: "28/2", City: "Санкт-Петербург"},
+		},
+		{
+			name: "house with a letter",
+			in:   "наб. реки Фонтанки, 15а",
+			want: Components{Street: "наб. реки Фонтанки", StreetName: "реки Фонтанки", StreetType: TypeEmbankment, House: "15а"},
+		},
+		{
+			name: "number sign",
+			in:   "Arbat st №12, Moscow",
+			want: Components{Street: "Arbat st", StreetName: "Arbat", StreetType: TypeStreet, House: "12", City: "Moscow"},
+		},
+		{
+			name: "parts without markers",
+			in:   "Арбат, Москва",
+			want: Components{Street: "Арбат", StreetName: "Арбат", City: "Москва"},
+		},
+		{
+			name: "empty",
+			in:   "",
+			want: Components{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Parse(tt.in); got != tt.want {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCityName(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "г. Москва", want: "Москва"},
+		{in: "г.Москва", want: "Москва"},
+		{in: "Москва", want: "Москва"},
+		{in: "city Kazan", want: "Kazan"},
+		{in: "пгт Янтарный", want: "Янтарный"},
+		{in: "Нижний Новгород", want: "Нижний Новгород"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := CityName(tt.in); got != tt.want {
+				t.Errorf("CityName(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		a, b string
+		same bool
+	}{
+		{a: "Ленинский", b: "Leninskiy", same: true},
+		{a: "Ленинский", b: "Leninsky", same: true},
+		{a: "Щукинская", b: "Shchukinskaya", same: true},
+		{a: "Щукинская", b: "Schukinskaja", same: true},
+		{a: "Хорошёво", b: "Khoroshevo", same: true},
+		{a: "Цветной", b: "Tsvetnoy", same: true},
+		{a: "Ул. Ленина", b: "ул ленина", same: true},
+		{a: "Ленина", b: "Ленинская", same: false},
+		{a: "11", b: "1", same: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" "+tt.b, func(t *testing.T) {
+			if got := Key(tt.a) == Key(tt.b); got != tt.same {
+				t.Errorf("Key(%q) = %q, Key(%q) = %q, same = %v, want %v", tt.a, Key(tt.a), tt.b, Key(tt.b), got, tt.same)
+			}
+		})
+	}
+}
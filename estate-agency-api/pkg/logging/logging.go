@@ -1,50 +1,55 @@
+// Package logging builds the service's slog logger and carries a request-scoped logger through a context.
 package logging
 
 import (
+	"context"
 	"fmt"
-	"log"
-	"os"
-	"path"
-	"runtime"
-	"sync"
+	"io"
+	"log/slog"
+	"strings"
+)
 
-	"github.com/sirupsen/logrus"
+const (
+	FormatText = "text"
+	FormatJSON = "json"
 )
 
-type Logger struct {
-	*logrus.Entry
+type key int
+
+const loggerKey key = iota
+
+// ParseLevel parses debug, info, warn or error.
+func ParseLevel(level string) (slog.Level, error) {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return l, fmt.Errorf("logging: %w", err)
+	}
+	return l, nil
+}
+
+// New returns a logger writing to w in the given format. Pass a *slog.LevelVar as level to change it
+// at runtime. Secrets are redacted, see Redact.
+func New(w io.Writer, level slog.Leveler, format string) (*slog.Logger, error) {
+	opts := &slog.HandlerOptions{Level: level, ReplaceAttr: Redact}
+
+	switch strings.ToLower(format) {
+	case FormatText:
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	case FormatJSON:
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	default:
+		return nil, fmt.Errorf("logging: unknown format %q", format)
+	}
 }
 
-func (s *Logger) ExtraFields(fields map[string]interface{}) *Logger {
-	return &Logger{s.WithFields(fields)}
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, logger)
 }
 
-var instance Logger
-var once sync.Once
-
-func GetLogger(level string) Logger {
-	once.Do(func() {
-		logrusLevel, err := logrus.ParseLevel(level)
-		if err != nil {
-			log.Fatalln(err)
-		}
-
-		l := logrus.New()
-		l.SetReportCaller(true)
-		l.Formatter = &logrus.TextFormatter{
-			CallerPrettyfier: func(f *runtime.Frame) (string, string) {
-				filename := path.Base(f.File)
-				return fmt.Sprintf("%s:%d", filename, f.Line), fmt.Sprintf("%s()", f.Function)
-			},
-			DisableColors: false,
-			FullTimestamp: true,
-		}
-
-		l.SetOutput(os.Stdout)
-		l.SetLevel(logrusLevel)
-
-		instance = Logger{logrus.NewEntry(l)}
-	})
-
-	return instance
+// FromContext returns the logger stored in the context, or fallback when there is none.
+func FromContext(ctx context.Context, fallback *slog.Logger) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey).(*slog.Logger); ok {
+		return logger
+	}
+	return fallback
 }
@@ -0,0 +1,52 @@
+package logging
+
+import (
+	"log/slog"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// secretKeys are attribute keys, matched case-insensitively as substrings, whose values are never logged.
+var secretKeys = []string{"password", "secret", "token", "authorization", "api_key", "apikey"}
+
+// Redact is a slog ReplaceAttr function. It hides secrets and masks phone numbers
+// down to their last two digits, so support can still tell numbers apart.
+func Redact(groups []string, a slog.Attr) slog.Attr {
+	key := strings.ToLower(a.Key)
+
+	for _, secret := range secretKeys {
+		if strings.Contains(key, secret) {
+			return slog.String(a.Key, redacted)
+		}
+	}
+
+	if strings.Contains(key, "phone") && a.Value.Kind() == slog.KindString {
+		return slog.String(a.Key, MaskPhone(a.Value.String()))
+	}
+
+	return a
+}
+
+// MaskPhone replaces every digit but the last two with '*'.
+func MaskPhone(phone string) string {
+	digits := 0
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			digits++
+		}
+	}
+
+	var b strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			digits--
+			if digits >= 2 {
+				r = '*'
+			}
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
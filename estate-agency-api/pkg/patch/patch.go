@@ -0,0 +1,63 @@
+package patch
+
+import (
+	"errors"
+	"fmt"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+)
+
+const (
+	ContentTypeMergePatch = "application/merge-patch+json"
+	ContentTypeJSONPatch  = "application/json-patch+json"
+)
+
+var ErrInvalidDocument = errors.New("invalid patch document")
+
+type Type int
+
+const (
+	// MergePatch is a RFC 7396 JSON Merge Patch document.
+	MergePatch Type = iota
+	// JSONPatch is a RFC 6902 JSON Patch document.
+	JSONPatch
+)
+
+// Document is a partial update of a resource. Unlike a bound struct it keeps track of
+// which fields are present, so zero values and explicit nulls can be applied.
+type Document struct {
+	Type Type
+	Data []byte
+}
+
+func NewMergePatch(data []byte) Document {
+	return Document{Type: MergePatch, Data: data}
+}
+
+func NewJSONPatch(data []byte) Document {
+	return Document{Type: JSONPatch, Data: data}
+}
+
+// Apply patches the JSON representation of a resource and returns the merged result.
+func (d Document) Apply(original []byte) ([]byte, error) {
+	switch d.Type {
+	case MergePatch:
+		patched, err := jsonpatch.MergePatch(original, d.Data)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidDocument, err)
+		}
+		return patched, nil
+	case JSONPatch:
+		p, err := jsonpatch.DecodePatch(d.Data)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidDocument, err)
+		}
+		patched, err := p.Apply(original)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidDocument, err)
+		}
+		return patched, nil
+	}
+
+	return nil, fmt.Errorf("%w: unknown type %d", ErrInvalidDocument, d.Type)
+}
@@ -0,0 +1,65 @@
+// Package reqctx carries request-scoped metadata such as the acting user, the agency and the request ID through a context.
+package reqctx
+
+import "context"
+
+type key int
+
+const (
+	actorKey key = iota
+	requestIDKey
+	tenantKey
+	realtorKey
+	platformAdminKey
+)
+
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey, actor)
+}
+
+// Actor returns the user the request is performed on behalf of, or an empty string.
+func Actor(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey).(string)
+	return actor
+}
+
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestID returns the ID of the request being served, or an empty string.
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+func WithTenant(ctx context.Context, agencyID int) context.Context {
+	return context.WithValue(ctx, tenantKey, agencyID)
+}
+
+// Tenant returns the ID of the agency the request is scoped to.
+func Tenant(ctx context.Context) (agencyID int, ok bool) {
+	agencyID, ok = ctx.Value(tenantKey).(int)
+	return agencyID, ok
+}
+
+func WithRealtor(ctx context.Context, realtorID int) context.Context {
+	return context.WithValue(ctx, realtorKey, realtorID)
+}
+
+// Realtor returns the ID of the realtor the request is performed on behalf of, if it is limited to one.
+func Realtor(ctx context.Context) (realtorID int, ok bool) {
+	realtorID, ok = ctx.Value(realtorKey).(int)
+	return realtorID, ok
+}
+
+// WithPlatformAdmin marks the request as made by the administrator of the platform, who manages the agencies.
+func WithPlatformAdmin(ctx context.Context) context.Context {
+	return context.WithValue(ctx, platformAdminKey, true)
+}
+
+// PlatformAdmin reports whether the request is made by the administrator of the platform.
+func PlatformAdmin(ctx context.Context) bool {
+	admin, _ := ctx.Value(platformAdminKey).(bool)
+	return admin
+}
@@ -0,0 +1,109 @@
+package webhook
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestVerify(t *testing.T) {
+	const secret = "s3cret"
+	body := []byte(`{"id":1,"type":"ApartmentCreated"}`)
+	now := time.Now().Unix()
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp string
+		signature string
+		body      []byte
+		tolerance time.Duration
+		want      error
+	}{
+		{name: "valid", secret: secret, timestamp: strconv.FormatInt(now, 10), signature: Sign(secret, now, body), body: body, tolerance: 5 * time.Minute},
+		{name: "wrong secret", secret: "other", timestamp: strconv.FormatInt(now, 10), signature: Sign(secret, now, body), body: body, tolerance: 5 * time.Minute, want: ErrInvalidSignature},
+		{name: "tampered body", secret: secret, timestamp: strconv.FormatInt(now, 10), signature: Sign(secret, now, body), body: []byte(`{"id":2}`), tolerance: 5 * time.Minute, want: ErrInvalidSignature},
+		{name: "replayed with another timestamp", secret: secret, timestamp: strconv.FormatInt(now+1, 10), signature: Sign(secret, now, body), body: body, tolerance: 5 * time.Minute, want: ErrInvalidSignature},
+		{name: "signature without prefix", secret: secret, timestamp: strconv.FormatInt(now, 10), signature: Sign(secret, now, body)[len(signaturePrefix):], body: body, tolerance: 5 * time.Minute, want: ErrInvalidSignature},
+		{name: "empty signature", secret: secret, timestamp: strconv.FormatInt(now, 10), body: body, tolerance: 5 * time.Minute, want: ErrInvalidSignature},
+		{name: "malformed timestamp", secret: secret, timestamp: "yesterday", signature: Sign(secret, now, body), body: body, tolerance: 5 * time.Minute, want: ErrInvalidSignature},
+		{name: "expired", secret: secret, timestamp: strconv.FormatInt(now-600, 10), signature: Sign(secret, now-600, body), body: body, tolerance: 5 * time.Minute, want: ErrExpiredTimestamp},
+		{name: "from the future", secret: secret, timestamp: strconv.FormatInt(now+600, 10), signature: Sign(secret, now+600, body), body: body, tolerance: 5 * time.Minute, want: ErrExpiredTimestamp},
+		{name: "old without tolerance", secret: secret, timestamp: strconv.FormatInt(now-600, 10), signature: Sign(secret, now-600, body), body: body},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Verify(tt.secret, tt.timestamp, tt.signature, tt.body, tt.tolerance); !errors.Is(err, tt.want) {
+				t.Errorf("Verify() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+// TestVerifyReceiver posts signed webhooks to a receiver that verifies them the way subscribers are told to.
+func TestVerifyReceiver(t *testing.T) {
+	const secret = "s3cret"
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if err = Verify(secret, r.Header.Get(TimestampHeader), r.Header.Get(SignatureHeader), body, 5*time.Minute); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	body := []byte(`{"id":1,"type":"ApartmentCreated"}`)
+	tests := []struct {
+		name   string
+		secret string
+		// sent replaces the body after signing when set.
+		sent []byte
+		age  time.Duration
+		want int
+	}{
+		{name: "signed", secret: secret, want: http.StatusNoContent},
+		{name: "signed with another secret", secret: "other", want: http.StatusUnauthorized},
+		{name: "body changed on the way", secret: secret, sent: []byte(`{"id":2,"type":"ApartmentCreated"}`), want: http.StatusUnauthorized},
+		{name: "replayed later", secret: secret, age: time.Hour, want: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timestamp := time.Now().Add(-tt.age).Unix()
+			signature := Sign(tt.secret, timestamp, body)
+
+			sent := body
+			if tt.sent != nil {
+				sent = tt.sent
+			}
+			req, err := http.NewRequest(http.MethodPost, receiver.URL, bytes.NewReader(sent))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+			req.Header.Set(SignatureHeader, signature)
+
+			resp, err := receiver.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.want {
+				t.Errorf("receiver answered %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
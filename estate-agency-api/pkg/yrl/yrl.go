@@ -0,0 +1,111 @@
+package yrl
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+const Namespace = "http://webmaster.yandex.ru/schemas/feed/realty/2010-06"
+
+const (
+	TypeSale = "продажа"
+	TypeRent = "аренда"
+
+	PropertyTypeLiving = "жилая"
+
+	CategoryFlat = "квартира"
+
+	AgentCategoryAgency = "агентство"
+
+	CurrencyRUB = "RUB"
+
+	UnitSquareMeter = "кв. м"
+
+	PeriodMonth = "месяц"
+)
+
+// Feed is the root element of a Yandex Realty (YRL) feed.
+type Feed struct {
+	XMLName        xml.Name `xml:"realty-feed"`
+	Xmlns          string   `xml:"xmlns,attr"`
+	GenerationDate string   `xml:"generation-date"`
+	Offers         []Offer  `xml:"offer"`
+}
+
+type Offer struct {
+	InternalID     string     `xml:"internal-id,attr"`
+	Type           string     `xml:"type"`
+	PropertyType   string     `xml:"property-type"`
+	Category       string     `xml:"category"`
+	CreationDate   string     `xml:"creation-date"`
+	LastUpdateDate string     `xml:"last-update-date,omitempty"`
+	Location       Location   `xml:"location"`
+	SalesAgent     SalesAgent `xml:"sales-agent"`
+	Price          Price      `xml:"price"`
+	Images         []string   `xml:"image,omitempty"`
+	Area           Area       `xml:"area"`
+	Rooms          int        `xml:"rooms,omitempty"`
+	Description    string     `xml:"description,omitempty"`
+
+	// Rent offers only.
+	RentPledge        *Bool `xml:"rent-pledge,omitempty"`
+	UtilitiesIncluded *Bool `xml:"utilities-included,omitempty"`
+	WithPets          *Bool `xml:"with-pets,omitempty"`
+}
+
+// Bool is a yes/no flag written as the feed schema expects.
+type Bool bool
+
+func NewBool(b bool) *Bool {
+	v := Bool(b)
+	return &v
+}
+
+func (b Bool) MarshalText() ([]byte, error) {
+	if b {
+		return []byte("да"), nil
+	}
+	return []byte("нет"), nil
+}
+
+type Location struct {
+	Country      string `xml:"country"`
+	LocalityName string `xml:"locality-name,omitempty"`
+	Address      string `xml:"address,omitempty"`
+}
+
+type SalesAgent struct {
+	Name     string `xml:"name,omitempty"`
+	Phone    string `xml:"phone"`
+	Email    string `xml:"email,omitempty"`
+	Category string `xml:"category"`
+}
+
+type Price struct {
+	Value    int    `xml:"value"`
+	Currency string `xml:"currency"`
+	Period   string `xml:"period,omitempty"`
+}
+
+type Area struct {
+	Value int    `xml:"value"`
+	Unit  string `xml:"unit"`
+}
+
+func NewFeed(generated time.Time, offers []Offer) *Feed {
+	return &Feed{Xmlns: Namespace, GenerationDate: FormatTime(generated), Offers: offers}
+}
+
+// FormatTime formats t as the ISO 8601 date-time the feed schema expects.
+func FormatTime(t time.Time) string {
+	return t.Format(time.RFC3339)
+}
+
+func Marshal(feed *Feed) ([]byte, error) {
+	body, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), body...), nil
+}
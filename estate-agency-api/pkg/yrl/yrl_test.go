@@ -0,0 +1,348 @@
+package yrl
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// element is a node of a parsed feed.
+type element struct {
+	name     xml.Name
+	attrs    map[string]string
+	text     string
+	children []*element
+}
+
+func (e *element) child(name string) *element {
+	for _, c := range e.children {
+		if c.name.Local == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func parse(data []byte) (*element, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+
+	var stack []*element
+	var root *element
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return root, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			e := &element{name: t.Name, attrs: make(map[string]string)}
+			for _, attr := range t.Attr {
+				e.attrs[attr.Name.Local] = attr.Value
+			}
+			if len(stack) == 0 {
+				root = e
+			} else {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, e)
+			}
+			stack = append(stack, e)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) != 0 {
+				stack[len(stack)-1].text += string(t)
+			}
+		}
+	}
+}
+
+// rule describes an element of the schema: whether it is required, the values it is restricted to and the
+// elements it contains. An element without children is a leaf with text content.
+type rule struct {
+	required bool
+	repeated bool
+	values   []string
+	dateTime bool
+	integer  bool
+	children map[string]rule
+}
+
+var boolValues = []string{"да", "нет", "true", "false", "1", "0", "+", "-"}
+
+// offerSchema follows the offer element of the realty feed schema for the elements the feed uses.
+var offerSchema = map[string]rule{
+	"type":             {required: true, values: []string{TypeSale, TypeRent}},
+	"property-type":    {required: true, values: []string{PropertyTypeLiving}},
+	"category":         {required: true, values: []string{CategoryFlat, "flat", "комната", "дом"}},
+	"creation-date":    {required: true, dateTime: true},
+	"last-update-date": {dateTime: true},
+	"location": {required: true, children: map[string]rule{
+		"country":       {required: true},
+		"locality-name": {},
+		"address":       {},
+	}},
+	"sales-agent": {required: true, children: map[string]rule{
+		"name":     {},
+		"phone":    {required: true, repeated: true},
+		"email":    {},
+		"category": {required: true, values: []string{AgentCategoryAgency, "владелец", "agency", "owner"}},
+	}},
+	"price": {required: true, children: map[string]rule{
+		"value":    {required: true, integer: true},
+		"currency": {required: true, values: []string{CurrencyRUB, "RUR", "EUR", "USD"}},
+		"period":   {values: []string{PeriodMonth, "день", "day", "month"}},
+	}},
+	"image": {repeated: true},
+	"area": {required: true, children: map[string]rule{
+		"value": {required: true, integer: true},
+		"unit":  {required: true, values: []string{UnitSquareMeter, "sq. m"}},
+	}},
+	"rooms":              {integer: true},
+	"description":        {},
+	"rent-pledge":        {values: boolValues},
+	"utilities-included": {values: boolValues},
+	"with-pets":          {values: boolValues},
+}
+
+func validate(feed *element) []string {
+	var errs []string
+	if feed == nil || feed.name.Local != "realty-feed" || feed.name.Space != Namespace {
+		return []string{fmt.Sprintf("root is %+v, want realty-feed in %s", feed, Namespace)}
+	}
+
+	if len(feed.children) == 0 || feed.children[0].name.Local != "generation-date" {
+		errs = append(errs, "generation-date must come first")
+	}
+	for i, c := range feed.children {
+		switch {
+		case c.name.Local == "generation-date" && i == 0:
+			errs = append(errs, validateLeaf("generation-date", c, rule{dateTime: true})...)
+		case c.name.Local == "offer":
+			if len(c.attrs["internal-id"]) == 0 {
+				errs = append(errs, "offer without internal-id")
+			}
+			errs = append(errs, validateChildren("offer", c, offerSchema)...)
+		default:
+			errs = append(errs, fmt.Sprintf("unexpected element %s in realty-feed", c.name.Local))
+		}
+	}
+
+	return errs
+}
+
+func validateChildren(path string, e *element, schema map[string]rule) []string {
+	var errs []string
+
+	counts := make(map[string]int)
+	for _, c := range e.children {
+		counts[c.name.Local]++
+		if c.name.Space != Namespace {
+			errs = append(errs, fmt.Sprintf("%s/%s is in namespace %q", path, c.name.Local, c.name.Space))
+		}
+
+		r, ok := schema[c.name.Local]
+		if !ok {
+			errs = append(errs, fmt.Sprintf("unexpected element %s/%s", path, c.name.Local))
+			continue
+		}
+		if r.children != nil {
+			errs = append(errs, validateChildren(path+"/"+c.name.Local, c, r.children)...)
+		} else {
+			errs = append(errs, validateLeaf(path+"/"+c.name.Local, c, r)...)
+		}
+	}
+
+	for name, r := range schema {
+		switch {
+		case r.required && counts[name] == 0:
+			errs = append(errs, fmt.Sprintf("%s/%s is missing", path, name))
+		case !r.repeated && counts[name] > 1:
+			errs = append(errs, fmt.Sprintf("%s/%s occurs %d times", path, name, counts[name]))
+		}
+	}
+
+	return errs
+}
+
+func validateLeaf(path string, e *element, r rule) []string {
+	if len(e.children) != 0 {
+		return []string{fmt.Sprintf("%s must not contain elements", path)}
+	}
+
+	text := strings.TrimSpace(e.text)
+	switch {
+	case len(text) == 0:
+		return []string{fmt.Sprintf("%s is empty", path)}
+	case r.dateTime:
+		if _, err := time.Parse(time.RFC3339, text); err != nil {
+			return []string{fmt.Sprintf("%s: %q is not a date-time", path, text)}
+		}
+	case r.integer:
+		var n int
+		if _, err := fmt.Sscan(text, &n); err != nil || fmt.Sprint(n) != text || n < 0 {
+			return []string{fmt.Sprintf("%s: %q is not a non-negative integer", path, text)}
+		}
+	case r.values != nil:
+		for _, v := range r.values {
+			if v == text {
+				return nil
+			}
+		}
+		return []string{fmt.Sprintf("%s: %q is not one of %q", path, text, r.values)}
+	}
+
+	return nil
+}
+
+func TestMarshalMatchesSchema(t *testing.T) {
+	generated := time.Date(2024, 3, 1, 12, 30, 0, 0, time.FixedZone("MSK", 3*60*60))
+	sale := Offer{
+		InternalID:     "1",
+		Type:           TypeSale,
+		PropertyType:   PropertyTypeLiving,
+		Category:       CategoryFlat,
+		CreationDate:   FormatTime(generated.Add(-48 * time.Hour)),
+		LastUpdateDate: FormatTime(generated.Add(-time.Hour)),
+		Location:       Location{Country: "Россия", LocalityName: "Москва", Address: "ул. Ленина, д. 5"},
+		SalesAgent:     SalesAgent{Name: "Иван Петров", Phone: "+79990000000", Email: "ivan@example.com", Category: AgentCategoryAgency},
+		Price:          Price{Value: 12000000, Currency: CurrencyRUB},
+		Images:         []string{"https://example.com/photo/apartment/1.png"},
+		Area:           Area{Value: 54, Unit: UnitSquareMeter},
+		Rooms:          2,
+		Description:    "Двухкомнатная квартира & балкон <у парка>",
+	}
+	rent := Offer{
+		InternalID:        "2",
+		Type:              TypeRent,
+		PropertyType:      PropertyTypeLiving,
+		Category:          CategoryFlat,
+		CreationDate:      FormatTime(generated),
+		Location:          Location{Country: "Россия"},
+		SalesAgent:        SalesAgent{Phone: "+79990000001", Category: AgentCategoryAgency},
+		Price:             Price{Value: 45000, Currency: CurrencyRUB, Period: PeriodMonth},
+		Area:              Area{Value: 30, Unit: UnitSquareMeter},
+		RentPledge:        NewBool(true),
+		UtilitiesIncluded: NewBool(false),
+		WithPets:          NewBool(false),
+	}
+
+	tests := []struct {
+		name   string
+		offers []Offer
+		// wantErrs lists substrings of the schema violations expected, none for a valid feed.
+		wantErrs []string
+	}{
+		{name: "empty feed", offers: nil},
+		{name: "sale offer", offers: []Offer{sale}},
+		{name: "rent offer", offers: []Offer{rent}},
+		{name: "sale and rent offers", offers: []Offer{sale, rent}},
+		{
+			name:     "offer without creation date",
+			offers:   []Offer{func() Offer { o := rent; o.CreationDate = ""; return o }()},
+			wantErrs: []string{"offer/creation-date is empty"},
+		},
+		{
+			name:     "offer without agent phone",
+			offers:   []Offer{func() Offer { o := rent; o.SalesAgent.Phone = ""; return o }()},
+			wantErrs: []string{"offer/sales-agent/phone is empty"},
+		},
+		{
+			name:     "offer with an unknown type",
+			offers:   []Offer{func() Offer { o := sale; o.Type = "обмен"; return o }()},
+			wantErrs: []string{`offer/type: "обмен" is not one of`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := Marshal(NewFeed(generated, tt.offers))
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if !bytes.HasPrefix(data, []byte(xml.Header)) {
+				t.Errorf("Marshal() does not start with the XML declaration")
+			}
+
+			root, err := parse(data)
+			if err != nil {
+				t.Fatalf("Marshal() produced malformed XML: %v\n%s", err, data)
+			}
+			errs := validate(root)
+
+			if len(tt.wantErrs) == 0 && len(errs) != 0 {
+				t.Fatalf("feed violates the schema:\n%s\n%s", strings.Join(errs, "\n"), data)
+			}
+			for _, want := range tt.wantErrs {
+				found := false
+				for _, err := range errs {
+					found = found || strings.Contains(err, want)
+				}
+				if !found {
+					t.Errorf("violation %q not reported, got %q", want, errs)
+				}
+			}
+			if got := len(root.children) - 1; got != len(tt.offers) {
+				t.Errorf("feed has %d offers, want %d", got, len(tt.offers))
+			}
+		})
+	}
+}
+
+func TestMarshalOffer(t *testing.T) {
+	data, err := Marshal(NewFeed(time.Now(), []Offer{{
+		InternalID:  "7",
+		Description: "a < b & c",
+		Price:       Price{Value: 45000, Currency: CurrencyRUB, Period: PeriodMonth},
+		RentPledge:  NewBool(true),
+		WithPets:    NewBool(false),
+	}}))
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	root, err := parse(data)
+	if err != nil {
+		t.Fatalf("Marshal() produced malformed XML: %v", err)
+	}
+	offer := root.child("offer")
+
+	tests := []struct {
+		path []string
+		want string
+	}{
+		{path: []string{"description"}, want: "a < b & c"},
+		{path: []string{"price", "period"}, want: PeriodMonth},
+		{path: []string{"rent-pledge"}, want: "да"},
+		{path: []string{"with-pets"}, want: "нет"},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.path, "/"), func(t *testing.T) {
+			e := offer
+			for _, name := range tt.path {
+				if e = e.child(name); e == nil {
+					t.Fatalf("%s is missing", name)
+				}
+			}
+			if e.text != tt.want {
+				t.Errorf("%s = %q, want %q", strings.Join(tt.path, "/"), e.text, tt.want)
+			}
+		})
+	}
+
+	if offer.attrs["internal-id"] != "7" {
+		t.Errorf("internal-id = %q, want 7", offer.attrs["internal-id"])
+	}
+	for _, name := range []string{"utilities-included", "image", "last-update-date"} {
+		if offer.child(name) != nil {
+			t.Errorf("empty %s is written", name)
+		}
+	}
+}